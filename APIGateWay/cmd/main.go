@@ -3,8 +3,11 @@ package main
 import (
 	"Project/APIGateWay/internal/config"
 	"Project/APIGateWay/internal/logger"
+	"Project/APIGateWay/internal/oauth"
 	"Project/APIGateWay/internal/server"
 	"Project/APIGateWay/internal/service"
+	"Project/pkg/revocation"
+	"context"
 	"fmt"
 
 	"go.uber.org/zap"
@@ -17,7 +20,7 @@ func main() {
 
 	log, err := logger.New()
 	if err != nil {
-		fmt.Errorf("Failed load logger%w", err)
+		fmt.Printf("Failed load logger: %v\n", err)
 		return
 	}
 
@@ -26,7 +29,14 @@ func main() {
 		log.Fatal("Failed load config")
 	}
 
-	authConn, err := grpc.NewClient(cfg.AuthServiceAdress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if cfg.AuthServiceSecret == "" {
+		log.Fatal("Invalid config: auth_service_secret is required")
+	}
+
+	authConn, err := grpc.NewClient(cfg.AuthServiceAdress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(service.CallerSecretInterceptor(cfg.AuthServiceSecret)),
+	)
 	if err != nil {
 		log.Fatal("Failed to connect to AuthService: ", zap.Error(err))
 	}
@@ -41,7 +51,17 @@ func main() {
 	authService := service.NewAuthService(authConn)
 	feedService := service.NewFeedService(feedConn)
 
-	server := server.NewServer(authService, feedService)
+	oauthProviders, err := oauth.NewProviders(cfg.OAuth.Providers)
+	if err != nil {
+		log.Fatal("Failed to configure identity providers: ", zap.Error(err))
+	}
+
+	revokedTokens := revocation.NewCache(revocation.NewList(cfg.Redis.Addr), cfg.Redis.RevocationResyncInterval, log.Logger)
+	if err := revokedTokens.Start(context.Background()); err != nil {
+		log.Fatal("Failed to load revoked tokens: ", zap.Error(err))
+	}
+
+	server := server.NewServer(log, authService, feedService, revokedTokens, oauthProviders, cfg.OAuth.CookieSecure)
 
 	log.Info("Starting API Gateway on :8080")
 	if err := server.Start(cfg.HttpServerAdress); err != nil {
@@ -0,0 +1,223 @@
+// Command stub-oidc is a minimal OpenID Connect provider for local development
+// and tests of the /oauth routes. It signs in whoever types an email, so it must
+// never be exposed.
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	email         string
+	emailVerified bool
+}
+
+type provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+
+	mu     sync.Mutex
+	codes  map[string]authorization
+	tokens map[string]authorization
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!doctype html>
+<title>Stub OIDC</title>
+<form method="get" action="/authorize">
+  {{range $key, $values := .}}{{range $values}}<input type="hidden" name="{{$key}}" value="{{.}}">{{end}}{{end}}
+  <label>Email <input name="login_hint" type="email" required></label>
+  <label><input name="email_verified" type="checkbox" value="true" checked> Email verified</label>
+  <button type="submit">Sign in</button>
+</form>`))
+
+func main() {
+	addr := flag.String("addr", ":9999", "Address to listen on")
+	issuer := flag.String("issuer", "http://localhost:9999", "Issuer URL as seen by the browser and the gateway")
+	clientID := flag.String("client-id", "local-client", "Accepted client ID")
+	clientSecret := flag.String("client-secret", "local-secret", "Accepted client secret")
+	flag.Parse()
+
+	p := &provider{
+		issuer:       strings.TrimSuffix(*issuer, "/"),
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		codes:        make(map[string]authorization),
+		tokens:       make(map[string]authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/userinfo", p.userInfo)
+
+	log.Printf("Stub OIDC provider %s listening on %s", p.issuer, *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (p *provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                           p.issuer,
+		"authorization_endpoint":           p.issuer + "/authorize",
+		"token_endpoint":                   p.issuer + "/token",
+		"userinfo_endpoint":                p.issuer + "/userinfo",
+		"response_types_supported":         []string{"code"},
+		"code_challenge_methods_supported": []string{"S256"},
+		"scopes_supported":                 []string{"openid", "email"},
+	})
+}
+
+// authorize shows a form asking for the email to sign in as, then redirects
+// back with a code. Pass login_hint to skip the form.
+func (p *provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("client_id") != p.clientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	email := query.Get("login_hint")
+	if email == "" {
+		query.Del("email_verified")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		loginPage.Execute(w, query)
+		return
+	}
+
+	code := randomToken()
+
+	p.mu.Lock()
+	p.codes[code] = authorization{
+		clientID:      p.clientID,
+		redirectURI:   redirectURI.String(),
+		codeChallenge: query.Get("code_challenge"),
+		email:         email,
+		emailVerified: query.Get("email_verified") != "false",
+	}
+	p.mu.Unlock()
+
+	callback := redirectURI.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callback.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (p *provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request", "malformed form")
+		return
+	}
+
+	if r.PostForm.Get("client_id") != p.clientID || r.PostForm.Get("client_secret") != p.clientSecret {
+		tokenError(w, "invalid_client", "unknown client or wrong secret")
+		return
+	}
+
+	p.mu.Lock()
+	auth, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	if !ok || auth.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, "invalid_grant", "unknown code or redirect_uri mismatch")
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+		tokenError(w, "invalid_grant", "code_verifier does not match the code_challenge")
+		return
+	}
+
+	accessToken := randomToken()
+
+	p.mu.Lock()
+	p.tokens[accessToken] = auth
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (p *provider) userInfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	p.mu.Lock()
+	auth, ok := p.tokens[accessToken]
+	p.mu.Unlock()
+
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// The subject is derived from the email so signing in again with the same
+	// email returns the same account.
+	sum := sha256.Sum256([]byte(strings.ToLower(auth.email)))
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":            hex.EncodeToString(sum[:8]),
+		"email":          auth.email,
+		"email_verified": auth.emailVerified,
+	})
+}
+
+func tokenError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomToken() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("failed to generate token: %v", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"flag"
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -10,8 +11,38 @@ import (
 type Config struct {
 	Env               string `mapstructure:"env"`
 	AuthServiceAdress string `mapstructure:"auth_service_address"`
+	// AuthServiceSecret is sent on every AuthService call to prove the call
+	// comes from the gateway.
+	AuthServiceSecret string `mapstructure:"auth_service_secret"`
 	HttpServerAdress  string `mapstructure:"http_server_adress"`
 	FeedServiceAdress string `mapstructure:"feed_service_address"`
+
+	OAuth OAuthConfig `mapstructure:"oauth"`
+	Redis RedisConfig `mapstructure:"redis"`
+}
+
+type RedisConfig struct {
+	Addr string `mapstructure:"addr"`
+	// RevocationResyncInterval is how often the revoked access tokens are
+	// reloaded in case a broadcast was missed.
+	RevocationResyncInterval time.Duration `mapstructure:"revocation_resync_interval"`
+}
+
+type OAuthConfig struct {
+	// CookieSecure marks the cookie holding the state and PKCE verifier between
+	// /oauth/{provider}/start and the callback as HTTPS only.
+	CookieSecure bool                           `mapstructure:"cookie_secure"`
+	Providers    map[string]OAuthProviderConfig `mapstructure:"providers"`
+}
+
+type OAuthProviderConfig struct {
+	// Kind is "oidc" (the default) or "github".
+	Kind         string   `mapstructure:"kind"`
+	Issuer       string   `mapstructure:"issuer"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
 }
 
 func InitFlags() string {
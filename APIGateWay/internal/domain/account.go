@@ -0,0 +1,26 @@
+package domain
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// LinkedIdentity is an identity provider account linked to the user.
+type LinkedIdentity struct {
+	Provider string `json:"provider"`
+	Email    string `json:"email,omitempty"`
+	LinkedAt int64  `json:"linked_at"`
+}
+
+// DataExport is the archive GET /me/export returns: everything the services
+// store about the user.
+type DataExport struct {
+	ExportedAt     int64             `json:"exported_at"`
+	User           *User             `json:"user"`
+	Profile        *Profile          `json:"profile"`
+	EmailConfirmed bool              `json:"email_confirmed"`
+	CreatedAt      int64             `json:"created_at"`
+	Roles          []*UserRole       `json:"roles"`
+	Sessions       []*Session        `json:"sessions"`
+	Identities     []*LinkedIdentity `json:"identities"`
+	Posts          []*PostResponse   `json:"posts"`
+}
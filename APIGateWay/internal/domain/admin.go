@@ -0,0 +1,42 @@
+package domain
+
+// AdminUser is a user as admins see it in the user list. Timestamps are Unix
+// seconds; locked_until and suspended_at are left out when not set.
+type AdminUser struct {
+	ID              string `json:"id"`
+	Email           string `json:"email"`
+	EmailConfirmed  bool   `json:"email_confirmed"`
+	CreatedAt       int64  `json:"created_at"`
+	LockedUntil     int64  `json:"locked_until,omitempty"`
+	SuspendedAt     int64  `json:"suspended_at,omitempty"`
+	SuspendedReason string `json:"suspended_reason,omitempty"`
+}
+
+// UserFilter holds the query parameters of GET /admin/users. Nil fields are
+// not filtered on.
+type UserFilter struct {
+	Query         string
+	Confirmed     *bool
+	Locked        *bool
+	Suspended     *bool
+	CreatedAfter  int64
+	CreatedBefore int64
+}
+
+type ListUsersResponse struct {
+	Users      []*AdminUser `json:"users"`
+	TotalUsers int          `json:"total_users"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ImpersonateUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ImpersonateUserResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresAt   int64  `json:"expires_at"`
+}
@@ -7,25 +7,64 @@ type RegisterRequest struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	DeviceName string `json:"device_name"`
 }
 
+// LoginResponse carries either the token pair or, when the user has two-factor
+// authentication enabled, an MFA token to pass to /login/mfa.
 type LoginResponse struct {
-	Tokens TokenPair `json:"tokens"`
-	User   User      `json:"user"`
+	Tokens      *TokenPair `json:"tokens,omitempty"`
+	User        User       `json:"user"`
+	MFARequired bool       `json:"mfa_required"`
+	MFAToken    string     `json:"mfa_token,omitempty"`
 }
 
-type LogoutRequest struct {
-	UserID string `json:"user_id"`
+// ExternalIdentity is the account a user signed in with at an identity provider.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+type LoginMFARequest struct {
+	MFAToken   string `json:"mfa_token"`
+	Code       string `json:"code"`
+	DeviceName string `json:"device_name"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+type ConsumeMagicLinkRequest struct {
+	Token      string `json:"token"`
+	DeviceName string `json:"device_name"`
+}
+
+type Enable2FAResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type Verify2FARequest struct {
+	Code string `json:"code"`
+}
+
+type Verify2FAResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
 type User struct {
-	ID    string `json:"id"`
-	Email string `json:"email"`
+	ID          string   `json:"id"`
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
 }
 
 type TokenPair struct {
@@ -37,3 +76,73 @@ type ConfirmationRequest struct {
 	Email            string `json:"email"`
 	ConfirmationCode string `json:"confirmation_code"`
 }
+
+type ResendConfirmationRequest struct {
+	Email string `json:"email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	ConfirmationCode string `json:"confirmation_code"`
+}
+
+type Session struct {
+	ID         string `json:"id"`
+	DeviceName string `json:"device_name"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  int64  `json:"created_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+	Current    bool   `json:"current"`
+}
+
+type SessionsResponse struct {
+	Sessions []*Session `json:"sessions"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSResponse struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type UserRole struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	GrantedBy   string   `json:"granted_by,omitempty"`
+	GrantedAt   int64    `json:"granted_at"`
+}
+
+type UserRolesResponse struct {
+	Roles []*UserRole `json:"roles"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+// AuthEvent is an entry of the authentication audit log. Timestamps are Unix
+// seconds.
+type AuthEvent struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Event     string `json:"event"`
+	Outcome   string `json:"outcome"`
+	Reason    string `json:"reason,omitempty"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	SessionID string `json:"session_id,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// AuthEventFilter holds the query parameters of GET /admin/auth-events.
+// From and To are Unix seconds, 0 when not set.
+type AuthEventFilter struct {
+	UserID  string
+	Email   string
+	Event   string
+	Outcome string
+	From    int64
+	To      int64
+}
+
+type AuthEventsResponse struct {
+	Events      []*AuthEvent `json:"events"`
+	TotalEvents int          `json:"total_events"`
+}
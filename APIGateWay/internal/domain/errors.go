@@ -1,5 +1,19 @@
 package domain
 
+// ErrorResponse is the body of every failed request.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	// Code is the upper snake case gRPC code name, e.g. "INVALID_ARGUMENT".
+	Code      string           `json:"code"`
+	Message   string           `json:"message"`
+	RequestID string           `json:"request_id"`
+	Fields    []FieldViolation `json:"fields,omitempty"`
+}
+
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
 }
@@ -7,12 +7,56 @@ type CreatePostRequest struct {
 }
 
 type PostResponse struct {
-	Content   string `json:"content"`
-	ImageURL  string `json:"image_url"`
-	CreatedAt string `json:"created_at"`
+	ID             string         `json:"id"`
+	UserID         string         `json:"user_id"`
+	Author         *Author        `json:"author,omitempty"`
+	Content        string         `json:"content"`
+	ImageURL       string         `json:"image_url"`
+	CreatedAt      string         `json:"created_at"`
+	UpdatedAt      string         `json:"updated_at,omitempty"`
+	DeletedAt      string         `json:"deleted_at,omitempty"`
+	ReactionCounts map[string]int `json:"reaction_counts"`
+	MyReactions    []string       `json:"my_reactions,omitempty"`
+	LikedByMe      bool           `json:"liked_by_me"`
 }
 
+// Follow is an entry of a followers or following list. Profile is missing for
+// users without a profile.
+type Follow struct {
+	UserID     string  `json:"user_id"`
+	Profile    *Author `json:"profile,omitempty"`
+	FollowedAt string  `json:"followed_at"`
+}
+
+type FollowsResponse struct {
+	Follows []*Follow `json:"follows"`
+	Total   int       `json:"total"`
+}
+
+type TimelineResponse struct {
+	Posts      []*PostResponse `json:"posts"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// UpdatePostRequest changes only the fields that are present.
+type UpdatePostRequest struct {
+	Content  *string `json:"content"`
+	ImageURL *string `json:"image_url"`
+}
+
+// PostsQuery selects a page of the feed. Page switches to the deprecated offset
+// pagination when no cursor is given.
+type PostsQuery struct {
+	Cursor       string
+	PageSize     int
+	IncludeTotal bool
+	Page         int
+}
+
+// AllPostResponse is a page of the feed. TotalPosts is exact with page; with
+// cursor it is an estimate, and 0 unless it was asked for.
 type AllPostResponse struct {
 	Posts      []*PostResponse `json:"posts"`
 	TotalPosts int             `json:"total_posts"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,94 @@
+package domain
+
+// OAuthClient is a third-party application that can request access to the
+// accounts of users through /oauth2/authorize.
+type OAuthClient struct {
+	ClientID     string   `json:"client_id"`
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+	GrantTypes   []string `json:"grant_types"`
+	Confidential bool     `json:"confidential"`
+}
+
+type RegisterOAuthClientRequest struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+	GrantTypes   []string `json:"grant_types"`
+	Confidential bool     `json:"confidential"`
+}
+
+type RegisterOAuthClientResponse struct {
+	Client       OAuthClient `json:"client"`
+	ClientSecret string      `json:"client_secret,omitempty"`
+}
+
+type AuthorizeOAuthClientRequest struct {
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Approve             bool   `json:"approve"`
+}
+
+// AuthorizeOAuthClientResponse either asks the user to approve Scopes or
+// carries the redirect back to the client with the authorization code.
+type AuthorizeOAuthClientResponse struct {
+	ConsentRequired bool        `json:"consent_required"`
+	Client          OAuthClient `json:"client"`
+	Scopes          []string    `json:"scopes"`
+	RedirectTo      string      `json:"redirect_to,omitempty"`
+}
+
+// OAuthClientCredentials come from HTTP Basic authentication or the
+// client_id and client_secret form parameters.
+type OAuthClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+}
+
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type OAuthTokenHandleRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+}
+
+// OAuthIntrospectionResponse is defined by RFC 7662, only Active is set for
+// inactive tokens.
+type OAuthIntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
+
+// OAuthErrorResponse is the error body of the token, introspection and
+// revocation endpoints (RFC 6749 section 5.2).
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"Project/APIGateWay/internal/domain"
+	"Project/APIGateWay/internal/service"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc/metadata"
+)
+
+type AccountHandlers struct {
+	authService *service.AuthService
+	feedService *service.FeedService
+}
+
+func NewAccountHandlers(authService *service.AuthService, feedService *service.FeedService) *AccountHandlers {
+	return &AccountHandlers{
+		authService: authService,
+		feedService: feedService,
+	}
+}
+
+// DeleteAccount deletes the account of the caller. FeedService removes the
+// posts asynchronously once it receives the user deleted event.
+func (h *AccountHandlers) DeleteAccount(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	success, err := h.authService.DeleteAccount(clientContext(c), accessToken, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+// ExportMyData assembles the data of every service about the caller into a
+// JSON file to download.
+func (h *AccountHandlers) ExportMyData(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	export, err := h.authService.ExportMyData(clientContext(c), accessToken)
+	if err != nil {
+		return err
+	}
+
+	ctx := metadata.AppendToOutgoingContext(clientContext(c), "authorization", "Bearer "+accessToken)
+
+	export.Posts, err = h.feedService.ExportMyPosts(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	export.ExportedAt = now.Unix()
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Attachment(fmt.Sprintf("data-export-%s.json", now.Format("20060102-150405")))
+	return c.JSON(export)
+}
+
+// ListMyAuthEvents returns the authentication audit log of the caller.
+func (h *AccountHandlers) ListMyAuthEvents(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	from, to := int64(c.QueryInt("from")), int64(c.QueryInt("to"))
+
+	events, err := h.authService.ListMyAuthEvents(clientContext(c), accessToken, from, to, c.QueryInt("page", 1), c.QueryInt("pageSize", 20))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(events)
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"Project/APIGateWay/internal/domain"
+	"Project/APIGateWay/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandlers are mounted behind RequirePermissions; AuthService checks the
+// permissions again for the RPCs it serves.
+type AdminHandlers struct {
+	authService *service.AuthService
+}
+
+func NewAdminHandlers(authService *service.AuthService) *AdminHandlers {
+	return &AdminHandlers{
+		authService: authService,
+	}
+}
+
+func (h *AdminHandlers) ListUserRoles(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	roles, err := h.authService.ListUserRoles(clientContext(c), accessToken, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(domain.UserRolesResponse{Roles: roles})
+}
+
+func (h *AdminHandlers) AssignRole(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	success, err := h.authService.AssignRole(clientContext(c), accessToken, c.Params("id"), c.Params("role"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AdminHandlers) RevokeRole(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	success, err := h.authService.RevokeRole(clientContext(c), accessToken, c.Params("id"), c.Params("role"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AdminHandlers) RevokeAccessToken(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	success, err := h.authService.RevokeAccessToken(clientContext(c), accessToken, c.Params("jti"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AdminHandlers) ListUsers(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	filter := domain.UserFilter{
+		Query:         c.Query("query"),
+		CreatedAfter:  int64(c.QueryInt("createdAfter")),
+		CreatedBefore: int64(c.QueryInt("createdBefore")),
+	}
+	if filter.Confirmed, err = queryBool(c, "confirmed"); err != nil {
+		return err
+	}
+	if filter.Locked, err = queryBool(c, "locked"); err != nil {
+		return err
+	}
+	if filter.Suspended, err = queryBool(c, "suspended"); err != nil {
+		return err
+	}
+
+	users, err := h.authService.ListUsers(clientContext(c), accessToken, filter, c.QueryInt("page", 1), c.QueryInt("pageSize", 20))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(users)
+}
+
+// queryBool reads an optional true/false query parameter; nil means it is missing.
+func queryBool(c *fiber.Ctx, name string) (*bool, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fiber.NewError(http.StatusBadRequest, "Query parameter "+name+" must be true or false")
+	}
+
+	return &value, nil
+}
+
+func (h *AdminHandlers) SuspendUser(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.SuspendUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	success, err := h.authService.SuspendUser(clientContext(c), accessToken, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AdminHandlers) UnsuspendUser(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	success, err := h.authService.UnsuspendUser(clientContext(c), accessToken, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AdminHandlers) ForceLogout(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := h.authService.ForceLogout(clientContext(c), accessToken, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(domain.RevokeSessionsResponse{Revoked: revoked})
+}
+
+func (h *AdminHandlers) ImpersonateUser(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.ImpersonateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	res, err := h.authService.ImpersonateUser(clientContext(c), accessToken, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(res)
+}
+
+func (h *AdminHandlers) ListAuthEvents(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	filter := domain.AuthEventFilter{
+		UserID:  c.Query("userId"),
+		Email:   c.Query("email"),
+		Event:   c.Query("event"),
+		Outcome: c.Query("outcome"),
+		From:    int64(c.QueryInt("from")),
+		To:      int64(c.QueryInt("to")),
+	}
+
+	events, err := h.authService.ListAuthEvents(clientContext(c), accessToken, filter, c.QueryInt("page", 1), c.QueryInt("pageSize", 20))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(events)
+}
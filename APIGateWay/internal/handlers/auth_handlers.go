@@ -3,12 +3,16 @@ package handlers
 import (
 	"Project/APIGateWay/internal/domain"
 	"Project/APIGateWay/internal/service"
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc/metadata"
 )
 
+var errInvalidBody = fiber.NewError(http.StatusBadRequest, "Invalid request body")
+
 type AuthHandlers struct {
 	authService *service.AuthService
 }
@@ -23,12 +27,12 @@ func (h *AuthHandlers) Register(c *fiber.Ctx) error {
     var req domain.RegisterRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(domain.ErrorResponse{Error: "Invalid request body"})
+		return errInvalidBody
 	}
 
-	success, err := h.authService.Register(c.Context(), &req)
+	success, err := h.authService.Register(clientContext(c), &req)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(domain.ErrorResponse{Error: "Unsuccess register"})
+		return err
 	}
 
 	return c.JSON(success)
@@ -38,58 +42,145 @@ func (h *AuthHandlers) Login(c *fiber.Ctx) error {
     var req domain.LoginRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(domain.ErrorResponse{Error: "Invalid request body"})
+		return errInvalidBody
 	}
 
-	tokens, user, err := h.authService.Login(c.Context(), &req)
+	response, err := h.authService.Login(clientContext(c), &req)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(domain.ErrorResponse{Error: "Invalid Login or Password"})
+		return err
 	}
 
-	return c.JSON(domain.LoginResponse{Tokens: *tokens,User: *user})
+	return c.JSON(response)
 }
 
-func (h *AuthHandlers) Logout(c *fiber.Ctx) error {
-	var req domain.LogoutRequest
+func (h *AuthHandlers) LoginMFA(c *fiber.Ctx) error {
+	var req domain.LoginMFARequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	response, err := h.authService.LoginMFA(clientContext(c), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
 
+func (h *AuthHandlers) RequestMagicLink(c *fiber.Ctx) error {
+	var req domain.MagicLinkRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(domain.ErrorResponse{Error: "Invalid request body"})
+		return errInvalidBody
 	}
 
-	success, err := h.authService.Logout(c.Context(), req.UserID)
+	success, err := h.authService.RequestMagicLink(clientContext(c), &req)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(domain.ErrorResponse{Error: "Failed logout"})
+		return err
 	}
 
 	return c.JSON(success)
 }
 
+func (h *AuthHandlers) ConsumeMagicLink(c *fiber.Ctx) error {
+	var req domain.ConsumeMagicLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	response, err := h.authService.ConsumeMagicLink(clientContext(c), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+func (h *AuthHandlers) Enable2FA(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	response, err := h.authService.Enable2FA(clientContext(c), accessToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+func (h *AuthHandlers) Verify2FA(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.Verify2FARequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	response, err := h.authService.Verify2FA(clientContext(c), accessToken, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+func (h *AuthHandlers) Logout(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	success, err := h.authService.Logout(clientContext(c), accessToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AuthHandlers) LogoutAll(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := h.authService.LogoutAll(clientContext(c), accessToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(domain.RevokeSessionsResponse{Revoked: revoked})
+}
+
 func (h *AuthHandlers) Refresh(c *fiber.Ctx) error {
     var req domain.RefreshRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(domain.ErrorResponse{Error: "Invalid request body"})
+		return errInvalidBody
 	}
 
-	tokens, err := h.authService.Refresh(c.Context(), req.RefreshToken)
+	tokens, err := h.authService.Refresh(clientContext(c), req.RefreshToken)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(domain.ErrorResponse{Error: "Failed Refresh tokens"})
+		return err
 	}
 
 	return c.JSON(tokens)
 }
 
 func (h *AuthHandlers) Me(c *fiber.Ctx) error {
-	authHeader := c.Get("Authorization")
-	if authHeader == "" {
-		return c.Status(http.StatusUnauthorized).JSON(domain.ErrorResponse{Error: "Failed get header"})
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
 	}
 
-	accessToken := strings.TrimPrefix(authHeader, "Bearer ")
-
-	user, err := h.authService.Me(c.Context(), accessToken)
+	user, err := h.authService.Me(clientContext(c), accessToken)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(domain.ErrorResponse{Error: "Failed Get Info of ME"})
+		return err
 	}
 
 	return c.JSON(user)
@@ -98,32 +189,195 @@ func (h *AuthHandlers) Me(c *fiber.Ctx) error {
 func (h *AuthHandlers) ConfirmEmail(c *fiber.Ctx) error {
 	var req domain.ConfirmationRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(domain.ErrorResponse{Error: "Invalid request body"})
+		return errInvalidBody
+	}
+
+	success, err := h.authService.ConfirmEmail(clientContext(c), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AuthHandlers) ResendConfirmationCode(c *fiber.Ctx) error {
+	var req domain.ResendConfirmationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	success, err := h.authService.ResendConfirmationCode(clientContext(c), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AuthHandlers) ForgotPassword(c *fiber.Ctx) error {
+	var req domain.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	success, err := h.authService.RequestPasswordReset(clientContext(c), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AuthHandlers) ResetPassword(c *fiber.Ctx) error {
+	var req domain.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	success, err := h.authService.ResetPassword(clientContext(c), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AuthHandlers) ChangePassword(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	success, err := h.authService.ChangePassword(clientContext(c), accessToken, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AuthHandlers) ChangeEmail(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.ChangeEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
 	}
 
-	success, err := h.authService.ConfirmEmail(c.Context(), &req)
+	success, err := h.authService.ChangeEmail(clientContext(c), accessToken, &req)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(domain.ErrorResponse{Error: "Failed Send Confim Token"})
+		return err
 	}
 
 	return c.JSON(success)
 }
 
-func CheckToken(c *fiber.Ctx, authService *service.AuthService) (*domain.User, error) {
+func (h *AuthHandlers) ConfirmEmailChange(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.ConfirmEmailChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	success, err := h.authService.ConfirmEmailChange(clientContext(c), accessToken, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AuthHandlers) ListSessions(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := h.authService.ListSessions(clientContext(c), accessToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(domain.SessionsResponse{Sessions: sessions})
+}
+
+func (h *AuthHandlers) RevokeSession(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	success, err := h.authService.RevokeSession(clientContext(c), accessToken, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *AuthHandlers) RevokeAllOtherSessions(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := h.authService.RevokeAllOtherSessions(clientContext(c), accessToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(domain.RevokeSessionsResponse{Revoked: revoked})
+}
+
+func (h *AuthHandlers) JWKS(c *fiber.Ctx) error {
+	jwks, err := h.authService.GetJWKS(c.Context())
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(jwks)
+}
+
+func bearerToken(c *fiber.Ctx) (string, error) {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
-		return nil, fiber.NewError(http.StatusUnauthorized, "Authorization header is missing")
+		return "", fiber.NewError(http.StatusUnauthorized, "Authorization header is missing")
 	}
 
 	token := strings.TrimPrefix(authHeader, "Bearer ")
 	if token == authHeader {
-		return nil, fiber.NewError(http.StatusUnauthorized, "Invalid authorization header format")
+		return "", fiber.NewError(http.StatusUnauthorized, "Invalid authorization header format")
 	}
 
-	user, err := authService.Me(c.Context(), token)
-	if err != nil {
-		return nil, fiber.NewError(http.StatusUnauthorized, "Invalid token")
-	}
+	return token, nil
+}
+
+// clientContext forwards the original client address, user agent and request ID
+// to the backend services so they can describe the device behind a session and
+// correlate their logs with the gateway.
+func clientContext(c *fiber.Ctx) context.Context {
+	md := metadata.Pairs(
+		"x-forwarded-for", c.IP(),
+		"x-user-agent", c.Get("User-Agent"),
+		"x-request-id", requestID(c),
+	)
+	return metadata.NewOutgoingContext(c.Context(), md)
+}
 
-	return user, nil
-}
\ No newline at end of file
+func requestID(c *fiber.Ctx) string {
+	id, _ := c.Locals("requestid").(string)
+	return id
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"Project/APIGateWay/internal/domain"
+	"Project/APIGateWay/internal/service"
+	"Project/pkg/authz"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const userLocalsKey = "user"
+
+var errPermissionDenied = fiber.NewError(http.StatusForbidden, "Permission denied")
+
+// RequirePermissions only lets requests through whose bearer token belongs to
+// a user with every permission. The roles come from AuthService, so a revoked
+// role takes effect at once. The user is stored for the handlers, see
+// CurrentUser.
+func RequirePermissions(authService *service.AuthService, permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		accessToken, err := bearerToken(c)
+		if err != nil {
+			return err
+		}
+
+		user, err := authService.Me(clientContext(c), accessToken)
+		if err != nil {
+			return err
+		}
+
+		userID, err := uuid.Parse(user.ID)
+		if err != nil {
+			return err
+		}
+
+		principal := authz.Principal{
+			UserID:      userID,
+			Roles:       user.Roles,
+			Permissions: user.Permissions,
+		}
+		if err := authz.Require(principal, permissions...); err != nil {
+			return errPermissionDenied
+		}
+
+		c.Locals(userLocalsKey, user)
+		return c.Next()
+	}
+}
+
+// CurrentUser returns the user RequirePermissions authenticated.
+func CurrentUser(c *fiber.Ctx) (*domain.User, bool) {
+	user, ok := c.Locals(userLocalsKey).(*domain.User)
+	return user, ok
+}
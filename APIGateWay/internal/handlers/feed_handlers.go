@@ -1,10 +1,10 @@
 package handlers
 
 import (
+	"context"
+
 	"Project/APIGateWay/internal/domain"
 	"Project/APIGateWay/internal/service"
-	"net/http"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"google.golang.org/grpc/metadata"
@@ -12,58 +12,175 @@ import (
 
 type FeedHandlers struct {
 	feedService *service.FeedService
-	auth        service.AuthService //ЭТО ПОЛНАЯ ХУЙНЯ БЕЗ ЭТОГО НЕ МОГУ ИСПОЛЬЗОВАТЬ Me функцию из authservice которая принимает токен что бы валидировать юзера
+	authService *service.AuthService
 }
 
-func NewFeedHandlers(feedService *service.FeedService) *FeedHandlers {
+func NewFeedHandlers(feedService *service.FeedService, authService *service.AuthService) *FeedHandlers {
 	return &FeedHandlers{
 		feedService: feedService,
+		authService: authService,
 	}
 }
 
 func (h *FeedHandlers) CreatePost(c *fiber.Ctx) error {
-	authHeader := c.Get("Authorization")
-	if authHeader == "" {
-		return c.Status(http.StatusUnauthorized).JSON(domain.ErrorResponse{Error: "Authorization header is missing"})
+	ctx, err := feedContext(c)
+	if err != nil {
+		return err
 	}
 
-	token := strings.TrimPrefix(authHeader, "Bearer ")
-	if token == authHeader {
-		return c.Status(http.StatusUnauthorized).JSON(domain.ErrorResponse{Error: "Invalid authorization header format"})
+	var req domain.CreatePostRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
 	}
 
-	user, err := h.auth.Me(c.Context(), token)
+	post, err := h.feedService.CreatePost(ctx, &req)
 	if err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(domain.ErrorResponse{Error: "Invalid token"})
+		return err
 	}
 
-	var req domain.CreatePostRequest
+	return c.JSON(post)
+}
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(domain.ErrorResponse{Error: "Invalid request body"})
+// GetAllPosts pages through the feed by cursor. Clients that still send page
+// get the deprecated offset pagination.
+func (h *FeedHandlers) GetAllPosts(c *fiber.Ctx) error {
+	query := domain.PostsQuery{
+		Cursor:       c.Query("cursor"),
+		PageSize:     c.QueryInt("pageSize", 10),
+		IncludeTotal: c.QueryBool("includeTotal"),
+		Page:         c.QueryInt("page"),
 	}
 
-	
+	response, err := h.feedService.GetAllPosts(optionalFeedContext(c), query)
+	if err != nil {
+		return err
+	}
 
-	md := metadata.Pairs("user_id", user.ID)
-	ctx := metadata.NewOutgoingContext(c.Context(), md)
+	if err := h.embedAuthors(c, response.Posts); err != nil {
+		return err
+	}
 
-	post, err := h.feedService.CreatePost(ctx, &req)
+	return c.JSON(response)
+}
+
+func (h *FeedHandlers) GetPost(c *fiber.Ctx) error {
+	post, err := h.feedService.GetPost(optionalFeedContext(c), c.Params("id"))
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(domain.ErrorResponse{Error: "Failed Create Post"})
+		return err
+	}
+
+	if err := h.embedAuthors(c, []*domain.PostResponse{post}); err != nil {
+		return err
 	}
 
 	return c.JSON(post)
 }
 
-func (h *FeedHandlers) GetAllPosts(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	pageSize := c.QueryInt("pageSize", 10)
+func (h *FeedHandlers) UpdatePost(c *fiber.Ctx) error {
+	ctx, err := feedContext(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.UpdatePostRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	post, err := h.feedService.UpdatePost(ctx, c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
 
-	posts, totalPosts, err := h.feedService.GetAllPosts(c.Context(), page, pageSize)
+	return c.JSON(post)
+}
+
+func (h *FeedHandlers) DeletePost(c *fiber.Ctx) error {
+	ctx, err := feedContext(c)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(domain.ErrorResponse{Error: "Failed Get all posts"})
+		return err
+	}
+
+	success, err := h.feedService.DeletePost(ctx, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+// feedContext passes the access token of the request on to FeedService, which
+// verifies it itself.
+func feedContext(c *fiber.Ctx) (context.Context, error) {
+	token, err := bearerToken(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.AppendToOutgoingContext(clientContext(c), "authorization", "Bearer "+token), nil
+}
+
+// optionalFeedContext passes the authorization header on when the client sent
+// one, so public feed reads can tell the caller's own reactions.
+func optionalFeedContext(c *fiber.Ctx) context.Context {
+	ctx := clientContext(c)
+	if authHeader := c.Get("Authorization"); authHeader != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", authHeader)
+	}
+
+	return ctx
+}
+
+// embedAuthors resolves the authors of the posts with a single batch call to
+// AuthService so the posts show who wrote them without exposing emails.
+func (h *FeedHandlers) embedAuthors(c *fiber.Ctx, posts []*domain.PostResponse) error {
+	userIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		userIDs = append(userIDs, post.UserID)
+	}
+
+	authors, err := h.authors(c, userIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.Author = authors[post.UserID]
+	}
+
+	return nil
+}
+
+// authors returns the profile summaries of the users that have a profile.
+func (h *FeedHandlers) authors(c *fiber.Ctx, userIDs []string) (map[string]*domain.Author, error) {
+	seen := make(map[string]bool, len(userIDs))
+	unique := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if !seen[userID] {
+			seen[userID] = true
+			unique = append(unique, userID)
+		}
+	}
+
+	if len(unique) == 0 {
+		return nil, nil
+	}
+
+	profiles, err := h.authService.GetProfilesBatch(clientContext(c), unique)
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make(map[string]*domain.Author, len(profiles))
+	for userID, profile := range profiles {
+		authors[userID] = &domain.Author{
+			ID:          profile.UserID,
+			Username:    profile.Username,
+			DisplayName: profile.DisplayName,
+			AvatarURL:   profile.AvatarURL,
+		}
 	}
 
-	return c.JSON(domain.AllPostResponse{Posts: posts, TotalPosts: totalPosts})
+	return authors, nil
 }
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"Project/APIGateWay/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *FeedHandlers) Follow(c *fiber.Ctx) error {
+	ctx, err := feedContext(c)
+	if err != nil {
+		return err
+	}
+
+	success, err := h.feedService.Follow(ctx, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *FeedHandlers) Unfollow(c *fiber.Ctx) error {
+	ctx, err := feedContext(c)
+	if err != nil {
+		return err
+	}
+
+	success, err := h.feedService.Unfollow(ctx, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+func (h *FeedHandlers) ListFollowers(c *fiber.Ctx) error {
+	response, err := h.feedService.ListFollowers(clientContext(c), c.Params("id"), c.QueryInt("page", 1), c.QueryInt("pageSize", 20))
+	if err != nil {
+		return err
+	}
+
+	if err := h.embedProfiles(c, response.Follows); err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+func (h *FeedHandlers) ListFollowing(c *fiber.Ctx) error {
+	response, err := h.feedService.ListFollowing(clientContext(c), c.Params("id"), c.QueryInt("page", 1), c.QueryInt("pageSize", 20))
+	if err != nil {
+		return err
+	}
+
+	if err := h.embedProfiles(c, response.Follows); err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+func (h *FeedHandlers) GetHomeTimeline(c *fiber.Ctx) error {
+	ctx, err := feedContext(c)
+	if err != nil {
+		return err
+	}
+
+	response, err := h.feedService.GetHomeTimeline(ctx, c.Query("cursor"), c.QueryInt("pageSize", 10))
+	if err != nil {
+		return err
+	}
+
+	if err := h.embedAuthors(c, response.Posts); err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+func (h *FeedHandlers) embedProfiles(c *fiber.Ctx, follows []*domain.Follow) error {
+	userIDs := make([]string, 0, len(follows))
+	for _, follow := range follows {
+		userIDs = append(userIDs, follow.UserID)
+	}
+
+	profiles, err := h.authors(c, userIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, follow := range follows {
+		follow.Profile = profiles[follow.UserID]
+	}
+
+	return nil
+}
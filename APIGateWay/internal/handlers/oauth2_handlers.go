@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"Project/APIGateWay/internal/domain"
+	"Project/APIGateWay/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizationServerHandlers expose AuthService as an OAuth2 authorization
+// server to third-party clients. The token, introspection and revocation
+// endpoints speak RFC 6749 form encoding and error bodies instead of the
+// JSON API of the rest of the gateway.
+type AuthorizationServerHandlers struct {
+	authService *service.AuthService
+}
+
+func NewAuthorizationServerHandlers(authService *service.AuthService) *AuthorizationServerHandlers {
+	return &AuthorizationServerHandlers{
+		authService: authService,
+	}
+}
+
+func (h *AuthorizationServerHandlers) RegisterClient(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.RegisterOAuthClientRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	response, err := h.authService.RegisterOAuthClient(clientContext(c), accessToken, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+// Authorize is called by the consent page of the frontend on behalf of the
+// logged in user. The user agent is sent to RedirectTo afterwards.
+func (h *AuthorizationServerHandlers) Authorize(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.AuthorizeOAuthClientRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	response, err := h.authService.AuthorizeOAuthClient(clientContext(c), accessToken, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+func (h *AuthorizationServerHandlers) Token(c *fiber.Ctx) error {
+	var req domain.OAuthTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	response, err := h.authService.OAuthToken(clientContext(c), clientCredentials(c), &req)
+	if err != nil {
+		return oauthErrorFromStatus(c, err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	return c.JSON(response)
+}
+
+func (h *AuthorizationServerHandlers) Introspect(c *fiber.Ctx) error {
+	var req domain.OAuthTokenHandleRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+	}
+
+	response, err := h.authService.IntrospectOAuthToken(clientContext(c), clientCredentials(c), &req)
+	if err != nil {
+		return oauthErrorFromStatus(c, err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	return c.JSON(response)
+}
+
+// Revoke answers 200 for unknown tokens as well, see RFC 7009 section 2.2.
+func (h *AuthorizationServerHandlers) Revoke(c *fiber.Ctx) error {
+	var req domain.OAuthTokenHandleRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+	}
+
+	if err := h.authService.RevokeOAuthToken(clientContext(c), clientCredentials(c), &req); err != nil {
+		return oauthErrorFromStatus(c, err)
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+func (h *AuthorizationServerHandlers) RevokeConsent(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	success, err := h.authService.RevokeOAuthConsent(clientContext(c), accessToken, c.Params("client_id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(success)
+}
+
+// clientCredentials reads HTTP Basic authentication, whose values are form
+// encoded (RFC 6749 section 2.3.1), and falls back to the form parameters.
+func clientCredentials(c *fiber.Ctx) domain.OAuthClientCredentials {
+	if encoded, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Basic "); ok {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err == nil {
+			id, secret, _ := strings.Cut(string(decoded), ":")
+			id, idErr := url.QueryUnescape(id)
+			secret, secretErr := url.QueryUnescape(secret)
+			if idErr == nil && secretErr == nil {
+				return domain.OAuthClientCredentials{ClientID: id, ClientSecret: secret}
+			}
+		}
+
+		return domain.OAuthClientCredentials{}
+	}
+
+	return domain.OAuthClientCredentials{
+		ClientID:     c.FormValue("client_id"),
+		ClientSecret: c.FormValue("client_secret"),
+	}
+}
+
+// oauthErrorFromStatus renders the RFC 6749 error AuthService attached to the
+// status. Other errors go through the regular error handler.
+func oauthErrorFromStatus(c *fiber.Ctx, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != "oauth2" {
+			continue
+		}
+
+		httpStatus := http.StatusBadRequest
+		if st.Code() == codes.Unauthenticated {
+			httpStatus = http.StatusUnauthorized
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth2"`)
+		}
+
+		return oauthError(c, httpStatus, info.Reason, info.Metadata["error_description"])
+	}
+
+	return err
+}
+
+func oauthError(c *fiber.Ctx, httpStatus int, code, description string) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	return c.Status(httpStatus).JSON(domain.OAuthErrorResponse{Error: code, ErrorDescription: description})
+}
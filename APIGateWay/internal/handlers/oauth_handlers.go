@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"Project/APIGateWay/internal/oauth"
+	"Project/APIGateWay/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const oauthFlowTTL = 10 * time.Minute
+
+var (
+	errOAuthFlowInvalid = fiber.NewError(http.StatusBadRequest, "Login flow expired or was started in another browser")
+	errProviderFailed   = fiber.NewError(http.StatusBadGateway, "Identity provider request failed")
+)
+
+type OAuthHandlers struct {
+	authService  *service.AuthService
+	providers    *oauth.Providers
+	cookieSecure bool
+}
+
+func NewOAuthHandlers(authService *service.AuthService, providers *oauth.Providers, cookieSecure bool) *OAuthHandlers {
+	return &OAuthHandlers{
+		authService:  authService,
+		providers:    providers,
+		cookieSecure: cookieSecure,
+	}
+}
+
+// Start redirects to the provider. The state and the PKCE verifier are kept in
+// a short lived cookie scoped to the callback of the provider.
+func (h *OAuthHandlers) Start(c *fiber.Ctx) error {
+	provider, err := h.provider(c)
+	if err != nil {
+		return err
+	}
+
+	state, err := oauth.NewState()
+	if err != nil {
+		return err
+	}
+
+	verifier, challenge, err := oauth.NewPKCE()
+	if err != nil {
+		return err
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Context(), state, challenge)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errProviderFailed, err)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthCookieName(provider),
+		Value:    state + "." + verifier,
+		Path:     "/oauth/" + provider.Name(),
+		MaxAge:   int(oauthFlowTTL.Seconds()),
+		Secure:   h.cookieSecure,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect(authURL, http.StatusFound)
+}
+
+// Callback finishes the flow and logs the user in like /login does.
+func (h *OAuthHandlers) Callback(c *fiber.Ctx) error {
+	provider, err := h.provider(c)
+	if err != nil {
+		return err
+	}
+
+	cookie := c.Cookies(oauthCookieName(provider))
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthCookieName(provider),
+		Path:     "/oauth/" + provider.Name(),
+		Expires:  time.Unix(0, 0),
+		Secure:   h.cookieSecure,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	if errCode := c.Query("error"); errCode != "" {
+		return fiber.NewError(http.StatusUnauthorized, "Sign in was cancelled at the identity provider: "+errCode)
+	}
+
+	state, verifier, ok := strings.Cut(cookie, ".")
+	if !ok || subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+		return errOAuthFlowInvalid
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		return fiber.NewError(http.StatusBadRequest, "Authorization code is missing")
+	}
+
+	identity, err := provider.Identity(c.Context(), code, verifier)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errProviderFailed, err)
+	}
+
+	response, err := h.authService.LoginWithExternalIdentity(clientContext(c), identity)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response)
+}
+
+func (h *OAuthHandlers) provider(c *fiber.Ctx) (*oauth.Provider, error) {
+	provider, err := h.providers.Get(c.Params("provider"))
+	if errors.Is(err, oauth.ErrUnknownProvider) {
+		return nil, fiber.NewError(http.StatusNotFound, "Unknown identity provider")
+	}
+
+	return provider, err
+}
+
+func oauthCookieName(provider *oauth.Provider) string {
+	return "oauth_" + provider.Name()
+}
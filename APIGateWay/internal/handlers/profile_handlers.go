@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"Project/APIGateWay/internal/domain"
+	"Project/APIGateWay/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProfileHandlers struct {
+	authService *service.AuthService
+}
+
+func NewProfileHandlers(authService *service.AuthService) *ProfileHandlers {
+	return &ProfileHandlers{
+		authService: authService,
+	}
+}
+
+func (h *ProfileHandlers) UpdateProfile(c *fiber.Ctx) error {
+	accessToken, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req domain.UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	profile, err := h.authService.UpdateProfile(clientContext(c), accessToken, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(profile)
+}
+
+func (h *ProfileHandlers) GetProfile(c *fiber.Ctx) error {
+	profile, err := h.authService.GetProfile(clientContext(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(profile)
+}
+
+func (h *ProfileHandlers) GetProfileByUsername(c *fiber.Ctx) error {
+	profile, err := h.authService.GetProfileByUsername(clientContext(c), c.Params("username"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(profile)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"Project/APIGateWay/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *FeedHandlers) LikePost(c *fiber.Ctx) error {
+	ctx, err := feedContext(c)
+	if err != nil {
+		return err
+	}
+
+	post, err := h.feedService.LikePost(ctx, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return h.reactedPost(c, post)
+}
+
+func (h *FeedHandlers) UnlikePost(c *fiber.Ctx) error {
+	ctx, err := feedContext(c)
+	if err != nil {
+		return err
+	}
+
+	post, err := h.feedService.UnlikePost(ctx, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return h.reactedPost(c, post)
+}
+
+func (h *FeedHandlers) AddReaction(c *fiber.Ctx) error {
+	ctx, err := feedContext(c)
+	if err != nil {
+		return err
+	}
+
+	post, err := h.feedService.AddReaction(ctx, c.Params("id"), c.Params("reaction"))
+	if err != nil {
+		return err
+	}
+
+	return h.reactedPost(c, post)
+}
+
+func (h *FeedHandlers) RemoveReaction(c *fiber.Ctx) error {
+	ctx, err := feedContext(c)
+	if err != nil {
+		return err
+	}
+
+	post, err := h.feedService.RemoveReaction(ctx, c.Params("id"), c.Params("reaction"))
+	if err != nil {
+		return err
+	}
+
+	return h.reactedPost(c, post)
+}
+
+// reactedPost responds with the post a reaction changed and its new counts.
+func (h *FeedHandlers) reactedPost(c *fiber.Ctx, post *domain.PostResponse) error {
+	if err := h.embedAuthors(c, []*domain.PostResponse{post}); err != nil {
+		return err
+	}
+
+	return c.JSON(post)
+}
@@ -0,0 +1,296 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"Project/APIGateWay/internal/config"
+	"Project/APIGateWay/internal/domain"
+)
+
+const (
+	KindOIDC   = "oidc"
+	KindGitHub = "github"
+
+	githubAuthorizationURL = "https://github.com/login/oauth/authorize"
+	githubTokenURL         = "https://github.com/login/oauth/access_token"
+	githubAPIURL           = "https://api.github.com"
+)
+
+var ErrUnknownProvider = errors.New("unknown identity provider")
+
+type endpoints struct {
+	Authorization string `json:"authorization_endpoint"`
+	Token         string `json:"token_endpoint"`
+	UserInfo      string `json:"userinfo_endpoint"`
+}
+
+// Provider runs the authorization code flow with PKCE against one identity
+// provider. OIDC providers are configured through discovery on first use;
+// GitHub is not an OIDC provider and uses its fixed OAuth endpoints and REST API.
+type Provider struct {
+	name   string
+	cfg    config.OAuthProviderConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	endpoints *endpoints
+}
+
+// Providers holds the configured providers by name, the {provider} of the
+// gateway routes.
+type Providers struct {
+	providers map[string]*Provider
+}
+
+func NewProviders(cfgs map[string]config.OAuthProviderConfig) (*Providers, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	providers := make(map[string]*Provider, len(cfgs))
+	for name, cfg := range cfgs {
+		if cfg.Kind == "" {
+			cfg.Kind = KindOIDC
+		}
+
+		provider := &Provider{name: name, cfg: cfg, client: client}
+
+		switch cfg.Kind {
+		case KindOIDC:
+			if cfg.Issuer == "" {
+				return nil, fmt.Errorf("oauth provider %q: issuer is required", name)
+			}
+		case KindGitHub:
+			provider.endpoints = &endpoints{
+				Authorization: githubAuthorizationURL,
+				Token:         githubTokenURL,
+				UserInfo:      githubAPIURL + "/user",
+			}
+		default:
+			return nil, fmt.Errorf("oauth provider %q: unknown kind %q", name, cfg.Kind)
+		}
+
+		providers[name] = provider
+	}
+
+	return &Providers{providers: providers}, nil
+}
+
+func (p *Providers) Get(name string) (*Provider, error) {
+	provider, ok := p.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	return provider, nil
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL is where the browser is sent to sign in at the provider.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, codeChallenge string) (string, error) {
+	endpoints, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(endpoints.Authorization, "?") {
+		separator = "&"
+	}
+
+	return endpoints.Authorization + separator + query.Encode(), nil
+}
+
+// Identity exchanges the authorization code and returns the account the user
+// signed in with at the provider.
+func (p *Provider) Identity(ctx context.Context, code, codeVerifier string) (*domain.ExternalIdentity, error) {
+	endpoints, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := p.exchange(ctx, endpoints.Token, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.cfg.Kind == KindGitHub {
+		return p.githubIdentity(ctx, accessToken)
+	}
+
+	return p.oidcIdentity(ctx, endpoints.UserInfo, accessToken)
+}
+
+func (p *Provider) discover(ctx context.Context) (*endpoints, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.endpoints != nil {
+		return p.endpoints, nil
+	}
+
+	discoveryURL := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+
+	var discovered endpoints
+	if err := p.getJSON(ctx, discoveryURL, "", &discovered); err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", p.name, err)
+	}
+
+	if discovered.Authorization == "" || discovered.Token == "" || discovered.UserInfo == "" {
+		return nil, fmt.Errorf("discovery document of %s misses an endpoint", p.name)
+	}
+
+	p.endpoints = &discovered
+	return p.endpoints, nil
+}
+
+func (p *Provider) exchange(ctx context.Context, tokenURL, code, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"client_secret": {p.cfg.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := p.do(req, &token); err != nil {
+		return "", fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	if token.Error != "" {
+		return "", fmt.Errorf("failed to exchange code: %s: %s", token.Error, token.ErrorDescription)
+	}
+
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("failed to exchange code: no access token in response")
+	}
+
+	return token.AccessToken, nil
+}
+
+func (p *Provider) oidcIdentity(ctx context.Context, userInfoURL, accessToken string) (*domain.ExternalIdentity, error) {
+	var userInfo struct {
+		Subject       string          `json:"sub"`
+		Email         string          `json:"email"`
+		EmailVerified json.RawMessage `json:"email_verified"`
+	}
+	if err := p.getJSON(ctx, userInfoURL, accessToken, &userInfo); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	if userInfo.Subject == "" {
+		return nil, fmt.Errorf("user info of %s has no subject", p.name)
+	}
+
+	// Some providers send email_verified as the string "true".
+	verified := strings.Trim(string(userInfo.EmailVerified), `"`) == "true"
+
+	return &domain.ExternalIdentity{
+		Provider:      p.name,
+		Subject:       userInfo.Subject,
+		Email:         userInfo.Email,
+		EmailVerified: verified,
+	}, nil
+}
+
+func (p *Provider) githubIdentity(ctx context.Context, accessToken string) (*domain.ExternalIdentity, error) {
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := p.getJSON(ctx, githubAPIURL+"/user", accessToken, &user); err != nil {
+		return nil, fmt.Errorf("failed to get github user: %w", err)
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(ctx, githubAPIURL+"/user/emails", accessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to get github emails: %w", err)
+	}
+
+	identity := &domain.ExternalIdentity{
+		Provider: p.name,
+		Subject:  fmt.Sprint(user.ID),
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, rawURL, accessToken string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	return p.do(req, v)
+}
+
+func (p *Provider) do(req *http.Request, v interface{}) error {
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Token endpoints report errors with 400 and a JSON body.
+	if res.StatusCode > http.StatusBadRequest {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"Project/APIGateWay/internal/domain"
+	"Project/APIGateWay/internal/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// httpStatusByCode maps gRPC codes to HTTP statuses the way grpc-gateway does,
+// except FailedPrecondition which is 403 because it means the account is not
+// allowed to do this yet, e.g. the email is not confirmed.
+var httpStatusByCode = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusForbidden,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// codeNames are the canonical names of the gRPC codes, as in
+// google.rpc.Code, used as the code of error responses.
+var codeNames = map[codes.Code]string{
+	codes.OK:                 "OK",
+	codes.Canceled:           "CANCELLED",
+	codes.Unknown:            "UNKNOWN",
+	codes.InvalidArgument:    "INVALID_ARGUMENT",
+	codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+	codes.NotFound:           "NOT_FOUND",
+	codes.AlreadyExists:      "ALREADY_EXISTS",
+	codes.PermissionDenied:   "PERMISSION_DENIED",
+	codes.ResourceExhausted:  "RESOURCE_EXHAUSTED",
+	codes.FailedPrecondition: "FAILED_PRECONDITION",
+	codes.Aborted:            "ABORTED",
+	codes.OutOfRange:         "OUT_OF_RANGE",
+	codes.Unimplemented:      "UNIMPLEMENTED",
+	codes.Internal:           "INTERNAL",
+	codes.Unavailable:        "UNAVAILABLE",
+	codes.DataLoss:           "DATA_LOSS",
+	codes.Unauthenticated:    "UNAUTHENTICATED",
+}
+
+// codeByHTTPStatus names errors raised by the gateway itself.
+var codeByHTTPStatus = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusMethodNotAllowed:      codes.Unimplemented,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusBadGateway:            codes.Unavailable,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+}
+
+// newErrorHandler renders every error returned by a handler as a
+// domain.ErrorResponse. gRPC errors from the backend services are mapped by
+// their code, *fiber.Error by its HTTP status.
+func newErrorHandler(log *logger.Logger) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		httpStatus, body := errorResponse(err)
+		body.RequestID, _ = c.Locals("requestid").(string)
+
+		if httpStatus >= http.StatusInternalServerError {
+			log.Error("Request failed",
+				zap.String("requestID", body.RequestID),
+				zap.String("method", c.Method()),
+				zap.String("path", c.Path()),
+				zap.Error(err),
+			)
+		}
+
+		return c.Status(httpStatus).JSON(domain.ErrorResponse{Error: body})
+	}
+}
+
+func errorResponse(err error) (int, domain.ErrorBody) {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code, ok := codeByHTTPStatus[fiberErr.Code]
+		if !ok {
+			code = codes.Unknown
+		}
+
+		return fiberErr.Code, domain.ErrorBody{Code: codeName(code), Message: fiberErr.Message}
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return http.StatusInternalServerError, domain.ErrorBody{Code: codeName(codes.Internal), Message: "Internal server error"}
+	}
+
+	httpStatus, ok := httpStatusByCode[st.Code()]
+	if !ok {
+		httpStatus = http.StatusInternalServerError
+	}
+
+	body := domain.ErrorBody{Code: codeName(st.Code()), Message: st.Message()}
+	if httpStatus >= http.StatusInternalServerError {
+		body.Message = "Internal server error"
+	}
+
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.FieldViolations {
+				body.Fields = append(body.Fields, domain.FieldViolation{Field: v.Field, Description: v.Description})
+			}
+		}
+	}
+
+	return httpStatus, body
+}
+
+// codeName turns codes.InvalidArgument into "INVALID_ARGUMENT". Codes outside
+// the canonical set are "UNKNOWN".
+func codeName(code codes.Code) string {
+	if name, ok := codeNames[code]; ok {
+		return name
+	}
+
+	return codeNames[codes.Unknown]
+}
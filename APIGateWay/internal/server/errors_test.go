@@ -0,0 +1,186 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"Project/APIGateWay/internal/domain"
+	"Project/APIGateWay/internal/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorResponse(t *testing.T) {
+	invalidEmail, err := status.New(codes.InvalidArgument, "invalid request").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "email", Description: "must be a valid email"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to attach details: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   domain.ErrorBody
+	}{
+		{
+			name:       "invalid argument keeps the field violations",
+			err:        invalidEmail.Err(),
+			wantStatus: http.StatusBadRequest,
+			wantBody: domain.ErrorBody{
+				Code:    "INVALID_ARGUMENT",
+				Message: "invalid request",
+				Fields:  []domain.FieldViolation{{Field: "email", Description: "must be a valid email"}},
+			},
+		},
+		{
+			name:       "unauthenticated",
+			err:        status.Error(codes.Unauthenticated, "invalid or expired access token"),
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   domain.ErrorBody{Code: "UNAUTHENTICATED", Message: "invalid or expired access token"},
+		},
+		{
+			name:       "permission denied",
+			err:        status.Error(codes.PermissionDenied, "permission denied"),
+			wantStatus: http.StatusForbidden,
+			wantBody:   domain.ErrorBody{Code: "PERMISSION_DENIED", Message: "permission denied"},
+		},
+		{
+			name:       "failed precondition is forbidden",
+			err:        status.Error(codes.FailedPrecondition, "email is not confirmed"),
+			wantStatus: http.StatusForbidden,
+			wantBody:   domain.ErrorBody{Code: "FAILED_PRECONDITION", Message: "email is not confirmed"},
+		},
+		{
+			name:       "not found",
+			err:        status.Error(codes.NotFound, "post not found"),
+			wantStatus: http.StatusNotFound,
+			wantBody:   domain.ErrorBody{Code: "NOT_FOUND", Message: "post not found"},
+		},
+		{
+			name:       "already exists",
+			err:        status.Error(codes.AlreadyExists, "email already exists"),
+			wantStatus: http.StatusConflict,
+			wantBody:   domain.ErrorBody{Code: "ALREADY_EXISTS", Message: "email already exists"},
+		},
+		{
+			name:       "resource exhausted",
+			err:        status.Error(codes.ResourceExhausted, "too many login attempts"),
+			wantStatus: http.StatusTooManyRequests,
+			wantBody:   domain.ErrorBody{Code: "RESOURCE_EXHAUSTED", Message: "too many login attempts"},
+		},
+		{
+			name:       "unavailable hides the message",
+			err:        status.Error(codes.Unavailable, "connection refused"),
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   domain.ErrorBody{Code: "UNAVAILABLE", Message: "Internal server error"},
+		},
+		{
+			name:       "internal hides the message",
+			err:        status.Error(codes.Internal, "pq: relation does not exist"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   domain.ErrorBody{Code: "INTERNAL", Message: "Internal server error"},
+		},
+		{
+			name:       "gateway error keeps its status",
+			err:        fiber.NewError(http.StatusUnauthorized, "Authorization header is missing"),
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   domain.ErrorBody{Code: "UNAUTHENTICATED", Message: "Authorization header is missing"},
+		},
+		{
+			name:       "bad gateway is unavailable",
+			err:        fiber.NewError(http.StatusBadGateway, "upstream failed"),
+			wantStatus: http.StatusBadGateway,
+			wantBody:   domain.ErrorBody{Code: "UNAVAILABLE", Message: "upstream failed"},
+		},
+		{
+			name:       "gateway error with an unnamed status",
+			err:        fiber.NewError(http.StatusTeapot, "short and stout"),
+			wantStatus: http.StatusTeapot,
+			wantBody:   domain.ErrorBody{Code: "UNKNOWN", Message: "short and stout"},
+		},
+		{
+			name:       "plain error is internal",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   domain.ErrorBody{Code: "INTERNAL", Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStatus, gotBody := errorResponse(tt.err)
+
+			if gotStatus != tt.wantStatus {
+				t.Errorf("status = %d, want %d", gotStatus, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("body = %+v, want %+v", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCodeName(t *testing.T) {
+	tests := map[codes.Code]string{
+		codes.OK:                 "OK",
+		codes.Canceled:           "CANCELLED",
+		codes.InvalidArgument:    "INVALID_ARGUMENT",
+		codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+		codes.FailedPrecondition: "FAILED_PRECONDITION",
+		codes.OutOfRange:         "OUT_OF_RANGE",
+		codes.DataLoss:           "DATA_LOSS",
+		codes.Code(42):           "UNKNOWN",
+	}
+
+	for code, want := range tests {
+		if got := codeName(code); got != want {
+			t.Errorf("codeName(%v) = %q, want %q", code, got, want)
+		}
+	}
+
+	for code := range httpStatusByCode {
+		if _, ok := codeNames[code]; !ok {
+			t.Errorf("code %v has an HTTP status but no name", code)
+		}
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: newErrorHandler(&logger.Logger{Logger: zap.NewNop()})})
+	app.Get("/posts/:id", func(c *fiber.Ctx) error {
+		c.Locals("requestid", "request-1")
+		return status.Error(codes.NotFound, "post not found")
+	})
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", res.StatusCode, http.StatusNotFound)
+	}
+
+	var body domain.ErrorResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+
+	want := domain.ErrorBody{Code: "NOT_FOUND", Message: "post not found", RequestID: "request-1"}
+	if !reflect.DeepEqual(body.Error, want) {
+		t.Errorf("body = %+v, want %+v", body.Error, want)
+	}
+}
@@ -2,18 +2,28 @@ package server
 
 import (
 	"Project/APIGateWay/internal/handlers"
+	"Project/APIGateWay/internal/logger"
+	"Project/APIGateWay/internal/oauth"
 	"Project/APIGateWay/internal/service"
+	"Project/pkg/authz"
+	"Project/pkg/revocation"
 
 	swagger "github.com/arsmn/fiber-swagger/v2"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 type Server struct {
 	app *fiber.App
 }
 
-func NewServer(authService *service.AuthService, feedService *service.FeedService) *Server {
-	app := fiber.New()
+func NewServer(log *logger.Logger, authService *service.AuthService, feedService *service.FeedService, revokedTokens *revocation.Cache, oauthProviders *oauth.Providers, oauthCookieSecure bool) *Server {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: newErrorHandler(log),
+	})
+
+	app.Use(requestid.New())
+	app.Use(handlers.RejectRevokedTokens(revokedTokens))
 
 	app.Static("/", "/app")
 
@@ -22,17 +32,82 @@ func NewServer(authService *service.AuthService, feedService *service.FeedServic
 	}))
 
 	authHandlers := handlers.NewAuthHandlers(authService)
-	feedHandlers := handlers.NewFeedHandlers(feedService)
+	feedHandlers := handlers.NewFeedHandlers(feedService, authService)
+	oauthHandlers := handlers.NewOAuthHandlers(authService, oauthProviders, oauthCookieSecure)
+	authorizationServerHandlers := handlers.NewAuthorizationServerHandlers(authService)
+	adminHandlers := handlers.NewAdminHandlers(authService)
+	accountHandlers := handlers.NewAccountHandlers(authService, feedService)
+	profileHandlers := handlers.NewProfileHandlers(authService)
 
 	app.Post("/register", authHandlers.Register)
 	app.Post("/login", authHandlers.Login)
+	app.Post("/login/mfa", authHandlers.LoginMFA)
+	app.Post("/login/magic-link", authHandlers.RequestMagicLink)
+	app.Post("/login/magic-link/consume", authHandlers.ConsumeMagicLink)
 	app.Post("/logout", authHandlers.Logout)
+	app.Post("/logout/all", authHandlers.LogoutAll)
 	app.Post("/refresh", authHandlers.Refresh)
 	app.Get("/me", authHandlers.Me)
+	app.Delete("/me", accountHandlers.DeleteAccount)
+	app.Get("/me/export", accountHandlers.ExportMyData)
+	app.Get("/me/auth-events", accountHandlers.ListMyAuthEvents)
+	app.Put("/me/profile", profileHandlers.UpdateProfile)
+	app.Get("/users/:id/profile", profileHandlers.GetProfile)
+	app.Get("/profiles/:username", profileHandlers.GetProfileByUsername)
 	app.Post("/confirm-email", authHandlers.ConfirmEmail)
+	app.Post("/confirm-email/resend", authHandlers.ResendConfirmationCode)
+	app.Post("/password/forgot", authHandlers.ForgotPassword)
+	app.Post("/password/reset", authHandlers.ResetPassword)
+	app.Post("/password/change", authHandlers.ChangePassword)
+	app.Post("/email/change", authHandlers.ChangeEmail)
+	app.Post("/email/change/confirm", authHandlers.ConfirmEmailChange)
+	app.Post("/2fa/enable", authHandlers.Enable2FA)
+	app.Post("/2fa/verify", authHandlers.Verify2FA)
+	app.Get("/sessions", authHandlers.ListSessions)
+	app.Delete("/sessions/:id", authHandlers.RevokeSession)
+	app.Post("/sessions/revoke-others", authHandlers.RevokeAllOtherSessions)
+	app.Get("/.well-known/jwks.json", authHandlers.JWKS)
+	app.Get("/oauth/:provider/start", oauthHandlers.Start)
+	app.Get("/oauth/:provider/callback", oauthHandlers.Callback)
+	app.Post("/oauth2/clients", authorizationServerHandlers.RegisterClient)
+	app.Post("/oauth2/authorize", authorizationServerHandlers.Authorize)
+	app.Post("/oauth2/token", authorizationServerHandlers.Token)
+	app.Post("/oauth2/introspect", authorizationServerHandlers.Introspect)
+	app.Post("/oauth2/revoke", authorizationServerHandlers.Revoke)
+	app.Delete("/oauth2/consents/:client_id", authorizationServerHandlers.RevokeConsent)
+
+	manageRoles := handlers.RequirePermissions(authService, authz.PermissionManageRoles)
+	revokeTokens := handlers.RequirePermissions(authService, authz.PermissionRevokeTokens)
+	manageUsers := handlers.RequirePermissions(authService, authz.PermissionManageUsers)
+	impersonateUsers := handlers.RequirePermissions(authService, authz.PermissionImpersonateUsers)
+	readAuditLog := handlers.RequirePermissions(authService, authz.PermissionReadAuditLog)
+
+	admin := app.Group("/admin")
+	admin.Get("/users/:id/roles", manageRoles, adminHandlers.ListUserRoles)
+	admin.Put("/users/:id/roles/:role", manageRoles, adminHandlers.AssignRole)
+	admin.Delete("/users/:id/roles/:role", manageRoles, adminHandlers.RevokeRole)
+	admin.Get("/users", manageUsers, adminHandlers.ListUsers)
+	admin.Post("/users/:id/suspend", manageUsers, adminHandlers.SuspendUser)
+	admin.Post("/users/:id/unsuspend", manageUsers, adminHandlers.UnsuspendUser)
+	admin.Post("/users/:id/logout", manageUsers, adminHandlers.ForceLogout)
+	admin.Post("/users/:id/impersonate", impersonateUsers, adminHandlers.ImpersonateUser)
+	admin.Post("/tokens/:jti/revoke", revokeTokens, adminHandlers.RevokeAccessToken)
+	admin.Get("/auth-events", readAuditLog, adminHandlers.ListAuthEvents)
 
 	app.Post("/posts", feedHandlers.CreatePost)
 	app.Get("/posts/all", feedHandlers.GetAllPosts)
+	app.Get("/posts/:id", feedHandlers.GetPost)
+	app.Patch("/posts/:id", feedHandlers.UpdatePost)
+	app.Delete("/posts/:id", feedHandlers.DeletePost)
+	app.Post("/posts/:id/like", feedHandlers.LikePost)
+	app.Delete("/posts/:id/like", feedHandlers.UnlikePost)
+	app.Post("/posts/:id/reactions/:reaction", feedHandlers.AddReaction)
+	app.Delete("/posts/:id/reactions/:reaction", feedHandlers.RemoveReaction)
+	app.Get("/timeline", feedHandlers.GetHomeTimeline)
+	app.Post("/users/:id/follow", feedHandlers.Follow)
+	app.Delete("/users/:id/follow", feedHandlers.Unfollow)
+	app.Get("/users/:id/followers", feedHandlers.ListFollowers)
+	app.Get("/users/:id/following", feedHandlers.ListFollowing)
 
 	return &Server{
 		app: app,
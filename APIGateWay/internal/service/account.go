@@ -0,0 +1,53 @@
+package service
+
+import (
+	"Project/APIGateWay/internal/domain"
+	"Project/proto/gen"
+	"context"
+)
+
+func (s *AuthService) DeleteAccount(ctx context.Context, accessToken string, req *domain.DeleteAccountRequest) (bool, error) {
+	grpcReq := &gen.DeleteAccountRequest{
+		AccessToken: accessToken,
+		Password:    req.Password,
+	}
+
+	res, err := s.client.DeleteAccount(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+// ExportMyData returns the part of the export AuthService holds; the posts
+// come from FeedService.
+func (s *AuthService) ExportMyData(ctx context.Context, accessToken string) (*domain.DataExport, error) {
+	grpcReq := &gen.ExportMyDataRequest{
+		AccessToken: accessToken,
+	}
+
+	res, err := s.client.ExportMyData(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	identities := make([]*domain.LinkedIdentity, 0, len(res.Identities))
+	for _, identity := range res.Identities {
+		identities = append(identities, &domain.LinkedIdentity{
+			Provider: identity.Provider,
+			Email:    identity.Email,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+
+	return &domain.DataExport{
+		User:           mapUser(res.User),
+		Profile:        mapProfile(res.Profile),
+		EmailConfirmed: res.EmailConfirmed,
+		CreatedAt:      res.CreatedAt,
+		Roles:          mapUserRoles(res.Roles),
+		Sessions:       mapSessions(res.Sessions),
+		Identities:     identities,
+	}, nil
+}
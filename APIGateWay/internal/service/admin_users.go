@@ -0,0 +1,99 @@
+package service
+
+import (
+	"Project/APIGateWay/internal/domain"
+	"Project/proto/gen"
+	"context"
+)
+
+func (s *AuthService) ListUsers(ctx context.Context, accessToken string, filter domain.UserFilter, page, pageSize int) (*domain.ListUsersResponse, error) {
+	grpcReq := &gen.ListUsersRequest{
+		AccessToken:   accessToken,
+		Page:          int32(page),
+		PageSize:      int32(pageSize),
+		Query:         filter.Query,
+		Confirmed:     filter.Confirmed,
+		Locked:        filter.Locked,
+		Suspended:     filter.Suspended,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+	}
+
+	res, err := s.client.ListUsers(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*domain.AdminUser, 0, len(res.Users))
+	for _, user := range res.Users {
+		users = append(users, &domain.AdminUser{
+			ID:              user.Id,
+			Email:           user.Email,
+			EmailConfirmed:  user.EmailConfirmed,
+			CreatedAt:       user.CreatedAt,
+			LockedUntil:     user.LockedUntil,
+			SuspendedAt:     user.SuspendedAt,
+			SuspendedReason: user.SuspendedReason,
+		})
+	}
+
+	return &domain.ListUsersResponse{Users: users, TotalUsers: int(res.TotalUsers)}, nil
+}
+
+func (s *AuthService) SuspendUser(ctx context.Context, accessToken, userID string, req *domain.SuspendUserRequest) (bool, error) {
+	grpcReq := &gen.SuspendUserRequest{
+		AccessToken: accessToken,
+		UserId:      userID,
+		Reason:      req.Reason,
+	}
+
+	res, err := s.client.SuspendUser(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) UnsuspendUser(ctx context.Context, accessToken, userID string) (bool, error) {
+	grpcReq := &gen.UnsuspendUserRequest{
+		AccessToken: accessToken,
+		UserId:      userID,
+	}
+
+	res, err := s.client.UnsuspendUser(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) ForceLogout(ctx context.Context, accessToken, userID string) (int, error) {
+	grpcReq := &gen.ForceLogoutRequest{
+		AccessToken: accessToken,
+		UserId:      userID,
+	}
+
+	res, err := s.client.ForceLogout(ctx, grpcReq)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(res.Revoked), nil
+}
+
+func (s *AuthService) ImpersonateUser(ctx context.Context, accessToken, userID string, req *domain.ImpersonateUserRequest) (*domain.ImpersonateUserResponse, error) {
+	grpcReq := &gen.ImpersonateUserRequest{
+		AccessToken: accessToken,
+		UserId:      userID,
+		Reason:      req.Reason,
+	}
+
+	res, err := s.client.ImpersonateUser(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ImpersonateUserResponse{AccessToken: res.AccessToken, ExpiresAt: res.ExpiresAt}, nil
+}
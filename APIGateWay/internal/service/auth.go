@@ -6,6 +6,7 @@ import (
 	"context"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 type AuthService struct {
@@ -18,6 +19,18 @@ func NewAuthService(conn *grpc.ClientConn) *AuthService {
 	}
 }
 
+// callerSecretKey is the metadata key AuthService expects the shared secret in.
+const callerSecretKey = "x-caller-secret"
+
+// CallerSecretInterceptor sends the secret shared with AuthService on every call,
+// which is how AuthService tells the gateway apart from other callers.
+func CallerSecretInterceptor(secret string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, callerSecretKey, secret)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
 func (s *AuthService) Register(ctx context.Context, req *domain.RegisterRequest) (bool, error) {
 	grpcReq := &gen.RegisterRequest{
 		Email:          req.Email,
@@ -33,33 +46,128 @@ func (s *AuthService) Register(ctx context.Context, req *domain.RegisterRequest)
 	return res.Success, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, req *domain.LoginRequest) (*domain.TokenPair, *domain.User, error) {
+func (s *AuthService) Login(ctx context.Context, req *domain.LoginRequest) (*domain.LoginResponse, error) {
 	grpcReq := &gen.LoginRequest{
-		Email:    req.Email,
-		Password: req.Password,
+		Email:      req.Email,
+		Password:   req.Password,
+		DeviceName: req.DeviceName,
 	}
 
 	res, err := s.client.Login(ctx, grpcReq)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	tokenPair := &domain.TokenPair{
-		AccessToken:  res.AccessToken.Token,
-		RefreshToken: res.RefreshToken.Token,
+	return mapLoginResponse(res), nil
+}
+
+func (s *AuthService) LoginMFA(ctx context.Context, req *domain.LoginMFARequest) (*domain.LoginResponse, error) {
+	grpcReq := &gen.LoginMFARequest{
+		MfaToken:   req.MFAToken,
+		Code:       req.Code,
+		DeviceName: req.DeviceName,
+	}
+
+	res, err := s.client.LoginMFA(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapLoginResponse(res), nil
+}
+
+func (s *AuthService) RequestMagicLink(ctx context.Context, req *domain.MagicLinkRequest) (bool, error) {
+	grpcReq := &gen.RequestMagicLinkRequest{
+		Email: req.Email,
+	}
+
+	res, err := s.client.RequestMagicLink(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) ConsumeMagicLink(ctx context.Context, req *domain.ConsumeMagicLinkRequest) (*domain.LoginResponse, error) {
+	grpcReq := &gen.ConsumeMagicLinkRequest{
+		Token:      req.Token,
+		DeviceName: req.DeviceName,
+	}
+
+	res, err := s.client.ConsumeMagicLink(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapLoginResponse(res), nil
+}
+
+func (s *AuthService) LoginWithExternalIdentity(ctx context.Context, identity *domain.ExternalIdentity) (*domain.LoginResponse, error) {
+	grpcReq := &gen.LoginWithExternalIdentityRequest{
+		Provider:      identity.Provider,
+		Subject:       identity.Subject,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+	}
+
+	res, err := s.client.LoginWithExternalIdentity(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapLoginResponse(res), nil
+}
+
+func mapLoginResponse(res *gen.LoginResponse) *domain.LoginResponse {
+	response := &domain.LoginResponse{
+		User: domain.User{
+			ID:    res.GetUser().GetId(),
+			Email: res.GetUser().GetEmail(),
+		},
+		MFARequired: res.MfaRequired,
+		MFAToken:    res.MfaToken,
 	}
 
-	user := &domain.User{
-		ID:    res.User.Id,
-		Email: res.User.Email,
+	if !res.MfaRequired {
+		response.Tokens = &domain.TokenPair{
+			AccessToken:  res.GetAccessToken().GetToken(),
+			RefreshToken: res.GetRefreshToken().GetToken(),
+		}
 	}
 
-	return tokenPair, user, nil
+	return response
 }
 
-func (s *AuthService) Logout(ctx context.Context, userID string) (bool, error) {
+func (s *AuthService) Enable2FA(ctx context.Context, accessToken string) (*domain.Enable2FAResponse, error) {
+	res, err := s.client.Enable2FA(ctx, &gen.Enable2FARequest{AccessToken: accessToken})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Enable2FAResponse{
+		Secret:     res.Secret,
+		OtpauthURI: res.OtpauthUri,
+	}, nil
+}
+
+func (s *AuthService) Verify2FA(ctx context.Context, accessToken string, req *domain.Verify2FARequest) (*domain.Verify2FAResponse, error) {
+	grpcReq := &gen.Verify2FARequest{
+		AccessToken: accessToken,
+		Code:        req.Code,
+	}
+
+	res, err := s.client.Verify2FA(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Verify2FAResponse{RecoveryCodes: res.RecoveryCodes}, nil
+}
+
+func (s *AuthService) Logout(ctx context.Context, accessToken string) (bool, error) {
 	grpcReq := &gen.LogoutRequest{
-		Id: userID,
+		AccessToken: accessToken,
 	}
 
 	res, err := s.client.Logout(ctx, grpcReq)
@@ -70,6 +178,19 @@ func (s *AuthService) Logout(ctx context.Context, userID string) (bool, error) {
 	return res.Success, nil
 }
 
+func (s *AuthService) LogoutAll(ctx context.Context, accessToken string) (int, error) {
+	grpcReq := &gen.LogoutAllRequest{
+		AccessToken: accessToken,
+	}
+
+	res, err := s.client.LogoutAll(ctx, grpcReq)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(res.Revoked), nil
+}
+
 func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
 	grpcReq := &gen.RefreshRequest{
 		RefreshToken: refreshToken,
@@ -96,10 +217,16 @@ func (s *AuthService) Me(ctx context.Context, accessToken string) (*domain.User,
 		return nil, err
 	}
 
+	return mapUser(res.User), nil
+}
+
+func mapUser(user *gen.User) *domain.User {
 	return &domain.User{
-		ID:    res.User.Id,
-		Email: res.User.Email,
-	}, nil
+		ID:          user.Id,
+		Email:       user.Email,
+		Roles:       user.Roles,
+		Permissions: user.Permissions,
+	}
 }
 
 func (s *AuthService) ConfirmEmail(ctx context.Context, req *domain.ConfirmationRequest) (bool, error) {
@@ -115,3 +242,168 @@ func (s *AuthService) ConfirmEmail(ctx context.Context, req *domain.Confirmation
 
 	return res.Success, nil
 }
+
+func (s *AuthService) ResendConfirmationCode(ctx context.Context, req *domain.ResendConfirmationRequest) (bool, error) {
+	grpcReq := &gen.ResendConfirmationCodeRequest{
+		Email: req.Email,
+	}
+
+	res, err := s.client.ResendConfirmationCode(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) RequestPasswordReset(ctx context.Context, req *domain.ForgotPasswordRequest) (bool, error) {
+	grpcReq := &gen.RequestPasswordResetRequest{
+		Email: req.Email,
+	}
+
+	res, err := s.client.RequestPasswordReset(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) (bool, error) {
+	grpcReq := &gen.ResetPasswordRequest{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	}
+
+	res, err := s.client.ResetPassword(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) ChangePassword(ctx context.Context, accessToken string, req *domain.ChangePasswordRequest) (bool, error) {
+	grpcReq := &gen.ChangePasswordRequest{
+		AccessToken: accessToken,
+		OldPassword: req.OldPassword,
+		NewPassword: req.NewPassword,
+	}
+
+	res, err := s.client.ChangePassword(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) ChangeEmail(ctx context.Context, accessToken string, req *domain.ChangeEmailRequest) (bool, error) {
+	grpcReq := &gen.ChangeEmailRequest{
+		AccessToken: accessToken,
+		NewEmail:    req.NewEmail,
+	}
+
+	res, err := s.client.ChangeEmail(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, accessToken string, req *domain.ConfirmEmailChangeRequest) (bool, error) {
+	grpcReq := &gen.ConfirmEmailChangeRequest{
+		AccessToken:      accessToken,
+		ConfirmationCode: req.ConfirmationCode,
+	}
+
+	res, err := s.client.ConfirmEmailChange(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) ListSessions(ctx context.Context, accessToken string) ([]*domain.Session, error) {
+	grpcReq := &gen.ListSessionsRequest{
+		AccessToken: accessToken,
+	}
+
+	res, err := s.client.ListSessions(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapSessions(res.Sessions), nil
+}
+
+func mapSessions(grpcSessions []*gen.Session) []*domain.Session {
+	sessions := make([]*domain.Session, 0, len(grpcSessions))
+	for _, session := range grpcSessions {
+		sessions = append(sessions, &domain.Session{
+			ID:         session.Id,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IP:         session.Ip,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.Current,
+		})
+	}
+
+	return sessions
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, accessToken, sessionID string) (bool, error) {
+	grpcReq := &gen.RevokeSessionRequest{
+		AccessToken: accessToken,
+		SessionId:   sessionID,
+	}
+
+	res, err := s.client.RevokeSession(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) RevokeAllOtherSessions(ctx context.Context, accessToken string) (int, error) {
+	grpcReq := &gen.RevokeAllOtherSessionsRequest{
+		AccessToken: accessToken,
+	}
+
+	res, err := s.client.RevokeAllOtherSessions(ctx, grpcReq)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(res.Revoked), nil
+}
+
+func (s *AuthService) GetJWKS(ctx context.Context) (*domain.JWKSResponse, error) {
+	res, err := s.client.GetJWKS(ctx, &gen.GetJWKSRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	jwks := &domain.JWKSResponse{
+		Keys: make([]domain.JSONWebKey, 0, len(res.Keys)),
+	}
+	for _, key := range res.Keys {
+		jwks.Keys = append(jwks.Keys, domain.JSONWebKey{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Alg: key.Alg,
+			Use: key.Use,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Crv,
+			X:   key.X,
+		})
+	}
+
+	return jwks, nil
+}
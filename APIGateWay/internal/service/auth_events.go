@@ -0,0 +1,65 @@
+package service
+
+import (
+	"Project/APIGateWay/internal/domain"
+	"Project/proto/gen"
+	"context"
+)
+
+func (s *AuthService) ListMyAuthEvents(ctx context.Context, accessToken string, from, to int64, page, pageSize int) (*domain.AuthEventsResponse, error) {
+	grpcReq := &gen.ListMyAuthEventsRequest{
+		AccessToken: accessToken,
+		Page:        int32(page),
+		PageSize:    int32(pageSize),
+		From:        from,
+		To:          to,
+	}
+
+	res, err := s.client.ListMyAuthEvents(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapAuthEvents(res), nil
+}
+
+func (s *AuthService) ListAuthEvents(ctx context.Context, accessToken string, filter domain.AuthEventFilter, page, pageSize int) (*domain.AuthEventsResponse, error) {
+	grpcReq := &gen.ListAuthEventsRequest{
+		AccessToken: accessToken,
+		Page:        int32(page),
+		PageSize:    int32(pageSize),
+		From:        filter.From,
+		To:          filter.To,
+		UserId:      filter.UserID,
+		Email:       filter.Email,
+		Event:       filter.Event,
+		Outcome:     filter.Outcome,
+	}
+
+	res, err := s.client.ListAuthEvents(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapAuthEvents(res), nil
+}
+
+func mapAuthEvents(res *gen.ListAuthEventsResponse) *domain.AuthEventsResponse {
+	events := make([]*domain.AuthEvent, 0, len(res.Events))
+	for _, event := range res.Events {
+		events = append(events, &domain.AuthEvent{
+			ID:        event.Id,
+			UserID:    event.UserId,
+			Email:     event.Email,
+			Event:     event.Event,
+			Outcome:   event.Outcome,
+			Reason:    event.Reason,
+			IP:        event.Ip,
+			UserAgent: event.UserAgent,
+			SessionID: event.SessionId,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	return &domain.AuthEventsResponse{Events: events, TotalEvents: int(res.TotalEvents)}
+}
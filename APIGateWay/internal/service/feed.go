@@ -4,7 +4,6 @@ import (
 	"Project/APIGateWay/internal/domain"
 	"Project/proto/gen"
 	"context"
-	"fmt"
 
 	"google.golang.org/grpc"
 )
@@ -26,37 +25,199 @@ func (s *FeedService) CreatePost(ctx context.Context, req *domain.CreatePostRequ
 
 	res, err := s.client.CreatePost(ctx, grpcReq)
 	if err != nil {
-		return nil, fmt.Errorf("Failed Create Post%w",err)
+		return nil, err
 	}
 
-	return &domain.PostResponse{
-		Content:   res.Post.Content,
-		ImageURL:  res.Post.ImageUrl,
-		CreatedAt: res.Post.CreatedAt,
-	}, nil
+	return mapPost(res.Post), nil
 
 }
 
-func (s *FeedService) GetAllPosts(ctx context.Context, page, pageSize int) ([]*domain.PostResponse, int, error) {
+func (s *FeedService) GetAllPosts(ctx context.Context, query domain.PostsQuery) (*domain.AllPostResponse, error) {
 	grpcReq := &gen.GetAllPostsRequest{
-		Page:     int32(page),
-		PageSize: int32(pageSize),
+		Cursor:       query.Cursor,
+		PageSize:     int32(query.PageSize),
+		IncludeTotal: query.IncludeTotal,
+		Page:         int32(query.Page),
 	}
 
 	res, err := s.client.GetAllPosts(ctx, grpcReq)
 	if err != nil {
-		return nil, 0, fmt.Errorf("Failed get all posts")
+		return nil, err
 	}
 
-	var posts []*domain.PostResponse
+	posts := make([]*domain.PostResponse, 0, len(res.Posts))
 	for _, p := range res.Posts {
-		posts = append(posts, &domain.PostResponse{
+		posts = append(posts, mapPost(p))
+	}
+
+	return &domain.AllPostResponse{
+		Posts:      posts,
+		TotalPosts: int(res.TotalPosts),
+		NextCursor: res.NextCursor,
+	}, nil
+}
+
+func (s *FeedService) GetPost(ctx context.Context, postID string) (*domain.PostResponse, error) {
+	res, err := s.client.GetPost(ctx, &gen.GetPostRequest{Id: postID})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapPost(res.Post), nil
+}
+
+func (s *FeedService) UpdatePost(ctx context.Context, postID string, req *domain.UpdatePostRequest) (*domain.PostResponse, error) {
+	grpcReq := &gen.UpdatePostRequest{
+		Id:       postID,
+		Content:  req.Content,
+		ImageUrl: req.ImageURL,
+	}
+
+	res, err := s.client.UpdatePost(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapPost(res.Post), nil
+}
+
+func (s *FeedService) DeletePost(ctx context.Context, postID string) (bool, error) {
+	res, err := s.client.DeletePost(ctx, &gen.DeletePostRequest{Id: postID})
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *FeedService) LikePost(ctx context.Context, postID string) (*domain.PostResponse, error) {
+	res, err := s.client.LikePost(ctx, &gen.LikePostRequest{PostId: postID})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapPost(res.Post), nil
+}
+
+func (s *FeedService) UnlikePost(ctx context.Context, postID string) (*domain.PostResponse, error) {
+	res, err := s.client.UnlikePost(ctx, &gen.LikePostRequest{PostId: postID})
+	if err != nil {
+		return nil, err
+	}
 
-			Content:   p.Content,
-			ImageURL:  p.ImageUrl,
-			CreatedAt: p.CreatedAt,
-		})
+	return mapPost(res.Post), nil
+}
+
+func (s *FeedService) AddReaction(ctx context.Context, postID, reaction string) (*domain.PostResponse, error) {
+	res, err := s.client.AddReaction(ctx, &gen.ReactionRequest{PostId: postID, Reaction: reaction})
+	if err != nil {
+		return nil, err
 	}
 
-	return posts, int(res.TotalPosts), nil
+	return mapPost(res.Post), nil
+}
+
+func (s *FeedService) RemoveReaction(ctx context.Context, postID, reaction string) (*domain.PostResponse, error) {
+	res, err := s.client.RemoveReaction(ctx, &gen.ReactionRequest{PostId: postID, Reaction: reaction})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapPost(res.Post), nil
+}
+
+func (s *FeedService) Follow(ctx context.Context, userID string) (bool, error) {
+	res, err := s.client.Follow(ctx, &gen.FollowRequest{UserId: userID})
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *FeedService) Unfollow(ctx context.Context, userID string) (bool, error) {
+	res, err := s.client.Unfollow(ctx, &gen.UnfollowRequest{UserId: userID})
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *FeedService) ListFollowers(ctx context.Context, userID string, page, pageSize int) (*domain.FollowsResponse, error) {
+	res, err := s.client.ListFollowers(ctx, &gen.ListFollowsRequest{UserId: userID, Page: int32(page), PageSize: int32(pageSize)})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapFollows(res), nil
+}
+
+func (s *FeedService) ListFollowing(ctx context.Context, userID string, page, pageSize int) (*domain.FollowsResponse, error) {
+	res, err := s.client.ListFollowing(ctx, &gen.ListFollowsRequest{UserId: userID, Page: int32(page), PageSize: int32(pageSize)})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapFollows(res), nil
+}
+
+func mapFollows(res *gen.ListFollowsResponse) *domain.FollowsResponse {
+	follows := make([]*domain.Follow, 0, len(res.Follows))
+	for _, f := range res.Follows {
+		follows = append(follows, &domain.Follow{UserID: f.UserId, FollowedAt: f.FollowedAt})
+	}
+
+	return &domain.FollowsResponse{Follows: follows, Total: int(res.Total)}
+}
+
+// GetHomeTimeline returns a page of the posts of the users the user whose token
+// is in ctx follows.
+func (s *FeedService) GetHomeTimeline(ctx context.Context, cursor string, pageSize int) (*domain.TimelineResponse, error) {
+	res, err := s.client.GetHomeTimeline(ctx, &gen.GetHomeTimelineRequest{Cursor: cursor, PageSize: int32(pageSize)})
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]*domain.PostResponse, 0, len(res.Posts))
+	for _, p := range res.Posts {
+		posts = append(posts, mapPost(p))
+	}
+
+	return &domain.TimelineResponse{Posts: posts, NextCursor: res.NextCursor}, nil
+}
+
+// ExportMyPosts returns every post of the user whose token is in ctx.
+func (s *FeedService) ExportMyPosts(ctx context.Context) ([]*domain.PostResponse, error) {
+	res, err := s.client.ExportMyPosts(ctx, &gen.ExportMyPostsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]*domain.PostResponse, 0, len(res.Posts))
+	for _, p := range res.Posts {
+		posts = append(posts, mapPost(p))
+	}
+
+	return posts, nil
+}
+
+func mapPost(post *gen.Post) *domain.PostResponse {
+	reactionCounts := make(map[string]int, len(post.ReactionCounts))
+	for reaction, count := range post.ReactionCounts {
+		reactionCounts[reaction] = int(count)
+	}
+
+	return &domain.PostResponse{
+		ID:             post.Id,
+		UserID:         post.UserId,
+		Content:        post.Content,
+		ImageURL:       post.ImageUrl,
+		CreatedAt:      post.CreatedAt,
+		UpdatedAt:      post.UpdatedAt,
+		DeletedAt:      post.DeletedAt,
+		ReactionCounts: reactionCounts,
+		MyReactions:    post.MyReactions,
+		LikedByMe:      post.LikedByMe,
+	}
 }
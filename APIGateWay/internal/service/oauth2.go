@@ -0,0 +1,146 @@
+package service
+
+import (
+	"Project/APIGateWay/internal/domain"
+	"Project/proto/gen"
+	"context"
+)
+
+func (s *AuthService) RegisterOAuthClient(ctx context.Context, accessToken string, req *domain.RegisterOAuthClientRequest) (*domain.RegisterOAuthClientResponse, error) {
+	grpcReq := &gen.RegisterOAuthClientRequest{
+		AccessToken:  accessToken,
+		Name:         req.Name,
+		RedirectUris: req.RedirectURIs,
+		Scopes:       req.Scopes,
+		GrantTypes:   req.GrantTypes,
+		Confidential: req.Confidential,
+	}
+
+	res, err := s.client.RegisterOAuthClient(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.RegisterOAuthClientResponse{
+		Client:       mapOAuthClient(res.Client),
+		ClientSecret: res.ClientSecret,
+	}, nil
+}
+
+func (s *AuthService) AuthorizeOAuthClient(ctx context.Context, accessToken string, req *domain.AuthorizeOAuthClientRequest) (*domain.AuthorizeOAuthClientResponse, error) {
+	grpcReq := &gen.AuthorizeOAuthClientRequest{
+		AccessToken:         accessToken,
+		ClientId:            req.ClientID,
+		RedirectUri:         req.RedirectURI,
+		Scope:               req.Scope,
+		State:               req.State,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Approve:             req.Approve,
+	}
+
+	res, err := s.client.AuthorizeOAuthClient(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthorizeOAuthClientResponse{
+		ConsentRequired: res.ConsentRequired,
+		Client:          mapOAuthClient(res.Client),
+		Scopes:          res.Scopes,
+		RedirectTo:      res.RedirectTo,
+	}, nil
+}
+
+func (s *AuthService) OAuthToken(ctx context.Context, client domain.OAuthClientCredentials, req *domain.OAuthTokenRequest) (*domain.OAuthTokenResponse, error) {
+	grpcReq := &gen.OAuthTokenRequest{
+		Client:       mapOAuthClientCredentials(client),
+		GrantType:    req.GrantType,
+		Code:         req.Code,
+		RedirectUri:  req.RedirectURI,
+		CodeVerifier: req.CodeVerifier,
+		RefreshToken: req.RefreshToken,
+		Scope:        req.Scope,
+	}
+
+	res, err := s.client.OAuthToken(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.OAuthTokenResponse{
+		AccessToken:  res.AccessToken,
+		TokenType:    res.TokenType,
+		ExpiresIn:    res.ExpiresIn,
+		RefreshToken: res.RefreshToken,
+		Scope:        res.Scope,
+	}, nil
+}
+
+func (s *AuthService) IntrospectOAuthToken(ctx context.Context, client domain.OAuthClientCredentials, req *domain.OAuthTokenHandleRequest) (*domain.OAuthIntrospectionResponse, error) {
+	grpcReq := &gen.IntrospectOAuthTokenRequest{
+		Client:        mapOAuthClientCredentials(client),
+		Token:         req.Token,
+		TokenTypeHint: req.TokenTypeHint,
+	}
+
+	res, err := s.client.IntrospectOAuthToken(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.OAuthIntrospectionResponse{
+		Active:    res.Active,
+		Scope:     res.Scope,
+		ClientID:  res.ClientId,
+		Sub:       res.Sub,
+		Exp:       res.Exp,
+		Iat:       res.Iat,
+		TokenType: res.TokenType,
+		Iss:       res.Iss,
+		Jti:       res.Jti,
+	}, nil
+}
+
+func (s *AuthService) RevokeOAuthToken(ctx context.Context, client domain.OAuthClientCredentials, req *domain.OAuthTokenHandleRequest) error {
+	grpcReq := &gen.RevokeOAuthTokenRequest{
+		Client:        mapOAuthClientCredentials(client),
+		Token:         req.Token,
+		TokenTypeHint: req.TokenTypeHint,
+	}
+
+	_, err := s.client.RevokeOAuthToken(ctx, grpcReq)
+	return err
+}
+
+func (s *AuthService) RevokeOAuthConsent(ctx context.Context, accessToken, clientID string) (bool, error) {
+	grpcReq := &gen.RevokeOAuthConsentRequest{
+		AccessToken: accessToken,
+		ClientId:    clientID,
+	}
+
+	res, err := s.client.RevokeOAuthConsent(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func mapOAuthClient(client *gen.OAuthClient) domain.OAuthClient {
+	return domain.OAuthClient{
+		ClientID:     client.GetClientId(),
+		Name:         client.GetName(),
+		RedirectURIs: client.GetRedirectUris(),
+		Scopes:       client.GetScopes(),
+		GrantTypes:   client.GetGrantTypes(),
+		Confidential: client.GetConfidential(),
+	}
+}
+
+func mapOAuthClientCredentials(client domain.OAuthClientCredentials) *gen.OAuthClientCredentials {
+	return &gen.OAuthClientCredentials{
+		ClientId:     client.ClientID,
+		ClientSecret: client.ClientSecret,
+	}
+}
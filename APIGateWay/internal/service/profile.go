@@ -0,0 +1,72 @@
+package service
+
+import (
+	"Project/APIGateWay/internal/domain"
+	"Project/proto/gen"
+	"context"
+)
+
+func (s *AuthService) UpdateProfile(ctx context.Context, accessToken string, req *domain.UpdateProfileRequest) (*domain.Profile, error) {
+	grpcReq := &gen.UpdateProfileRequest{
+		AccessToken: accessToken,
+		Username:    req.Username,
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+		AvatarUrl:   req.AvatarURL,
+	}
+
+	res, err := s.client.UpdateProfile(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapProfile(res.Profile), nil
+}
+
+func (s *AuthService) GetProfile(ctx context.Context, userID string) (*domain.Profile, error) {
+	res, err := s.client.GetProfile(ctx, &gen.GetProfileRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapProfile(res.Profile), nil
+}
+
+func (s *AuthService) GetProfileByUsername(ctx context.Context, username string) (*domain.Profile, error) {
+	res, err := s.client.GetProfile(ctx, &gen.GetProfileRequest{Username: username})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapProfile(res.Profile), nil
+}
+
+// GetProfilesBatch returns the profiles by user ID. Unknown users are missing
+// from the map.
+func (s *AuthService) GetProfilesBatch(ctx context.Context, userIDs []string) (map[string]*domain.Profile, error) {
+	res, err := s.client.GetProfilesBatch(ctx, &gen.GetProfilesBatchRequest{UserIds: userIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]*domain.Profile, len(res.Profiles))
+	for _, profile := range res.Profiles {
+		profiles[profile.UserId] = mapProfile(profile)
+	}
+
+	return profiles, nil
+}
+
+func mapProfile(profile *gen.Profile) *domain.Profile {
+	if profile == nil {
+		return nil
+	}
+
+	return &domain.Profile{
+		UserID:      profile.UserId,
+		Username:    profile.Username,
+		DisplayName: profile.DisplayName,
+		Bio:         profile.Bio,
+		AvatarURL:   profile.AvatarUrl,
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"Project/APIGateWay/internal/domain"
+	"Project/proto/gen"
+	"context"
+)
+
+func (s *AuthService) AssignRole(ctx context.Context, accessToken, userID, role string) (bool, error) {
+	grpcReq := &gen.AssignRoleRequest{
+		AccessToken: accessToken,
+		UserId:      userID,
+		Role:        role,
+	}
+
+	res, err := s.client.AssignRole(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) RevokeRole(ctx context.Context, accessToken, userID, role string) (bool, error) {
+	grpcReq := &gen.RevokeRoleRequest{
+		AccessToken: accessToken,
+		UserId:      userID,
+		Role:        role,
+	}
+
+	res, err := s.client.RevokeRole(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func (s *AuthService) ListUserRoles(ctx context.Context, accessToken, userID string) ([]*domain.UserRole, error) {
+	grpcReq := &gen.ListUserRolesRequest{
+		AccessToken: accessToken,
+		UserId:      userID,
+	}
+
+	res, err := s.client.ListUserRoles(ctx, grpcReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapUserRoles(res.Roles), nil
+}
+
+func mapUserRoles(grpcRoles []*gen.UserRole) []*domain.UserRole {
+	roles := make([]*domain.UserRole, 0, len(grpcRoles))
+	for _, role := range grpcRoles {
+		roles = append(roles, &domain.UserRole{
+			Role:        role.Role,
+			Permissions: role.Permissions,
+			GrantedBy:   role.GrantedBy,
+			GrantedAt:   role.GrantedAt,
+		})
+	}
+
+	return roles
+}
+
+func (s *AuthService) RevokeAccessToken(ctx context.Context, accessToken, jti string) (bool, error) {
+	grpcReq := &gen.RevokeAccessTokenRequest{
+		AccessToken: accessToken,
+		Jti:         jti,
+	}
+
+	res, err := s.client.RevokeAccessToken(ctx, grpcReq)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
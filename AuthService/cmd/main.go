@@ -22,7 +22,7 @@ func main() {
 
 	log, err := logger.New()
 	if err != nil {
-		fmt.Errorf("Failed load logger %w", err)
+		fmt.Printf("Failed load logger: %v\n", err)
 		return
 	}
 
@@ -8,9 +8,17 @@ import (
 	"Project/AuthService/internal/service"
 	"Project/AuthService/internal/storage/postgres"
 	"Project/AuthService/internal/transport/handlers"
+	"Project/AuthService/internal/transport/interceptors"
 	"Project/AuthService/internal/transport/server"
+	"Project/AuthService/internal/validation"
 	"Project/AuthService/pkg/database"
+	"Project/AuthService/pkg/encryption"
+	"Project/AuthService/pkg/jwt"
+	"Project/AuthService/pkg/password"
+	"Project/pkg/revocation"
+	"Project/proto/gen"
 
+	"context"
 	"fmt"
 )
 
@@ -29,32 +37,182 @@ func New(log *logger.Logger, cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("Failed to init user repositories:%w", err)
 	}
 
+	sessionStorage, err := postgres.NewSessionStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to init session repositories:%w", err)
+	}
+
+	mfaStorage, err := postgres.NewMFAStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to init mfa repositories:%w", err)
+	}
+
+	identityStorage, err := postgres.NewIdentityStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to init identity repositories:%w", err)
+	}
+
+	oauthStorage, err := postgres.NewOAuthStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to init oauth repositories:%w", err)
+	}
+
+	roleStorage, err := postgres.NewRoleStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to init role repositories:%w", err)
+	}
+
+	profileStorage, err := postgres.NewProfileStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to init profile repositories:%w", err)
+	}
+
+	authEventStorage, err := postgres.NewAuthEventStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to init auth event repositories:%w", err)
+	}
+
 	kafkaProducer := kafka.NewProducer([]string{cfg.Kafka.Broker},
 		cfg.Kafka.Topic,
 		log,
 	)
 
+	userEventsProducer := kafka.NewProducer([]string{cfg.Kafka.Broker},
+		cfg.Kafka.UserDeletedTopic,
+		log,
+	)
+
+	authEventsProducer := kafka.NewProducer([]string{cfg.Kafka.Broker},
+		cfg.Kafka.AuthEventsTopic,
+		log,
+	)
+
 	redisClient := redis.NewClient(cfg.Redis.Addr)
 	if redisClient == nil {
 		return nil, fmt.Errorf("failed to connect to Redis")
 	}
 
-	authService := service.NewAuthenticationService(
+	keyring, err := jwt.LoadKeyring(cfg.Auth.KeysDir, cfg.Auth.SigningKeyID, cfg.Auth.GenerateMissingKey)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load signing keys:%w", err)
+	}
+
+	secretCipher, err := encryption.NewCipher(cfg.Auth.MFAEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to init mfa encryption:%w", err)
+	}
+
+	unconfirmedLoginPolicy, err := service.ParseUnconfirmedLoginPolicy(cfg.Auth.UnconfirmedLoginPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid auth config:%w", err)
+	}
+
+	if cfg.Auth.OAuthAudience == "" || cfg.Auth.OAuthAudience == cfg.Auth.Audience {
+		return nil, fmt.Errorf("Invalid auth config: oauth_audience is required and has to differ from audience")
+	}
+
+	if cfg.Grpc.CallerSecret == "" {
+		return nil, fmt.Errorf("Invalid grpc config: caller_secret is required")
+	}
+
+	if cfg.LoginProtection.FailureWindow <= 0 || cfg.LoginProtection.BaseLockout <= 0 {
+		return nil, fmt.Errorf("Invalid login protection config: failure_window and base_lockout have to be positive")
+	}
+
+	if cfg.Auth.MagicLinkEnabled && cfg.Auth.MagicLinkURL == "" {
+		return nil, fmt.Errorf("Invalid auth config: magic_link_url is required when magic links are enabled")
+	}
+
+	var breachedPasswords *validation.BreachedPasswords
+	if cfg.PasswordPolicy.BreachedPasswordsDir != "" {
+		breachedPasswords, err = validation.NewBreachedPasswords(cfg.PasswordPolicy.BreachedPasswordsDir)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid password policy config:%w", err)
+		}
+	}
+
+	passwordValidator := validation.NewPasswordValidator(validation.PasswordPolicy{
+		MinLength:     cfg.PasswordPolicy.MinLength,
+		MaxLength:     cfg.PasswordPolicy.MaxLength,
+		RequireUpper:  cfg.PasswordPolicy.RequireUpper,
+		RequireLower:  cfg.PasswordPolicy.RequireLower,
+		RequireDigit:  cfg.PasswordPolicy.RequireDigit,
+		RequireSymbol: cfg.PasswordPolicy.RequireSymbol,
+		MinStrength:   cfg.PasswordPolicy.MinStrength,
+	}, breachedPasswords)
+
+	passwordHasher, err := password.NewHasherByName(cfg.Auth.PasswordHashAlgorithm, cfg.Auth.BcryptCost, password.Argon2Params{
+		Time:    cfg.Auth.Argon2Time,
+		Memory:  cfg.Auth.Argon2Memory,
+		Threads: cfg.Auth.Argon2Threads,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Invalid auth config:%w", err)
+	}
+
+	authService, err := service.NewAuthenticationService(
 		userStorage,
-		cfg.Auth.SecretKey,
+		sessionStorage,
+		mfaStorage,
+		identityStorage,
+		oauthStorage,
+		roleStorage,
+		profileStorage,
+		authEventStorage,
+		keyring,
+		secretCipher,
+		cfg.Auth.Issuer,
+		cfg.Auth.Audience,
+		cfg.Auth.OAuthAudience,
 		cfg.Auth.AccessTokenTTL,
 		cfg.Auth.RefreshTokenTTL,
 		log,
 		kafkaProducer,
+		userEventsProducer,
+		authEventsProducer,
 		redisClient,
+		revocation.NewList(cfg.Redis.Addr),
+		service.EmailConfirmationSettings{
+			UnconfirmedLoginPolicy:      unconfirmedLoginPolicy,
+			UnconfirmedLoginGracePeriod: cfg.Auth.UnconfirmedLoginGracePeriod,
+			ResendCooldown:              cfg.Auth.ConfirmationResendCooldown,
+		},
+		service.LoginProtectionSettings{
+			MaxAccountFailures: cfg.LoginProtection.MaxAccountFailures,
+			MaxIPFailures:      cfg.LoginProtection.MaxIPFailures,
+			FailureWindow:      cfg.LoginProtection.FailureWindow,
+			BaseLockout:        cfg.LoginProtection.BaseLockout,
+			MaxLockout:         cfg.LoginProtection.MaxLockout,
+		},
+		service.MagicLinkSettings{
+			Enabled: cfg.Auth.MagicLinkEnabled,
+			TTL:     cfg.Auth.MagicLinkTTL,
+			URL:     cfg.Auth.MagicLinkURL,
+		},
+		passwordValidator,
+		passwordHasher,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to init auth service:%w", err)
+	}
+
+	if err := authService.BootstrapAdmins(context.Background(), cfg.Auth.BootstrapAdmins); err != nil {
+		return nil, fmt.Errorf("Failed to bootstrap admins:%w", err)
+	}
 
 	authHandlers := handlers.NewAuthHandlers(authService, log.Logger)
 
+	callerInterceptor := interceptors.NewCallerInterceptor(
+		cfg.Grpc.CallerSecret,
+		[]string{gen.Authentication_GetJWKS_FullMethodName},
+		log.Logger,
+	)
+
 	grpcServer := server.NewGRPCServer(
 		log.Logger,
 		cfg.Grpc.Port,
 		authHandlers,
+		callerInterceptor,
 	)
 
 	return &App{
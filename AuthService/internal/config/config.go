@@ -15,12 +15,72 @@ type Config struct {
 	Grpc     *GRPCConfig     `mapstructure:"grpc"`
 	Kafka    *KafkaConfig    `mapstructure:"kafka"`
 	Redis    *RedisConfig    `mapstructure:"redis"`
+
+	LoginProtection *LoginProtectionConfig `mapstructure:"login_protection"`
+	PasswordPolicy  *PasswordPolicyConfig  `mapstructure:"password_policy"`
 }
 
 type AuthConfig struct {
-	SecretKey       string        `mapstructure:"secret_key"`
-	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl"`
-	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
+	KeysDir            string        `mapstructure:"keys_dir"`
+	SigningKeyID       string        `mapstructure:"signing_key_id"`
+	GenerateMissingKey bool          `mapstructure:"generate_missing_key"`
+	Issuer             string        `mapstructure:"issuer"`
+	Audience           string        `mapstructure:"audience"`
+	AccessTokenTTL     time.Duration `mapstructure:"access_token_ttl"`
+	RefreshTokenTTL    time.Duration `mapstructure:"refresh_token_ttl"`
+
+	// OAuthAudience is the audience of access tokens issued to OAuth clients.
+	// It differs from Audience so first-party APIs never take them by mistake.
+	OAuthAudience string `mapstructure:"oauth_audience"`
+
+	// UnconfirmedLoginPolicy is one of "allow", "block" or "grace".
+	UnconfirmedLoginPolicy      string        `mapstructure:"unconfirmed_login_policy"`
+	UnconfirmedLoginGracePeriod time.Duration `mapstructure:"unconfirmed_login_grace_period"`
+	ConfirmationResendCooldown  time.Duration `mapstructure:"confirmation_resend_cooldown"`
+
+	// MagicLinkURL is the frontend page emailed login links point to, see
+	// service.MagicLinkSettings.
+	MagicLinkEnabled bool          `mapstructure:"magic_link_enabled"`
+	MagicLinkTTL     time.Duration `mapstructure:"magic_link_ttl"`
+	MagicLinkURL     string        `mapstructure:"magic_link_url"`
+
+	// MFAEncryptionKey is the base64 encoded AES-256 key TOTP secrets are
+	// encrypted with.
+	MFAEncryptionKey string `mapstructure:"mfa_encryption_key"`
+
+	// PasswordHashAlgorithm is "argon2id" or "bcrypt". Hashes of the other
+	// algorithm are still accepted and replaced on the next login.
+	PasswordHashAlgorithm string `mapstructure:"password_hash_algorithm"`
+	BcryptCost            int    `mapstructure:"bcrypt_cost"`
+	Argon2Time            uint32 `mapstructure:"argon2_time"`
+	// Argon2Memory is in KiB.
+	Argon2Memory  uint32 `mapstructure:"argon2_memory"`
+	Argon2Threads uint8  `mapstructure:"argon2_threads"`
+
+	// BootstrapAdmins are emails of accounts that get the admin role at startup.
+	BootstrapAdmins []string `mapstructure:"bootstrap_admins"`
+}
+
+type LoginProtectionConfig struct {
+	MaxAccountFailures int64         `mapstructure:"max_account_failures"`
+	MaxIPFailures      int64         `mapstructure:"max_ip_failures"`
+	FailureWindow      time.Duration `mapstructure:"failure_window"`
+	BaseLockout        time.Duration `mapstructure:"base_lockout"`
+	MaxLockout         time.Duration `mapstructure:"max_lockout"`
+}
+
+type PasswordPolicyConfig struct {
+	MinLength     int  `mapstructure:"min_length"`
+	MaxLength     int  `mapstructure:"max_length"`
+	RequireUpper  bool `mapstructure:"require_upper"`
+	RequireLower  bool `mapstructure:"require_lower"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	RequireSymbol bool `mapstructure:"require_symbol"`
+	MinStrength   int  `mapstructure:"min_strength"`
+
+	// BreachedPasswordsDir holds the SHA-1 prefix range files of a breached
+	// password corpus. The check is skipped when it is empty.
+	BreachedPasswordsDir string `mapstructure:"breached_passwords_dir"`
 }
 
 type PostgresConfig struct {
@@ -29,11 +89,16 @@ type PostgresConfig struct {
 
 type GRPCConfig struct {
 	Port int `mapstructure:"port"`
+	// CallerSecret is shared with the gateway, the only caller allowed to use
+	// the RPCs other than GetJWKS.
+	CallerSecret string `mapstructure:"caller_secret"`
 }
 
 type KafkaConfig struct {
-	Broker string `mapstructure:"broker"`
-	Topic  string `mapstructure:"topic"`
+	Broker           string `mapstructure:"broker"`
+	Topic            string `mapstructure:"topic"`
+	UserDeletedTopic string `mapstructure:"user_deleted_topic"`
+	AuthEventsTopic  string `mapstructure:"auth_events_topic"`
 }
 
 type RedisConfig struct {
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrUnauthenticated means the access token is invalid, expired or belongs to
+	// a revoked session.
+	ErrUnauthenticated  = errors.New("invalid or expired access token")
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrImpersonationNotAllowed is returned when an impersonation token is used
+	// for something only the user themselves may do, such as changing credentials.
+	ErrImpersonationNotAllowed = errors.New("not allowed while impersonating a user")
+
+	// ErrInvalidCredentials is returned for both an unknown email and a wrong
+	// password so logins cannot be used to find out which accounts exist.
+	ErrInvalidCredentials   = errors.New("invalid email or password")
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+	ErrAccountSuspended     = errors.New("account is suspended")
+
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenExpired  = errors.New("refresh token expired")
+	ErrRefreshTokenReused   = errors.New("refresh token reused")
+
+	ErrPasswordResetTokenInvalid = errors.New("password reset token is invalid")
+	ErrPasswordResetTokenExpired = errors.New("password reset token expired")
+
+	ErrMagicLinkDisabled = errors.New("magic link login is disabled")
+	ErrMagicLinkInvalid  = errors.New("magic link is invalid")
+	ErrMagicLinkExpired  = errors.New("magic link expired")
+	ErrMagicLinkCooldown = errors.New("a magic link was sent recently")
+
+	ErrEmailAlreadyExists     = errors.New("email already exists")
+	ErrEmailChangeCodeInvalid = errors.New("email change code is invalid")
+	ErrEmailChangeCodeExpired = errors.New("email change code expired")
+
+	ErrInvalidConfirmationCode    = errors.New("invalid confirmation code")
+	ErrEmailNotConfirmed          = errors.New("email is not confirmed")
+	ErrConfirmationResendCooldown = errors.New("confirmation code was sent recently")
+
+	ErrMFANotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrInvalidMFACode      = errors.New("invalid two-factor authentication code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa token")
+
+	ErrIdentityNotFound         = errors.New("identity not found")
+	ErrIdentityAlreadyLinked    = errors.New("another account of this provider is already linked")
+	ErrExternalEmailNotVerified = errors.New("email is not verified by the identity provider")
+
+	ErrRoleNotFound        = errors.New("role not found")
+	ErrRoleAlreadyAssigned = errors.New("role is already assigned to the user")
+	ErrRoleNotAssigned     = errors.New("role is not assigned to the user")
+
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrUsernameTaken   = errors.New("username is already taken")
+
+	ErrOAuthClientNotFound  = errors.New("oauth client not found")
+	ErrOAuthCodeInvalid     = errors.New("authorization code is invalid or expired")
+	ErrOAuthCodeReused      = errors.New("authorization code was already used")
+	ErrOAuthConsentNotFound = errors.New("consent not found")
+	ErrOAuthGrantNotFound   = errors.New("oauth grant not found")
+)
+
+// OAuth error codes of RFC 6749 section 5.2 and 4.1.2.1.
+const (
+	OAuthInvalidRequest       = "invalid_request"
+	OAuthInvalidClient        = "invalid_client"
+	OAuthInvalidGrant         = "invalid_grant"
+	OAuthUnauthorizedClient   = "unauthorized_client"
+	OAuthUnsupportedGrantType = "unsupported_grant_type"
+	OAuthInvalidScope         = "invalid_scope"
+)
+
+// OAuthError is returned by the OAuth endpoints, which report errors in the
+// format of RFC 6749 instead of field violations.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func NewOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// ValidationError reports invalid request fields. The transport layer turns it
+// into codes.InvalidArgument with the violations attached as details.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func NewValidationError(field, description string) *ValidationError {
+	return &ValidationError{Violations: []FieldViolation{{Field: field, Description: description}}}
+}
+
+func (e *ValidationError) Add(field, description string) {
+	e.Violations = append(e.Violations, FieldViolation{Field: field, Description: description})
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+
+	return "validation failed: " + strings.Join(parts, "; ")
+}
@@ -1,7 +1,54 @@
 package message
 
+import "time"
+
+const (
+	EventRefreshTokenReuse = "refresh_token_reuse"
+	EventPasswordChanged   = "password_changed"
+	EventEmailChanged      = "email_changed"
+	EventMFAEnabled        = "mfa_enabled"
+	EventRecoveryCodeUsed  = "mfa_recovery_code_used"
+	EventAccountLocked     = "account_locked"
+	EventAccountDeleted    = "account_deleted"
+	EventAccountSuspended  = "account_suspended"
+)
+
 type ConfirmationMessage struct {
-	ToEmail string 
-	Subject string 
-	Body    string 
+	ToEmail string `json:"to_email"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// SecurityEventMessage is delivered as an email by NotificationService; the
+// event fields let other consumers of the topic tell security events apart.
+type SecurityEventMessage struct {
+	ToEmail    string    `json:"to_email"`
+	Subject    string    `json:"subject"`
+	Body       string    `json:"body"`
+	Event      string    `json:"event"`
+	UserID     string    `json:"user_id"`
+	SessionID  string    `json:"session_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// AuthEventMessage is published for every authentication event so security
+// tooling can follow logins and credential changes.
+type AuthEventMessage struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id,omitempty"`
+	Email      string    `json:"email,omitempty"`
+	Event      string    `json:"event"`
+	Outcome    string    `json:"outcome"`
+	Reason     string    `json:"reason,omitempty"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	SessionID  string    `json:"session_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// UserDeletedMessage is published when an account is deleted so the other
+// services can remove the data they keep about the user.
+type UserDeletedMessage struct {
+	UserID    string    `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
 }
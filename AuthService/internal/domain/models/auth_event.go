@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Authentication events recorded in the audit log.
+const (
+	AuthEventRegister       = "register"
+	AuthEventLogin          = "login"
+	AuthEventLoginMFA       = "login_mfa"
+	AuthEventLoginExternal  = "login_external"
+	AuthEventLoginMagicLink = "login_magic_link"
+	AuthEventLogout         = "logout"
+	AuthEventRefresh        = "refresh"
+	AuthEventConfirmEmail   = "confirm_email"
+	AuthEventPasswordChange = "password_change"
+	AuthEventPasswordReset  = "password_reset"
+
+	AuthOutcomeSuccess     = "success"
+	AuthOutcomeFailure     = "failure"
+	AuthOutcomeMFARequired = "mfa_required"
+)
+
+// AuthEvent is an entry of the audit log. UserID is uuid.Nil when the event
+// cannot be attributed to an account, e.g. a login with an unknown email.
+type AuthEvent struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Email     string
+	Event     string
+	Outcome   string
+	Reason    string
+	IP        string
+	UserAgent string
+	SessionID uuid.UUID
+	CreatedAt time.Time
+}
+
+// AuthEventFilter narrows the audit log; empty fields are not filtered on.
+type AuthEventFilter struct {
+	UserID  uuid.UUID
+	Email   string
+	Event   string
+	Outcome string
+	From    *time.Time
+	To      *time.Time
+}
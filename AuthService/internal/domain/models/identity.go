@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links an account of an external provider, e.g. Google, to a user.
+// Subject is the stable ID of the account at the provider.
+type Identity struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
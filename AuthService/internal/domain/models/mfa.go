@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MFA struct {
+	UserID          uuid.UUID
+	SecretEncrypted string
+	EnabledAt       *time.Time
+	LastUsedStep    int64
+}
+
+// LoginResult is the outcome of a login step. When MFARequired is set the
+// tokens are empty and MFAToken has to be exchanged with a second factor.
+type LoginResult struct {
+	User         *User
+	AccessToken  string
+	RefreshToken string
+	SessionID    uuid.UUID
+	MFARequired  bool
+	MFAToken     string
+}
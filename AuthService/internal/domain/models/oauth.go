@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthClient is a third-party application registered to act on behalf of
+// users. Public clients have no secret and must use PKCE.
+type OAuthClient struct {
+	ID           string
+	Name         string
+	SecretHash   string
+	RedirectURIs []string
+	Scopes       []string
+	GrantTypes   []string
+	OwnerUserID  *uuid.UUID
+	CreatedAt    time.Time
+}
+
+func (c *OAuthClient) Confidential() bool {
+	return c.SecretHash != ""
+}
+
+type OAuthAuthorizationCode struct {
+	CodeHash      string
+	ClientID      string
+	UserID        uuid.UUID
+	RedirectURI   string
+	Scopes        []string
+	CodeChallenge string
+	ExpiresAt     time.Time
+}
+
+type OAuthConsent struct {
+	UserID    uuid.UUID
+	ClientID  string
+	Scopes    []string
+	GrantedAt time.Time
+}
+
+// OAuthGrant stands for the tokens issued to a client. UserID is nil for the
+// client credentials grant.
+type OAuthGrant struct {
+	ID                    uuid.UUID
+	ClientID              string
+	UserID                *uuid.UUID
+	Scopes                []string
+	AuthorizationCodeHash string
+	RefreshTokenExpiresAt *time.Time
+	RevokedAt             *time.Time
+	CreatedAt             time.Time
+}
+
+// OAuthAuthorization is the outcome of an authorization request. While
+// ConsentRequired is set RedirectTo is empty and the user has to approve Scopes.
+type OAuthAuthorization struct {
+	ConsentRequired bool
+	Client          *OAuthClient
+	Scopes          []string
+	RedirectTo      string
+}
+
+type OAuthTokenResult struct {
+	AccessToken  string
+	ExpiresIn    time.Duration
+	RefreshToken string
+	Scopes       []string
+}
+
+// OAuthIntrospection is the RFC 7662 view of a token. Only Active is set for
+// tokens that are invalid, expired or revoked.
+type OAuthIntrospection struct {
+	Active    bool
+	ID        string
+	ClientID  string
+	Subject   string
+	Scopes    []string
+	Issuer    string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// OAuthAuthorizationRequest holds the parameters of an authorization request
+// (RFC 6749 section 4.1.1 with PKCE).
+type OAuthAuthorizationRequest struct {
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// OAuthTokenRequest holds the parameters of a token request for every
+// supported grant type.
+type OAuthTokenRequest struct {
+	ClientID     string
+	ClientSecret string
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
@@ -0,0 +1,13 @@
+package models
+
+import "github.com/google/uuid"
+
+// Profile is the public part of a user. Users who never set one up have an
+// empty profile without a username.
+type Profile struct {
+	UserID      uuid.UUID
+	Username    string
+	DisplayName string
+	Bio         string
+	AvatarURL   string
+}
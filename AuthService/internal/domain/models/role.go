@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserRole is a role assigned to a user. GrantedBy is nil for roles assigned
+// by configuration or by an admin whose account was deleted since.
+type UserRole struct {
+	Role        string
+	Permissions []string
+	GrantedBy   *uuid.UUID
+	GrantedAt   time.Time
+}
+
+// UserAccess are the roles of a user and the permissions they grant.
+type UserAccess struct {
+	Roles       []string
+	Permissions []string
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Session struct {
+	ID                    uuid.UUID
+	UserID                uuid.UUID
+	DeviceName            string
+	UserAgent             string
+	IP                    string
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshTokenExpiresAt time.Time
+	CreatedAt             time.Time
+	LastUsedAt            time.Time
+}
+
+type ClientInfo struct {
+	DeviceName string
+	UserAgent  string
+	IP         string
+}
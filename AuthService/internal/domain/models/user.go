@@ -1,10 +1,55 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
-	ID           uuid.UUID
-	Email        string
-	PasswordHash string
+	ID             uuid.UUID
+	Email          string
+	PasswordHash   string
+	EmailConfirmed bool
+	CreatedAt      time.Time
+	LockedUntil    *time.Time
+
+	SuspendedAt     *time.Time
+	SuspendedReason string
 }
 
+func (u *User) Suspended() bool {
+	return u.SuspendedAt != nil
+}
+
+// UserFilter narrows the users listed by admins. Query matches the email or
+// the username; nil fields are not filtered on.
+type UserFilter struct {
+	Query         string
+	Confirmed     *bool
+	Locked        *bool
+	Suspended     *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// Impersonation is the audit record of an admin acting as a user.
+type Impersonation struct {
+	ID        uuid.UUID
+	AdminID   uuid.UUID
+	UserID    uuid.UUID
+	SessionID uuid.UUID
+	Reason    string
+	ExpiresAt time.Time
+}
+
+// UserExport is everything AuthService stores about a user, returned by
+// ExportMyData.
+type UserExport struct {
+	User       *User
+	Access     *UserAccess
+	Profile    *Profile
+	Roles      []UserRole
+	Sessions   []Session
+	Identities []Identity
+}
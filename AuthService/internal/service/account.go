@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/message"
+	"Project/AuthService/internal/domain/models"
+	"Project/pkg/authz"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DeleteAccount deletes the account of the caller after asking for the
+// password once more. The sessions are revoked first so their access tokens
+// stop working everywhere, then the other services are told to remove their
+// data about the user through the user deleted event. Admins have to lose the
+// admin role first so that at least one admin is always left.
+func (s *AuthenticationService) DeleteAccount(ctx context.Context, accessToken, password string) error {
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userStorage.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if err := s.passwordHasher.Verify(password, user.PasswordHash); err != nil {
+		return domain.NewValidationError("password", "does not match the current password")
+	}
+
+	access, err := s.roleStorage.GetUserAccess(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	if slices.Contains(access.Roles, authz.RoleAdmin) {
+		return domain.NewValidationError("account", "the admin role must be revoked before the account can be deleted")
+	}
+
+	if _, err := s.revokeOtherSessions(ctx, user.ID, uuid.Nil); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	deletedAt, err := s.userStorage.DeleteUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	event := message.UserDeletedMessage{
+		UserID:    user.ID.String(),
+		DeletedAt: deletedAt.UTC(),
+	}
+	if err := s.userEvents.SendMessage(ctx, user.ID.String(), event); err != nil {
+		// The account is gone either way; the event has to be replayed by hand.
+		s.logger.Error("Failed to publish user deleted event", zap.String("userID", user.ID.String()), zap.Error(err))
+	}
+
+	s.sendSecurityEvent(ctx, user.Email, message.SecurityEventMessage{
+		Subject: "Your account was deleted",
+		Body:    "Your account and the data stored with it were deleted. If this was not you, contact support.",
+		Event:   message.EventAccountDeleted,
+		UserID:  user.ID.String(),
+	})
+
+	s.logger.Info("Account deleted", zap.String("userID", user.ID.String()))
+	return nil
+}
+
+// ExportMyData returns everything AuthService stores about the caller except
+// secrets such as the password hash and tokens.
+func (s *AuthenticationService) ExportMyData(ctx context.Context, accessToken string) (*models.UserExport, error) {
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userStorage.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	access, err := s.roleStorage.GetUserAccess(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	profile, err := s.profileStorage.GetProfile(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	roles, err := s.roleStorage.ListUserRoles(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+
+	sessions, err := s.sessionStorage.ListSessions(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	identities, err := s.identityStorage.ListIdentities(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	return &models.UserExport{
+		User:       user,
+		Access:     access,
+		Profile:    profile,
+		Roles:      roles,
+		Sessions:   sessions,
+		Identities: identities,
+	}, nil
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/message"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/pkg/jwt"
+	"Project/pkg/authz"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxUsersPageSize = 100
+	maxReasonLength  = 500
+)
+
+// ListUsers lets admins page through and search the users.
+func (s *AuthenticationService) ListUsers(ctx context.Context, accessToken string, filter models.UserFilter, page, pageSize int) ([]models.User, int, error) {
+	if _, err := s.authorize(ctx, accessToken, authz.PermissionManageUsers); err != nil {
+		return nil, 0, err
+	}
+
+	validationErr := &domain.ValidationError{}
+	if page < 1 {
+		validationErr.Add("page", "must be at least 1")
+	}
+	if pageSize < 1 || pageSize > maxUsersPageSize {
+		validationErr.Add("page_size", fmt.Sprintf("must be between 1 and %d", maxUsersPageSize))
+	}
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		validationErr.Add("created_before", "must be after created_after")
+	}
+	if len(validationErr.Violations) > 0 {
+		return nil, 0, validationErr
+	}
+
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	users, total, err := s.userStorage.ListUsers(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// SuspendUser blocks the user from logging in and signs them out everywhere
+// until an admin unsuspends them.
+func (s *AuthenticationService) SuspendUser(ctx context.Context, accessToken string, userID uuid.UUID, reason string) error {
+	admin, err := s.authorize(ctx, accessToken, authz.PermissionManageUsers)
+	if err != nil {
+		return err
+	}
+
+	reason, err = validateReason(reason)
+	if err != nil {
+		return err
+	}
+
+	if userID == admin.UserID {
+		return domain.NewValidationError("user_id", "admins cannot suspend themselves")
+	}
+
+	user, err := s.userStorage.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if err := s.userStorage.SuspendUser(ctx, user.ID, reason, admin.UserID); err != nil {
+		return fmt.Errorf("failed to suspend user: %w", err)
+	}
+
+	if _, err := s.revokeOtherSessions(ctx, user.ID, uuid.Nil); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.sendSecurityEvent(ctx, user.Email, message.SecurityEventMessage{
+		Subject: "Your account was suspended",
+		Body:    "Your account was suspended by an administrator: " + reason,
+		Event:   message.EventAccountSuspended,
+		UserID:  user.ID.String(),
+	})
+
+	s.logger.Info("User suspended", zap.String("userID", user.ID.String()), zap.String("adminID", admin.UserID.String()), zap.String("reason", reason))
+	return nil
+}
+
+func (s *AuthenticationService) UnsuspendUser(ctx context.Context, accessToken string, userID uuid.UUID) error {
+	admin, err := s.authorize(ctx, accessToken, authz.PermissionManageUsers)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userStorage.UnsuspendUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to unsuspend user: %w", err)
+	}
+
+	s.logger.Info("User unsuspended", zap.String("userID", userID.String()), zap.String("adminID", admin.UserID.String()))
+	return nil
+}
+
+// ForceLogout revokes every session of the user and returns how many there were.
+func (s *AuthenticationService) ForceLogout(ctx context.Context, accessToken string, userID uuid.UUID) (int, error) {
+	admin, err := s.authorize(ctx, accessToken, authz.PermissionManageUsers)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := s.userStorage.GetUserByID(ctx, userID); err != nil {
+		return 0, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	revoked, err := s.revokeOtherSessions(ctx, userID, uuid.Nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.logger.Info("User logged out by admin", zap.String("userID", userID.String()), zap.String("adminID", admin.UserID.String()), zap.Int("sessions", revoked))
+	return revoked, nil
+}
+
+// ImpersonateUser starts a session for the user on behalf of an admin and
+// returns its access token. The token names the admin in its "act" claim,
+// carries none of the roles of the user and cannot be refreshed. It cannot be
+// used to change the credentials of the user either. Every impersonation is
+// recorded together with its reason.
+func (s *AuthenticationService) ImpersonateUser(ctx context.Context, accessToken string, userID uuid.UUID, reason string, client models.ClientInfo) (string, time.Time, error) {
+	admin, err := s.authorize(ctx, accessToken, authz.PermissionImpersonateUsers)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	reason, err = validateReason(reason)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if userID == admin.UserID {
+		return "", time.Time{}, domain.NewValidationError("user_id", "admins cannot impersonate themselves")
+	}
+
+	user, err := s.userStorage.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if user.Suspended() {
+		return "", time.Time{}, domain.ErrAccountSuspended
+	}
+
+	access, err := s.roleStorage.GetUserAccess(ctx, user.ID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	if slices.Contains(access.Roles, authz.RoleAdmin) {
+		return "", time.Time{}, domain.NewValidationError("user_id", "admins cannot be impersonated")
+	}
+
+	session := &models.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		DeviceName: "Impersonation by " + admin.UserID.String(),
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+	}
+
+	claims := jwt.TokenClaims{
+		UserID:    user.ID,
+		SessionID: session.ID,
+		Email:     user.Email,
+		Issuer:    s.tokenIssuer,
+		Audience:  s.tokenAudience,
+		ActorID:   admin.UserID,
+	}
+
+	session.AccessToken, session.AccessTokenExpiresAt, err = jwt.GenerateToken(s.keyring, claims, s.AccessTokenTTL)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	impersonation := &models.Impersonation{
+		ID:        uuid.New(),
+		AdminID:   admin.UserID,
+		UserID:    user.ID,
+		SessionID: session.ID,
+		Reason:    reason,
+		ExpiresAt: session.AccessTokenExpiresAt,
+	}
+
+	if err := s.sessionStorage.CreateImpersonationSession(ctx, session, impersonation); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	ttl := time.Until(session.AccessTokenExpiresAt)
+	if err := s.redisClient.Set(ctx, session.ID.String(), session.AccessToken, ttl); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to save access token to Redis: %w", err)
+	}
+
+	s.logger.Warn("User impersonated",
+		zap.String("userID", user.ID.String()),
+		zap.String("adminID", admin.UserID.String()),
+		zap.String("impersonationID", impersonation.ID.String()),
+		zap.String("reason", reason),
+	)
+
+	return session.AccessToken, session.AccessTokenExpiresAt, nil
+}
+
+// authenticateAccountOwner is authenticate for operations only the user may
+// perform themselves, such as changing credentials, and rejects impersonation
+// tokens.
+func (s *AuthenticationService) authenticateAccountOwner(ctx context.Context, accessToken string) (*jwt.TokenClaims, error) {
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Impersonated() {
+		return nil, domain.ErrImpersonationNotAllowed
+	}
+
+	return claims, nil
+}
+
+func validateReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+
+	if reason == "" {
+		return "", domain.NewValidationError("reason", "is required")
+	}
+	if utf8.RuneCountInString(reason) > maxReasonLength {
+		return "", domain.NewValidationError("reason", fmt.Sprintf("must be at most %d characters", maxReasonLength))
+	}
+
+	return reason, nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/message"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/internal/validation"
+	"Project/pkg/authz"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const maxAuthEventsPageSize = 100
+
+type AuthEventStorage interface {
+	SaveAuthEvent(ctx context.Context, event *models.AuthEvent) error
+	ListAuthEvents(ctx context.Context, filter models.AuthEventFilter, page, pageSize int) ([]models.AuthEvent, int, error)
+}
+
+// authEventReasons are the errors whose message is safe to keep as the reason
+// of a failed event; anything else is recorded as an internal error.
+var authEventReasons = []error{
+	domain.ErrInvalidCredentials,
+	domain.ErrTooManyLoginAttempts,
+	domain.ErrAccountSuspended,
+	domain.ErrEmailNotConfirmed,
+	domain.ErrEmailAlreadyExists,
+	domain.ErrInvalidMFACode,
+	domain.ErrInvalidMFAChallenge,
+	domain.ErrExternalEmailNotVerified,
+	domain.ErrRefreshTokenNotFound,
+	domain.ErrRefreshTokenExpired,
+	domain.ErrRefreshTokenReused,
+	domain.ErrInvalidConfirmationCode,
+	domain.ErrPasswordResetTokenInvalid,
+	domain.ErrPasswordResetTokenExpired,
+	domain.ErrMagicLinkDisabled,
+	domain.ErrMagicLinkInvalid,
+	domain.ErrMagicLinkExpired,
+	domain.ErrUnauthenticated,
+	domain.ErrPermissionDenied,
+	domain.ErrImpersonationNotAllowed,
+	domain.ErrUserNotFound,
+}
+
+func newAuthEvent(event string, client models.ClientInfo) *models.AuthEvent {
+	return &models.AuthEvent{
+		ID:        uuid.New(),
+		Event:     event,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	}
+}
+
+// recordAuthEvent completes the event with the outcome of the operation, err
+// being its result, then appends it to the audit log and publishes it to the
+// auth events topic. Failures are only logged so the audit log never breaks a
+// login.
+func (s *AuthenticationService) recordAuthEvent(ctx context.Context, event *models.AuthEvent, err error) {
+	event.CreatedAt = time.Now()
+
+	switch {
+	case err != nil:
+		event.Outcome = models.AuthOutcomeFailure
+		event.Reason = authEventReason(err)
+	case event.Outcome == "":
+		event.Outcome = models.AuthOutcomeSuccess
+	}
+
+	if err := s.auditLog.SaveAuthEvent(ctx, event); err != nil {
+		s.logger.Error("Failed to save auth event", zap.String("event", event.Event), zap.Error(err))
+	}
+
+	msg := message.AuthEventMessage{
+		ID:         event.ID.String(),
+		Email:      event.Email,
+		Event:      event.Event,
+		Outcome:    event.Outcome,
+		Reason:     event.Reason,
+		IP:         event.IP,
+		UserAgent:  event.UserAgent,
+		OccurredAt: event.CreatedAt.UTC(),
+	}
+	key := event.Email
+	if event.UserID != uuid.Nil {
+		msg.UserID = event.UserID.String()
+		key = msg.UserID
+	}
+	if event.SessionID != uuid.Nil {
+		msg.SessionID = event.SessionID.String()
+	}
+
+	if err := s.authEvents.SendMessage(ctx, key, msg); err != nil {
+		s.logger.Error("Failed to send auth event to kafka", zap.String("event", event.Event), zap.Error(err))
+	}
+}
+
+// recordLoginEvent records a login, which ends either with a session or with
+// the second factor still missing.
+func (s *AuthenticationService) recordLoginEvent(ctx context.Context, event *models.AuthEvent, result *models.LoginResult, err error) {
+	if result != nil {
+		event.UserID = result.User.ID
+		event.SessionID = result.SessionID
+		if result.MFARequired {
+			event.Outcome = models.AuthOutcomeMFARequired
+		}
+	}
+
+	s.recordAuthEvent(ctx, event, err)
+}
+
+func authEventReason(err error) string {
+	for _, reason := range authEventReasons {
+		if errors.Is(err, reason) {
+			return reason.Error()
+		}
+	}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		return "validation failed"
+	}
+
+	return "internal error"
+}
+
+// ListMyAuthEvents returns the audit log of the caller, newest first.
+func (s *AuthenticationService) ListMyAuthEvents(ctx context.Context, accessToken string, from, to *time.Time, page, pageSize int) ([]models.AuthEvent, int, error) {
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.listAuthEvents(ctx, models.AuthEventFilter{UserID: claims.UserID, From: from, To: to}, page, pageSize)
+}
+
+// ListAuthEvents lets admins search the audit log of every user.
+func (s *AuthenticationService) ListAuthEvents(ctx context.Context, accessToken string, filter models.AuthEventFilter, page, pageSize int) ([]models.AuthEvent, int, error) {
+	if _, err := s.authorize(ctx, accessToken, authz.PermissionReadAuditLog); err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Email != "" {
+		filter.Email = validation.NormalizeEmail(filter.Email)
+	}
+
+	return s.listAuthEvents(ctx, filter, page, pageSize)
+}
+
+func (s *AuthenticationService) listAuthEvents(ctx context.Context, filter models.AuthEventFilter, page, pageSize int) ([]models.AuthEvent, int, error) {
+	validationErr := &domain.ValidationError{}
+	if page < 1 {
+		validationErr.Add("page", "must be at least 1")
+	}
+	if pageSize < 1 || pageSize > maxAuthEventsPageSize {
+		validationErr.Add("page_size", fmt.Sprintf("must be between 1 and %d", maxAuthEventsPageSize))
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		validationErr.Add("to", "must be after from")
+	}
+	if len(validationErr.Violations) > 0 {
+		return nil, 0, validationErr
+	}
+
+	events, total, err := s.auditLog.ListAuthEvents(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list auth events: %w", err)
+	}
+
+	return events, total, nil
+}
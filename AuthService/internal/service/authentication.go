@@ -2,35 +2,149 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
+	"Project/AuthService/internal/domain"
 	"Project/AuthService/internal/domain/message"
 	"Project/AuthService/internal/domain/models"
 	"Project/AuthService/internal/kafka"
 	"Project/AuthService/internal/logger"
 
 	"Project/AuthService/internal/storage/postgres"
+	"Project/AuthService/internal/validation"
+	"Project/AuthService/pkg/encryption"
 	"Project/AuthService/pkg/jwt"
+	passwordhash "Project/AuthService/pkg/password"
+	"Project/AuthService/pkg/totp"
+	"Project/pkg/authz"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 )
 
+const (
+	passwordResetTokenTTL = time.Hour
+	emailChangeCodeTTL    = 24 * time.Hour
+	confirmationCodeTTL   = 24 * time.Hour
+
+	defaultConfirmationResendCooldown = time.Minute
+
+	mfaChallengeTTL   = 5 * time.Minute
+	maxMFAAttempts    = 5
+	recoveryCodeCount = 10
+
+	// maxLockoutDoublings bounds the lockout backoff exponent.
+	maxLockoutDoublings = 30
+)
+
+// UnconfirmedLoginPolicy decides whether users who have not confirmed their email
+// yet may log in.
+type UnconfirmedLoginPolicy string
+
+const (
+	UnconfirmedLoginAllow UnconfirmedLoginPolicy = "allow"
+	UnconfirmedLoginBlock UnconfirmedLoginPolicy = "block"
+	// UnconfirmedLoginGrace lets unconfirmed users in for a grace period after
+	// registration and blocks them afterwards.
+	UnconfirmedLoginGrace UnconfirmedLoginPolicy = "grace"
+)
+
+func ParseUnconfirmedLoginPolicy(policy string) (UnconfirmedLoginPolicy, error) {
+	switch p := UnconfirmedLoginPolicy(policy); p {
+	case UnconfirmedLoginAllow, UnconfirmedLoginBlock, UnconfirmedLoginGrace:
+		return p, nil
+	case "":
+		return UnconfirmedLoginAllow, nil
+	default:
+		return "", fmt.Errorf("unknown unconfirmed login policy %q", policy)
+	}
+}
+
+// LoginProtectionSettings configures failed login counters. After
+// MaxAccountFailures failures within FailureWindow the account is locked for
+// BaseLockout, doubling with every further failure up to MaxLockout.
+// MaxLockout is optional, without it the doubling stops after
+// maxLockoutDoublings failures.
+type LoginProtectionSettings struct {
+	MaxAccountFailures int64
+	MaxIPFailures      int64
+	FailureWindow      time.Duration
+	BaseLockout        time.Duration
+	MaxLockout         time.Duration
+}
+
+type EmailConfirmationSettings struct {
+	UnconfirmedLoginPolicy      UnconfirmedLoginPolicy
+	UnconfirmedLoginGracePeriod time.Duration
+	ResendCooldown              time.Duration
+}
+
 type AuthenticationService struct {
 	userStorage     UserStorage
-	jwtSecretKey    string
+	sessionStorage  SessionStorage
+	mfaStorage      MFAStorage
+	identityStorage IdentityStorage
+	oauthStorage    OAuthStorage
+	roleStorage     RoleStorage
+	profileStorage  ProfileStorage
+	auditLog        AuthEventStorage
+	keyring         *jwt.Keyring
+	secretCipher    *encryption.Cipher
+	tokenIssuer     string
+	tokenAudience   string
+	oauthAudience   string
 	logger          *logger.Logger
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
 	KafkapProducer  MessageBroker
+	userEvents      MessageBroker
+	authEvents      MessageBroker
 	redisClient     RedisRepositories
+	revocations     TokenRevocations
+	confirmation    EmailConfirmationSettings
+	loginProtection LoginProtectionSettings
+	magicLink       MagicLinkSettings
+	passwords       *validation.PasswordValidator
+	passwordHasher  PasswordHasher
+
+	// dummyPasswordHash is verified against when the email is unknown.
+	dummyPasswordHash string
 }
 
 type RedisRepositories interface {
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 	Get(ctx context.Context, key string) (string, error)
-	Delete(ctx context.Context, key string) error
+	Delete(ctx context.Context, keys ...string) error
+	AcquireCooldown(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	Increment(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	SaveMFAChallenge(ctx context.Context, challengeHash, userID string, ttl time.Duration) error
+	GetMFAChallenge(ctx context.Context, challengeHash string) (string, error)
+	DeleteMFAChallenge(ctx context.Context, challengeHash string) error
+	Counter(ctx context.Context, key string) (int64, error)
+	ResetCounter(ctx context.Context, key string) error
+	Lock(ctx context.Context, key string, ttl time.Duration) error
+	IsLocked(ctx context.Context, key string) (bool, error)
+}
+
+// TokenRevocations is the revocation list of access token IDs shared with the
+// other services.
+type TokenRevocations interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// PasswordHasher hashes new passwords with the configured algorithm and still
+// verifies hashes made by the previous ones.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) error
+	NeedsRehash(encoded string) bool
 }
 
 type MessageBroker interface {
@@ -38,54 +152,146 @@ type MessageBroker interface {
 }
 
 type UserStorage interface {
-	CreateUser(ctx context.Context, email, password string) (uuid.UUID, error)
-	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	CreateUser(ctx context.Context, email, emailNormalized, password string) (uuid.UUID, error)
+	GetUserByEmail(ctx context.Context, emailNormalized string) (*models.User, error)
 	GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error)
-	UserExists(ctx context.Context, email string) (bool, error)
-	SaveTokens(ctx context.Context, userID uuid.UUID, accessToken string, accessExp time.Time, refreshToken string, refreshExp time.Time) error
-	DeleteTokens(ctx context.Context, userID uuid.UUID) error
-	ConfirmEmail(ctx context.Context, email, code string) (uuid.UUID, error)
+	UserExists(ctx context.Context, emailNormalized string) (bool, error)
+	ConfirmEmail(ctx context.Context, emailNormalized, code string) (uuid.UUID, error)
 	SaveConfirmationCode(ctx context.Context, userID uuid.UUID, confirmationCode string, confirmCodeExpiresAt time.Time) error
-	GetAccessToken(ctx context.Context, userID uuid.UUID) (string, time.Time, error)
+	SavePasswordResetToken(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error)
+	SaveMagicLinkToken(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error
+	ConsumeMagicLinkToken(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	SaveEmailChange(ctx context.Context, userID uuid.UUID, newEmail, newEmailNormalized, codeHash string, expiresAt time.Time) error
+	ConfirmEmailChange(ctx context.Context, userID uuid.UUID, codeHash string) (string, string, error)
+	SetLockedUntil(ctx context.Context, userID uuid.UUID, lockedUntil time.Time) error
+	DeleteUser(ctx context.Context, userID uuid.UUID) (time.Time, error)
+	ConfirmExternalEmail(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	ListUsers(ctx context.Context, filter models.UserFilter, page, pageSize int) ([]models.User, int, error)
+	SuspendUser(ctx context.Context, userID uuid.UUID, reason string, suspendedBy uuid.UUID) error
+	UnsuspendUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type IdentityStorage interface {
+	GetIdentity(ctx context.Context, provider, subject string) (*models.Identity, error)
+	CreateIdentity(ctx context.Context, identity *models.Identity) error
+	CreateUserWithIdentity(ctx context.Context, email, emailNormalized, passwordHash string, identity *models.Identity) (uuid.UUID, error)
+	ListIdentities(ctx context.Context, userID uuid.UUID) ([]models.Identity, error)
+}
+
+type MFAStorage interface {
+	GetMFA(ctx context.Context, userID uuid.UUID) (*models.MFA, error)
+	SavePendingSecret(ctx context.Context, userID uuid.UUID, secretEncrypted string) error
+	EnableMFA(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+}
+
+type SessionStorage interface {
+	CreateSession(ctx context.Context, session *models.Session, refreshTokenHash string) error
+	CreateImpersonationSession(ctx context.Context, session *models.Session, impersonation *models.Impersonation) error
+	UpdateSessionAccessToken(ctx context.Context, sessionID uuid.UUID, accessToken string, accessExp time.Time) error
+	RotateRefreshToken(ctx context.Context, oldHash, newHash string, newExp time.Time) (*models.Session, error)
+	GetSession(ctx context.Context, sessionID uuid.UUID) (*models.Session, error)
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) (string, error)
+	RevokeOtherSessions(ctx context.Context, userID, keepSessionID uuid.UUID) ([]models.Session, error)
 }
 
 func NewAuthenticationService(
 	userRepo *postgres.UserStorage,
-	jwtSecretKey string,
+	sessionRepo *postgres.SessionStorage,
+	mfaRepo *postgres.MFAStorage,
+	identityRepo *postgres.IdentityStorage,
+	oauthRepo *postgres.OAuthStorage,
+	roleRepo *postgres.RoleStorage,
+	profileRepo *postgres.ProfileStorage,
+	authEventRepo *postgres.AuthEventStorage,
+	keyring *jwt.Keyring,
+	secretCipher *encryption.Cipher,
+	tokenIssuer string,
+	tokenAudience string,
+	oauthAudience string,
 	accessTokenTTL time.Duration,
 	refreshTokenTTL time.Duration,
 	log *logger.Logger,
 	KafkaProducer *kafka.Producer,
+	userEvents *kafka.Producer,
+	authEvents *kafka.Producer,
 	redisClient RedisRepositories,
-) *AuthenticationService {
+	revocations TokenRevocations,
+	confirmation EmailConfirmationSettings,
+	loginProtection LoginProtectionSettings,
+	magicLink MagicLinkSettings,
+	passwords *validation.PasswordValidator,
+	passwordHasher PasswordHasher,
+) (*AuthenticationService, error) {
+	dummyPasswordHash, err := passwordHasher.Hash("dummy password")
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash dummy password: %w", err)
+	}
+
 	return &AuthenticationService{
 		userStorage:     userRepo,
-		jwtSecretKey:    jwtSecretKey,
+		sessionStorage:  sessionRepo,
+		mfaStorage:      mfaRepo,
+		identityStorage: identityRepo,
+		oauthStorage:    oauthRepo,
+		roleStorage:     roleRepo,
+		profileStorage:  profileRepo,
+		auditLog:        authEventRepo,
+		keyring:         keyring,
+		secretCipher:    secretCipher,
+		tokenIssuer:     tokenIssuer,
+		tokenAudience:   tokenAudience,
+		oauthAudience:   oauthAudience,
 		AccessTokenTTL:  accessTokenTTL,
 		RefreshTokenTTL: refreshTokenTTL,
 		logger:          log,
 		KafkapProducer:  KafkaProducer,
+		userEvents:      userEvents,
+		authEvents:      authEvents,
 		redisClient:     redisClient,
-	}
+		revocations:     revocations,
+		confirmation:    confirmation,
+		loginProtection: loginProtection,
+		magicLink:       magicLink,
+		passwords:       passwords,
+		passwordHasher:  passwordHasher,
+
+		dummyPasswordHash: dummyPasswordHash,
+	}, nil
 }
 
-func (s *AuthenticationService) Register(ctx context.Context, email, password, repeatPassword string) error {
+func (s *AuthenticationService) Register(ctx context.Context, email, password, repeatPassword string, client models.ClientInfo) (err error) {
+	email = strings.TrimSpace(email)
+
+	event := newAuthEvent(models.AuthEventRegister, client)
+	event.Email = validation.NormalizeEmail(email)
+	defer func() { s.recordAuthEvent(ctx, event, err) }()
+
 	if err := s.ValidateRegister(ctx, email, password, repeatPassword); err != nil {
 		return fmt.Errorf("Failed validate register;%w", err)
 	}
 
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	passwordHash, err := s.passwordHasher.Hash(password)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	userID, err := s.userStorage.CreateUser(ctx, email, string(passwordHash))
+	userID, err := s.userStorage.CreateUser(ctx, email, validation.NormalizeEmail(email), passwordHash)
 	if err != nil {
 		return fmt.Errorf("failed to register user: %w", err)
 	}
+	event.UserID = userID
+
+	return s.sendConfirmationCode(ctx, userID, email, "Welcome!")
+}
 
+func (s *AuthenticationService) sendConfirmationCode(ctx context.Context, userID uuid.UUID, email, subject string) error {
 	confirmationCode := jwt.GenerateConfirmationCode()
-	confirmCodeExpiresAt := time.Now().Add(24 * time.Hour)
+	confirmCodeExpiresAt := time.Now().Add(confirmationCodeTTL)
 
 	if err := s.userStorage.SaveConfirmationCode(ctx, userID, confirmationCode, confirmCodeExpiresAt); err != nil {
 		return fmt.Errorf("failed to save confirmation code: %w", err)
@@ -93,7 +299,7 @@ func (s *AuthenticationService) Register(ctx context.Context, email, password, r
 
 	message := message.ConfirmationMessage{
 		ToEmail: email,
-		Subject: "Welcome!",
+		Subject: subject,
 		Body:    confirmationCode,
 	}
 
@@ -104,138 +310,1205 @@ func (s *AuthenticationService) Register(ctx context.Context, email, password, r
 	return nil
 }
 
+// ResendConfirmationCode sends a new confirmation code, at most once per cooldown
+// for every email. Unknown and already confirmed emails are silently ignored.
+func (s *AuthenticationService) ResendConfirmationCode(ctx context.Context, email string) error {
+	cooldown := s.confirmation.ResendCooldown
+	if cooldown <= 0 {
+		cooldown = defaultConfirmationResendCooldown
+	}
+
+	email = validation.NormalizeEmail(email)
+
+	acquired, err := s.redisClient.AcquireCooldown(ctx, "confirmation_resend:"+email, cooldown)
+	if err != nil {
+		return fmt.Errorf("failed to check resend cooldown: %w", err)
+	}
+
+	if !acquired {
+		return domain.ErrConfirmationResendCooldown
+	}
+
+	user, err := s.userStorage.GetUserByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		s.logger.Info("Confirmation code requested for unknown email", zap.String("email", email))
+		return nil
+	}
+
+	if user.EmailConfirmed {
+		return nil
+	}
+
+	return s.sendConfirmationCode(ctx, user.ID, user.Email, "Your confirmation code")
+}
+
+// checkEmailConfirmed applies the unconfirmed login policy to the user.
+func (s *AuthenticationService) checkEmailConfirmed(user *models.User) error {
+	if user.EmailConfirmed {
+		return nil
+	}
+
+	switch s.confirmation.UnconfirmedLoginPolicy {
+	case UnconfirmedLoginBlock:
+		return domain.ErrEmailNotConfirmed
+	case UnconfirmedLoginGrace:
+		if time.Since(user.CreatedAt) > s.confirmation.UnconfirmedLoginGracePeriod {
+			return domain.ErrEmailNotConfirmed
+		}
+	}
+
+	return nil
+}
+
+// ValidateRegister checks the email syntax, the password policy and that no
+// account uses the email yet.
 func (s *AuthenticationService) ValidateRegister(ctx context.Context, email, password, repeatPassword string) error {
+	validationErr := &domain.ValidationError{}
+
+	if err := validation.ValidateEmail(email); err != nil {
+		validationErr.Add("email", err.Error())
+	}
+
+	if err := s.checkPassword(validationErr, "password", password, email); err != nil {
+		return err
+	}
+
 	if password != repeatPassword {
-		return fmt.Errorf("passwords do not match")
+		validationErr.Add("repeat_password", "passwords do not match")
+	}
+
+	if len(validationErr.Violations) > 0 {
+		return validationErr
 	}
 
-	exists, err := s.userStorage.UserExists(ctx, email)
+	exists, err := s.userStorage.UserExists(ctx, validation.NormalizeEmail(email))
 	if err != nil {
 		return fmt.Errorf("failed to check email uniqueness: %w", err)
 	}
 
 	if exists {
-		return fmt.Errorf("email already exists")
+		return domain.ErrEmailAlreadyExists
+	}
+	return nil
+}
+
+// checkPassword adds a violation of field to validationErr for every rule of
+// the password policy the password breaks.
+func (s *AuthenticationService) checkPassword(validationErr *domain.ValidationError, field, password string, userInputs ...string) error {
+	problems, err := s.passwords.Validate(password, userInputs...)
+	if err != nil {
+		return fmt.Errorf("failed to validate password: %w", err)
+	}
+
+	for _, problem := range problems {
+		validationErr.Add(field, problem)
+	}
+
+	return nil
+}
+
+func (s *AuthenticationService) validateNewPassword(field, password string, userInputs ...string) error {
+	validationErr := &domain.ValidationError{}
+	if err := s.checkPassword(validationErr, field, password, userInputs...); err != nil {
+		return err
+	}
+
+	if len(validationErr.Violations) > 0 {
+		return validationErr
 	}
+
 	return nil
 }
 
-func (s *AuthenticationService) Login(ctx context.Context, email, password string) (*models.User, string, string, error) {
+// Login checks the password. Users with two-factor authentication get an MFA
+// token to finish the login with LoginMFA instead of a token pair.
+func (s *AuthenticationService) Login(ctx context.Context, email, password string, client models.ClientInfo) (result *models.LoginResult, err error) {
+	email = validation.NormalizeEmail(email)
+
+	event := newAuthEvent(models.AuthEventLogin, client)
+	event.Email = email
+	defer func() { s.recordLoginEvent(ctx, event, result, err) }()
+
+	if err := s.checkLoginAllowed(ctx, email, client.IP); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userStorage.GetUserByEmail(ctx, email)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("failed to get user: %w", err)
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		// Spend the same time as for a wrong password so response times do not
+		// reveal whether the account exists.
+		_ = s.passwordHasher.Verify(password, s.dummyPasswordHash)
+		s.recordLoginFailure(ctx, email, client.IP, nil)
+		return nil, domain.ErrInvalidCredentials
+	}
+	event.UserID = user.ID
+
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return nil, domain.ErrTooManyLoginAttempts
+	}
+
+	if err := s.passwordHasher.Verify(password, user.PasswordHash); err != nil {
+		if !errors.Is(err, passwordhash.ErrMismatch) {
+			s.logger.Error("Failed to verify password hash", zap.String("userID", user.ID.String()), zap.Error(err))
+		}
+		s.recordLoginFailure(ctx, email, client.IP, user)
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	s.rehashPassword(ctx, user, password)
+
+	if err := s.redisClient.ResetCounter(ctx, accountFailuresKey(email)); err != nil {
+		s.logger.Error("Failed to reset login failures", zap.Error(err))
+	}
+
+	if err := s.checkEmailConfirmed(user); err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, user, client)
+}
+
+// completeLogin starts a session for a user whose first factor is verified, or
+// returns an MFA token when the user has two-factor authentication enabled.
+func (s *AuthenticationService) completeLogin(ctx context.Context, user *models.User, client models.ClientInfo) (*models.LoginResult, error) {
+	if user.Suspended() {
+		return nil, domain.ErrAccountSuspended
+	}
+
+	mfa, err := s.mfaStorage.GetMFA(ctx, user.ID)
+	if err != nil && !errors.Is(err, domain.ErrMFANotEnabled) {
+		return nil, fmt.Errorf("failed to get mfa: %w", err)
+	}
+
+	if mfa != nil && mfa.EnabledAt != nil {
+		mfaToken, err := jwt.GenerateOpaqueToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mfa token: %w", err)
+		}
+
+		if err := s.redisClient.SaveMFAChallenge(ctx, jwt.HashToken(mfaToken), user.ID.String(), mfaChallengeTTL); err != nil {
+			return nil, fmt.Errorf("failed to save mfa challenge: %w", err)
+		}
+
+		return &models.LoginResult{User: user, MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	return s.startSession(ctx, user, client)
+}
+
+// LoginWithExternalIdentity logs in the user an external identity is linked to.
+// An unknown identity is linked to the account with the same email, or a new
+// account is created for it, but only when the provider verified the email.
+func (s *AuthenticationService) LoginWithExternalIdentity(ctx context.Context, provider, subject, email string, emailVerified bool, client models.ClientInfo) (result *models.LoginResult, err error) {
+	event := newAuthEvent(models.AuthEventLoginExternal, client)
+	event.Email = validation.NormalizeEmail(email)
+	defer func() { s.recordLoginEvent(ctx, event, result, err) }()
+
+	var user *models.User
+
+	identity, err := s.identityStorage.GetIdentity(ctx, provider, subject)
+	switch {
+	case err == nil:
+		user, err = s.userStorage.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+	case errors.Is(err, domain.ErrIdentityNotFound):
+		user, err = s.linkExternalIdentity(ctx, provider, subject, email, emailVerified)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	event.UserID = user.ID
+
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return nil, domain.ErrTooManyLoginAttempts
+	}
+
+	return s.completeLogin(ctx, user, client)
+}
+
+func (s *AuthenticationService) linkExternalIdentity(ctx context.Context, provider, subject, email string, emailVerified bool) (*models.User, error) {
+	email = strings.TrimSpace(email)
+	if !emailVerified || validation.ValidateEmail(email) != nil {
+		return nil, domain.ErrExternalEmailNotVerified
+	}
+
+	identity := &models.Identity{
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	}
+
+	// Accounts created or taken over by a provider get a password nobody knows,
+	// the user can set one with the password reset flow.
+	passwordHash, err := s.unusablePasswordHash()
+	if err != nil {
+		return nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, "", "", fmt.Errorf("invalid password")
+	user, err := s.userStorage.GetUserByEmail(ctx, validation.NormalizeEmail(email))
+	switch {
+	case err == nil:
+		if !user.EmailConfirmed {
+			if err := s.userStorage.ConfirmExternalEmail(ctx, user.ID, passwordHash); err != nil {
+				return nil, fmt.Errorf("failed to confirm email: %w", err)
+			}
+			user.EmailConfirmed = true
+			user.PasswordHash = passwordHash
+		}
+
+		identity.UserID = user.ID
+		if err := s.identityStorage.CreateIdentity(ctx, identity); err != nil {
+			return nil, fmt.Errorf("failed to link identity: %w", err)
+		}
+
+		s.logger.Info("External identity linked", zap.String("userID", user.ID.String()), zap.String("provider", provider))
+		return user, nil
+	case errors.Is(err, domain.ErrUserNotFound):
+		userID, err := s.identityStorage.CreateUserWithIdentity(ctx, email, validation.NormalizeEmail(email), passwordHash, identity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+
+		s.logger.Info("User created from external identity", zap.String("userID", userID.String()), zap.String("provider", provider))
+
+		user, err := s.userStorage.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		return user, nil
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+}
 
-	accessToken, accessTokenExpiresAt, err := jwt.GenerateToken(user.ID, user.Email, s.jwtSecretKey, s.AccessTokenTTL)
+func (s *AuthenticationService) unusablePasswordHash() (string, error) {
+	secret, err := jwt.GenerateOpaqueToken()
 	if err != nil {
-		return nil, "", "", fmt.Errorf("failed to generate access token: %w", err)
+		return "", fmt.Errorf("failed to generate password: %w", err)
 	}
 
-	refreshToken, refreshTokenExpiresAt, err := jwt.GenerateToken(user.ID, user.Email, s.jwtSecretKey, s.RefreshTokenTTL)
+	passwordHash, err := s.passwordHasher.Hash(secret)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	if err := s.userStorage.SaveTokens(ctx, user.ID, accessToken, accessTokenExpiresAt, refreshToken, refreshTokenExpiresAt); err != nil {
-		return nil, "", "", fmt.Errorf("failed to save  token: %w", err)
+	return passwordHash, nil
+}
+
+// rehashPassword replaces a hash made with an old algorithm or old parameters
+// while the plain password is at hand. Failures only delay the upgrade to the
+// next login.
+func (s *AuthenticationService) rehashPassword(ctx context.Context, user *models.User, password string) {
+	if !s.passwordHasher.NeedsRehash(user.PasswordHash) {
+		return
 	}
 
-	userIDKey := user.ID.String()
-	ttl := time.Until(accessTokenExpiresAt)
-	if err := s.redisClient.Set(ctx, userIDKey, accessToken, ttl); err != nil {
-		return nil, "", "", fmt.Errorf("failed to save access token to Redis: %w", err)
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		s.logger.Error("Failed to rehash password", zap.String("userID", user.ID.String()), zap.Error(err))
+		return
+	}
+
+	if err := s.userStorage.UpdatePassword(ctx, user.ID, passwordHash); err != nil {
+		s.logger.Error("Failed to save rehashed password", zap.String("userID", user.ID.String()), zap.Error(err))
+		return
 	}
 
-	return user, accessToken, refreshToken, nil
+	user.PasswordHash = passwordHash
 }
 
-func (s *AuthenticationService) Logout(ctx context.Context, userID uuid.UUID) error {
-	userIDKey := userID.String()
-	if err := s.redisClient.Delete(ctx, userIDKey); err != nil {
-		return fmt.Errorf("failed to delete access token from Redis: %w", err)
+func accountFailuresKey(email string) string {
+	return "login_failures:account:" + email
+}
+
+func ipFailuresKey(ip string) string {
+	return "login_failures:ip:" + ip
+}
+
+// checkLoginAllowed rejects logins for locked emails and for addresses with too
+// many recent failures. Unknown emails are locked the same way as real accounts.
+func (s *AuthenticationService) checkLoginAllowed(ctx context.Context, email, ip string) error {
+	if ip != "" && s.loginProtection.MaxIPFailures > 0 {
+		failures, err := s.redisClient.Counter(ctx, ipFailuresKey(ip))
+		if err != nil {
+			return fmt.Errorf("failed to check login failures: %w", err)
+		}
+
+		if failures >= s.loginProtection.MaxIPFailures {
+			return domain.ErrTooManyLoginAttempts
+		}
+	}
+
+	locked, err := s.redisClient.IsLocked(ctx, accountFailuresKey(email))
+	if err != nil {
+		return fmt.Errorf("failed to check account lock: %w", err)
 	}
 
-	if err := s.userStorage.DeleteTokens(ctx, userID); err != nil {
-		return fmt.Errorf("failed to delete refresh token: %w", err)
+	if locked {
+		return domain.ErrTooManyLoginAttempts
 	}
 
 	return nil
 }
 
-func (s *AuthenticationService) Refresh(ctx context.Context, refreshToken string) (*models.User, string, string, error) {
-	userID, err := jwt.ExtractUserIDFromToken(refreshToken, s.jwtSecretKey)
+// recordLoginFailure counts a failed login for the email and the client address
+// and locks the email once it passes the threshold. user is nil for unknown
+// emails. Failures are only logged, the login is rejected either way.
+func (s *AuthenticationService) recordLoginFailure(ctx context.Context, email, ip string, user *models.User) {
+	window := s.loginProtection.FailureWindow
+
+	if ip != "" {
+		if _, err := s.redisClient.Increment(ctx, ipFailuresKey(ip), window); err != nil {
+			s.logger.Error("Failed to count login failure", zap.Error(err))
+		}
+	}
+
+	failures, err := s.redisClient.Increment(ctx, accountFailuresKey(email), window)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("failed to extract user_id from token")
+		s.logger.Error("Failed to count login failure", zap.Error(err))
+		return
 	}
-	user, err := s.userStorage.GetUserByID(ctx, userID)
+
+	threshold := s.loginProtection.MaxAccountFailures
+	if threshold <= 0 || failures < threshold {
+		return
+	}
+
+	lockout := s.loginProtection.BaseLockout
+	if doublings := min(failures-threshold, maxLockoutDoublings); lockout > math.MaxInt64>>doublings {
+		lockout = math.MaxInt64
+	} else {
+		lockout <<= doublings
+	}
+
+	if maxLockout := s.loginProtection.MaxLockout; maxLockout > 0 && lockout > maxLockout {
+		lockout = maxLockout
+	}
+
+	if lockout <= 0 {
+		return
+	}
+
+	if err := s.redisClient.Lock(ctx, accountFailuresKey(email), lockout); err != nil {
+		s.logger.Error("Failed to lock account", zap.Error(err))
+	}
+
+	if user == nil {
+		return
+	}
+
+	lockedUntil := time.Now().Add(lockout)
+	if err := s.userStorage.SetLockedUntil(ctx, user.ID, lockedUntil); err != nil {
+		s.logger.Error("Failed to lock account", zap.String("userID", user.ID.String()), zap.Error(err))
+	}
+
+	s.logger.Warn("Account locked after failed logins",
+		zap.String("userID", user.ID.String()),
+		zap.Int64("failures", failures),
+		zap.Duration("lockout", lockout),
+	)
+
+	if failures == threshold {
+		s.sendSecurityEvent(ctx, user.Email, message.SecurityEventMessage{
+			Subject: "Your account was temporarily locked",
+			Body:    fmt.Sprintf("Your account was locked until %s after %d failed login attempts. If this was not you, consider changing your password.", lockedUntil.UTC().Format(time.RFC1123), failures),
+			Event:   message.EventAccountLocked,
+			UserID:  user.ID.String(),
+		})
+	}
+}
+
+// startSession creates a session for an authenticated user and issues its
+// token pair.
+func (s *AuthenticationService) startSession(ctx context.Context, user *models.User, client models.ClientInfo) (*models.LoginResult, error) {
+	var err error
+
+	session := &models.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		DeviceName: client.DeviceName,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+	}
+
+	session.AccessToken, session.AccessTokenExpiresAt, err = s.generateAccessToken(ctx, user, session.ID)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("failed to fetch user: %w", err)
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	accessToken, accessTokenExpiresAt, err := jwt.GenerateToken(user.ID, user.Email, s.jwtSecretKey, s.AccessTokenTTL)
+	refreshToken, err := jwt.GenerateOpaqueToken()
 	if err != nil {
-		return nil, "", "", fmt.Errorf("failed to generate access token: %w", err)
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	session.RefreshTokenExpiresAt = time.Now().Add(s.RefreshTokenTTL)
+
+	if err := s.sessionStorage.CreateSession(ctx, session, jwt.HashToken(refreshToken)); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	ttl := time.Until(session.AccessTokenExpiresAt)
+	if err := s.redisClient.Set(ctx, session.ID.String(), session.AccessToken, ttl); err != nil {
+		return nil, fmt.Errorf("failed to save access token to Redis: %w", err)
+	}
+
+	return &models.LoginResult{User: user, AccessToken: session.AccessToken, RefreshToken: refreshToken, SessionID: session.ID}, nil
+}
+
+// Logout revokes the session the access token belongs to.
+func (s *AuthenticationService) Logout(ctx context.Context, accessToken string, client models.ClientInfo) (err error) {
+	event := newAuthEvent(models.AuthEventLogout, client)
+	defer func() { s.recordAuthEvent(ctx, event, err) }()
+
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	event.UserID = claims.UserID
+	event.SessionID = claims.SessionID
+
+	if err := s.revokeSession(ctx, claims.UserID, claims.SessionID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every session of the user the access token belongs to,
+// including its own, and returns how many there were.
+func (s *AuthenticationService) LogoutAll(ctx context.Context, accessToken string, client models.ClientInfo) (revoked int, err error) {
+	event := newAuthEvent(models.AuthEventLogout, client)
+	defer func() { s.recordAuthEvent(ctx, event, err) }()
+
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return 0, fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	event.UserID = claims.UserID
+	event.SessionID = claims.SessionID
+
+	revoked, err = s.revokeOtherSessions(ctx, claims.UserID, uuid.Nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
 	}
 
-	refreshToken, refreshTokenExpiresAt, err := jwt.GenerateToken(user.ID, user.Email, s.jwtSecretKey, s.RefreshTokenTTL)
+	return revoked, nil
+}
+
+// Refresh exchanges a single-use refresh token for a new token pair. Every
+// refresh token belongs to the family of its session; presenting one that was
+// already exchanged means it leaked, so the whole family is revoked.
+func (s *AuthenticationService) Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (user *models.User, accessToken, newRefreshToken string, err error) {
+	event := newAuthEvent(models.AuthEventRefresh, client)
+	defer func() { s.recordAuthEvent(ctx, event, err) }()
+
+	newRefreshToken, err = jwt.GenerateOpaqueToken()
 	if err != nil {
 		return nil, "", "", fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
-	if err := s.userStorage.SaveTokens(ctx, user.ID, accessToken, accessTokenExpiresAt, refreshToken, refreshTokenExpiresAt); err != nil {
+	session, err := s.sessionStorage.RotateRefreshToken(ctx, jwt.HashToken(refreshToken), jwt.HashToken(newRefreshToken), time.Now().Add(s.RefreshTokenTTL))
+	if session != nil {
+		event.UserID = session.UserID
+		event.SessionID = session.ID
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			s.handleRefreshTokenReuse(ctx, session)
+		}
+		return nil, "", "", fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	user, err = s.userStorage.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if user.Suspended() {
+		return nil, "", "", domain.ErrAccountSuspended
+	}
+
+	accessToken, accessTokenExpiresAt, err := s.generateAccessToken(ctx, user, session.ID)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	if err := s.sessionStorage.UpdateSessionAccessToken(ctx, session.ID, accessToken, accessTokenExpiresAt); err != nil {
 		return nil, "", "", fmt.Errorf("failed to save access token: %w", err)
 	}
 
-	userIDKey := user.ID.String()
 	ttl := time.Until(accessTokenExpiresAt)
-	if err := s.redisClient.Set(ctx, userIDKey, accessToken, ttl); err != nil {
+	if err := s.redisClient.Set(ctx, session.ID.String(), accessToken, ttl); err != nil {
 		return nil, "", "", fmt.Errorf("failed to save access token to Redis: %w", err)
 	}
 
-	return user, accessToken, refreshToken, nil
+	return user, accessToken, newRefreshToken, nil
 }
 
-func (s *AuthenticationService) Me(ctx context.Context, accessToken string) (*models.User, error) {
-	userID, err := jwt.ExtractUserIDFromToken(accessToken, s.jwtSecretKey)
+// handleRefreshTokenReuse drops the cached access token of the revoked family and
+// warns the owner. Failures are only logged: the family is already revoked.
+func (s *AuthenticationService) handleRefreshTokenReuse(ctx context.Context, session *models.Session) {
+	s.logger.Warn("Refresh token reuse detected, session revoked",
+		zap.String("userID", session.UserID.String()),
+		zap.String("sessionID", session.ID.String()),
+	)
+
+	if err := s.redisClient.Delete(ctx, session.ID.String()); err != nil {
+		s.logger.Error("Failed to delete access token from Redis", zap.Error(err))
+	}
+
+	if err := s.revokeAccessTokens(ctx, session.AccessToken); err != nil {
+		s.logger.Error("Failed to revoke access token", zap.Error(err))
+	}
+
+	user, err := s.userStorage.GetUserByID(ctx, session.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract user ID: %w", err)
+		s.logger.Error("Failed to fetch user for security event", zap.Error(err))
+		return
+	}
+
+	s.sendSecurityEvent(ctx, user.Email, message.SecurityEventMessage{
+		Subject:   "Security alert: suspicious sign-in activity",
+		Body:      "A refresh token of one of your sessions was used twice, which usually means it was stolen. The session has been signed out. If this was not you, change your password.",
+		Event:     message.EventRefreshTokenReuse,
+		UserID:    session.UserID.String(),
+		SessionID: session.ID.String(),
+	})
+}
+
+// sendSecurityEvent emails a security notification to toEmail. Failures are only
+// logged since the change the event describes has already been made.
+func (s *AuthenticationService) sendSecurityEvent(ctx context.Context, toEmail string, event message.SecurityEventMessage) {
+	event.ToEmail = toEmail
+	event.OccurredAt = time.Now().UTC()
+
+	if err := s.KafkapProducer.SendMessage(ctx, toEmail, event); err != nil {
+		s.logger.Error("Failed to send security event to kafka", zap.String("event", event.Event), zap.Error(err))
 	}
+}
 
-	userIDKey := userID.String()
-	storedAccessToken, err := s.redisClient.Get(ctx, userIDKey)
+// Me returns the user of the access token with its current roles, which may
+// be newer than the ones embedded in the token.
+func (s *AuthenticationService) Me(ctx context.Context, accessToken string) (*models.User, *models.UserAccess, error) {
+	claims, err := s.authenticate(ctx, accessToken)
 	if err != nil {
-		storedAccessToken, expiresAt, err := s.userStorage.GetAccessToken(ctx, userID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid or expired access token: %w", err)
-		}
+		return nil, nil, err
+	}
 
-		ttl := time.Until(expiresAt)
-		if err := s.redisClient.Set(ctx, userIDKey, storedAccessToken, ttl); err != nil {
-			return nil, fmt.Errorf("failed to save access token to Redis: %w", err)
-		}
+	user, err := s.userStorage.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch user: %w", err)
 	}
 
-	if storedAccessToken != accessToken {
-		return nil, fmt.Errorf("invalid access token")
+	if user.Suspended() {
+		return nil, nil, domain.ErrAccountSuspended
 	}
 
-	user, err := s.userStorage.GetUserByID(ctx, userID)
+	access, err := s.roleStorage.GetUserAccess(ctx, user.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch user: %w", err)
+		return nil, nil, fmt.Errorf("failed to get user roles: %w", err)
 	}
 
-	return user, nil
+	return user, access, nil
 }
 
-func (s *AuthenticationService) ConfirmEmail(ctx context.Context, email, confirmationCode string) (uuid.UUID, error) {
-	userID, err := s.userStorage.ConfirmEmail(ctx, email, confirmationCode)
+// authenticate checks the access token signature and that it is still the current
+// token of its session, looking in Redis first and falling back to Postgres.
+func (s *AuthenticationService) authenticate(ctx context.Context, accessToken string) (*jwt.TokenClaims, error) {
+	claims, err := jwt.ParseToken(accessToken, s.keyring, s.tokenAudience)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to confirm email: %w", err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnauthenticated, err)
+	}
+
+	revoked, err := s.revocations.IsRevoked(ctx, claims.ID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	sessionKey := claims.SessionID.String()
+	storedAccessToken, err := s.redisClient.Get(ctx, sessionKey)
+	if err != nil {
+		session, err := s.sessionStorage.GetSession(ctx, claims.SessionID)
+		if err != nil {
+			if errors.Is(err, domain.ErrSessionNotFound) {
+				return nil, domain.ErrUnauthenticated
+			}
+			return nil, fmt.Errorf("failed to get session: %w", err)
+		}
+
+		storedAccessToken = session.AccessToken
+		ttl := time.Until(session.AccessTokenExpiresAt)
+		if ttl > 0 {
+			if err := s.redisClient.Set(ctx, sessionKey, storedAccessToken, ttl); err != nil {
+				return nil, fmt.Errorf("failed to save access token to Redis: %w", err)
+			}
+		}
+	}
+
+	if storedAccessToken != accessToken {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	return claims, nil
+}
+
+// generateAccessToken embeds the current roles of the user, so role changes
+// reach other services with the next refresh.
+func (s *AuthenticationService) generateAccessToken(ctx context.Context, user *models.User, sessionID uuid.UUID) (string, time.Time, error) {
+	access, err := s.roleStorage.GetUserAccess(ctx, user.ID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	claims := jwt.TokenClaims{
+		UserID:      user.ID,
+		SessionID:   sessionID,
+		Email:       user.Email,
+		Roles:       access.Roles,
+		Permissions: access.Permissions,
+		Issuer:      s.tokenIssuer,
+		Audience:    s.tokenAudience,
+	}
+
+	return jwt.GenerateToken(s.keyring, claims, s.AccessTokenTTL)
+}
+
+// JWKS returns the public keys tokens can be verified with.
+func (s *AuthenticationService) JWKS() []jwt.JSONWebKey {
+	return s.keyring.JWKS()
+}
+
+func (s *AuthenticationService) ListSessions(ctx context.Context, accessToken string) ([]models.Session, uuid.UUID, error) {
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	sessions, err := s.sessionStorage.ListSessions(ctx, claims.UserID)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, claims.SessionID, nil
+}
+
+func (s *AuthenticationService) RevokeSession(ctx context.Context, accessToken string, sessionID uuid.UUID) error {
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if err := s.revokeSession(ctx, claims.UserID, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AuthenticationService) revokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	accessToken, err := s.sessionStorage.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.redisClient.Delete(ctx, sessionID.String()); err != nil {
+		return fmt.Errorf("failed to delete access token from Redis: %w", err)
+	}
+
+	return s.revokeAccessTokens(ctx, accessToken)
+}
+
+func (s *AuthenticationService) RevokeAllOtherSessions(ctx context.Context, accessToken string) (int, error) {
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked, err := s.revokeOtherSessions(ctx, claims.UserID, claims.SessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return revoked, nil
+}
+
+func (s *AuthenticationService) revokeOtherSessions(ctx context.Context, userID, keepSessionID uuid.UUID) (int, error) {
+	revoked, err := s.sessionStorage.RevokeOtherSessions(ctx, userID, keepSessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, 0, len(revoked))
+	accessTokens := make([]string, 0, len(revoked))
+	for _, session := range revoked {
+		keys = append(keys, session.ID.String())
+		accessTokens = append(accessTokens, session.AccessToken)
+	}
+
+	if err := s.redisClient.Delete(ctx, keys...); err != nil {
+		return 0, fmt.Errorf("failed to delete access tokens from Redis: %w", err)
+	}
+
+	if err := s.revokeAccessTokens(ctx, accessTokens...); err != nil {
+		return 0, err
+	}
+
+	return len(revoked), nil
+}
+
+// RevokeAccessToken puts an access token on the revocation list. Without a
+// token ID the caller's own token is revoked; revoking someone else's token
+// requires the tokens:revoke permission. The ID alone does not tell when that
+// token expires, so it is kept for the longest possible lifetime.
+func (s *AuthenticationService) RevokeAccessToken(ctx context.Context, accessToken string, jti uuid.UUID) error {
+	if jti == uuid.Nil {
+		claims, err := s.authenticate(ctx, accessToken)
+		if err != nil {
+			return err
+		}
+
+		if err := s.revocations.Revoke(ctx, claims.ID.String(), claims.ExpiresAt); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+		return nil
+	}
+
+	admin, err := s.authorize(ctx, accessToken, authz.PermissionRevokeTokens)
+	if err != nil {
+		return err
+	}
+
+	if err := s.revocations.Revoke(ctx, jti.String(), time.Now().Add(s.AccessTokenTTL)); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	s.logger.Info("Access token revoked", zap.String("jti", jti.String()), zap.String("adminID", admin.UserID.String()))
+	return nil
+}
+
+// revokeAccessTokens puts the still valid ones of the access tokens on the
+// revocation list so FeedService and the gateway stop accepting them too.
+func (s *AuthenticationService) revokeAccessTokens(ctx context.Context, accessTokens ...string) error {
+	for _, accessToken := range accessTokens {
+		if accessToken == "" {
+			continue
+		}
+
+		claims, err := jwt.ParseToken(accessToken, s.keyring, s.tokenAudience)
+		if err != nil {
+			// Expired or issued before tokens had an ID.
+			continue
+		}
+
+		if err := s.revocations.Revoke(ctx, claims.ID.String(), claims.ExpiresAt); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *AuthenticationService) ConfirmEmail(ctx context.Context, email, confirmationCode string, client models.ClientInfo) (userID uuid.UUID, err error) {
+	event := newAuthEvent(models.AuthEventConfirmEmail, client)
+	event.Email = validation.NormalizeEmail(email)
+	defer func() {
+		event.UserID = userID
+		s.recordAuthEvent(ctx, event, err)
+	}()
+
+	userID, err = s.userStorage.ConfirmEmail(ctx, event.Email, confirmationCode)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to confirm email: %w", err)
 	}
 
 	return userID, nil
 }
+
+// RequestPasswordReset emails a one-time reset token to the user. Unknown emails
+// are not reported to the caller so the endpoint cannot be used to find accounts.
+func (s *AuthenticationService) RequestPasswordReset(ctx context.Context, email string) error {
+	email = validation.NormalizeEmail(email)
+
+	user, err := s.userStorage.GetUserByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		s.logger.Info("Password reset requested for unknown email", zap.String("email", email))
+		return nil
+	}
+
+	token, err := jwt.GenerateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+
+	if err := s.userStorage.SavePasswordResetToken(ctx, user.ID, jwt.HashToken(token), time.Now().Add(passwordResetTokenTTL)); err != nil {
+		return fmt.Errorf("failed to save password reset token: %w", err)
+	}
+
+	message := message.ConfirmationMessage{
+		ToEmail: user.Email,
+		Subject: "Password reset",
+		Body:    fmt.Sprintf("Use this token to reset your password: %s\nIt expires in %s. If you did not request a reset, ignore this email.", token, passwordResetTokenTTL),
+	}
+
+	if err := s.KafkapProducer.SendMessage(ctx, user.Email, message); err != nil {
+		return fmt.Errorf("failed to send password reset token to kafka: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using a token from RequestPasswordReset and
+// signs the user out everywhere.
+func (s *AuthenticationService) ResetPassword(ctx context.Context, token, newPassword string, client models.ClientInfo) (err error) {
+	event := newAuthEvent(models.AuthEventPasswordReset, client)
+	defer func() { s.recordAuthEvent(ctx, event, err) }()
+
+	if err := s.validateNewPassword("new_password", newPassword); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	userID, err := s.userStorage.ResetPassword(ctx, jwt.HashToken(token), passwordHash)
+	if err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+	event.UserID = userID
+
+	if _, err := s.revokeOtherSessions(ctx, userID, uuid.Nil); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// ChangePassword replaces the password after checking the current one. Every
+// other session is signed out, the one making the change stays logged in.
+func (s *AuthenticationService) ChangePassword(ctx context.Context, accessToken, oldPassword, newPassword string, client models.ClientInfo) (err error) {
+	event := newAuthEvent(models.AuthEventPasswordChange, client)
+	defer func() { s.recordAuthEvent(ctx, event, err) }()
+
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	event.UserID = claims.UserID
+	event.SessionID = claims.SessionID
+
+	user, err := s.userStorage.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if err := s.passwordHasher.Verify(oldPassword, user.PasswordHash); err != nil {
+		return domain.NewValidationError("old_password", "does not match the current password")
+	}
+
+	if err := s.validateNewPassword("new_password", newPassword, user.Email); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userStorage.UpdatePassword(ctx, user.ID, passwordHash); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if _, err := s.revokeOtherSessions(ctx, user.ID, claims.SessionID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.sendSecurityEvent(ctx, user.Email, message.SecurityEventMessage{
+		Subject:   "Your password was changed",
+		Body:      "The password of your account was changed and your other sessions were signed out. If this was not you, reset your password immediately.",
+		Event:     message.EventPasswordChanged,
+		UserID:    user.ID.String(),
+		SessionID: claims.SessionID.String(),
+	})
+
+	return nil
+}
+
+// ChangeEmail sends a confirmation code to the new address. The email of the
+// account only changes once the code is confirmed with ConfirmEmailChange.
+func (s *AuthenticationService) ChangeEmail(ctx context.Context, accessToken, newEmail string) error {
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	newEmail = strings.TrimSpace(newEmail)
+	if err := validation.ValidateEmail(newEmail); err != nil {
+		return domain.NewValidationError("new_email", err.Error())
+	}
+
+	newEmailNormalized := validation.NormalizeEmail(newEmail)
+	if newEmailNormalized == validation.NormalizeEmail(claims.Email) {
+		return domain.NewValidationError("new_email", "must differ from the current email")
+	}
+
+	exists, err := s.userStorage.UserExists(ctx, newEmailNormalized)
+	if err != nil {
+		return fmt.Errorf("failed to check email uniqueness: %w", err)
+	}
+
+	if exists {
+		return domain.ErrEmailAlreadyExists
+	}
+
+	confirmationCode := jwt.GenerateConfirmationCode()
+
+	if err := s.userStorage.SaveEmailChange(ctx, claims.UserID, newEmail, newEmailNormalized, jwt.HashToken(confirmationCode), time.Now().Add(emailChangeCodeTTL)); err != nil {
+		return fmt.Errorf("failed to save email change: %w", err)
+	}
+
+	message := message.ConfirmationMessage{
+		ToEmail: newEmail,
+		Subject: "Confirm your new email",
+		Body:    confirmationCode,
+	}
+
+	if err := s.KafkapProducer.SendMessage(ctx, newEmail, message); err != nil {
+		return fmt.Errorf("failed to send confirm code to kafka: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AuthenticationService) ConfirmEmailChange(ctx context.Context, accessToken, confirmationCode string) error {
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	oldEmail, newEmail, err := s.userStorage.ConfirmEmailChange(ctx, claims.UserID, jwt.HashToken(confirmationCode))
+	if err != nil {
+		return fmt.Errorf("failed to confirm email change: %w", err)
+	}
+
+	s.sendSecurityEvent(ctx, oldEmail, message.SecurityEventMessage{
+		Subject:   "Your email was changed",
+		Body:      fmt.Sprintf("The email of your account was changed to %s. If this was not you, contact support.", newEmail),
+		Event:     message.EventEmailChanged,
+		UserID:    claims.UserID.String(),
+		SessionID: claims.SessionID.String(),
+	})
+
+	return nil
+}
+
+// Enable2FA creates a new TOTP secret for the user. It stays inactive until a
+// code generated from it is confirmed with Verify2FA.
+func (s *AuthenticationService) Enable2FA(ctx context.Context, accessToken string) (string, string, error) {
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+
+	secretEncrypted, err := s.secretCipher.Encrypt(secret)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt totp secret: %w", err)
+	}
+
+	if err := s.mfaStorage.SavePendingSecret(ctx, claims.UserID, secretEncrypted); err != nil {
+		return "", "", err
+	}
+
+	return secret, totp.URI(s.tokenIssuer, claims.Email, secret), nil
+}
+
+// Verify2FA activates two-factor authentication and returns the recovery codes.
+// They are only stored hashed, so this is the only time they can be shown.
+func (s *AuthenticationService) Verify2FA(ctx context.Context, accessToken, code string) ([]string, error) {
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := s.mfaStorage.GetMFA(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa.EnabledAt != nil {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := s.secretCipher.Decrypt(mfa.SecretEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+
+	step, ok := totp.Validate(strings.TrimSpace(code), secret, time.Now())
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaStorage.EnableMFA(ctx, claims.UserID, step, recoveryCodeHashes); err != nil {
+		return nil, err
+	}
+
+	s.sendSecurityEvent(ctx, claims.Email, message.SecurityEventMessage{
+		Subject:   "Two-factor authentication enabled",
+		Body:      "Two-factor authentication was enabled for your account. If this was not you, contact support.",
+		Event:     message.EventMFAEnabled,
+		UserID:    claims.UserID.String(),
+		SessionID: claims.SessionID.String(),
+	})
+
+	return recoveryCodes, nil
+}
+
+// LoginMFA finishes a login started by Login with a TOTP or recovery code. An
+// MFA token allows maxMFAAttempts wrong codes before it is dropped.
+func (s *AuthenticationService) LoginMFA(ctx context.Context, mfaToken, code string, client models.ClientInfo) (result *models.LoginResult, err error) {
+	event := newAuthEvent(models.AuthEventLoginMFA, client)
+	defer func() { s.recordLoginEvent(ctx, event, result, err) }()
+
+	challengeHash := jwt.HashToken(mfaToken)
+
+	userIDStr, err := s.redisClient.GetMFAChallenge(ctx, challengeHash)
+	if err != nil {
+		return nil, domain.ErrInvalidMFAChallenge
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, domain.ErrInvalidMFAChallenge
+	}
+	event.UserID = userID
+
+	if err := s.verifySecondFactor(ctx, userID, code); err != nil {
+		attempts, incErr := s.redisClient.Increment(ctx, "mfa_attempts:"+challengeHash, mfaChallengeTTL)
+		if incErr != nil || attempts >= maxMFAAttempts {
+			if delErr := s.redisClient.DeleteMFAChallenge(ctx, challengeHash); delErr != nil {
+				s.logger.Error("Failed to delete mfa challenge", zap.Error(delErr))
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.redisClient.DeleteMFAChallenge(ctx, challengeHash); err != nil {
+		return nil, fmt.Errorf("failed to delete mfa challenge: %w", err)
+	}
+
+	user, err := s.userStorage.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if user.Suspended() {
+		return nil, domain.ErrAccountSuspended
+	}
+
+	return s.startSession(ctx, user, client)
+}
+
+// verifySecondFactor accepts a TOTP code or, failing that, an unused recovery code.
+func (s *AuthenticationService) verifySecondFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	mfa, err := s.mfaStorage.GetMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if mfa.EnabledAt == nil {
+		return domain.ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		secret, err := s.secretCipher.Decrypt(mfa.SecretEncrypted)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt totp secret: %w", err)
+		}
+
+		step, ok := totp.Validate(code, secret, time.Now())
+		if !ok {
+			return domain.ErrInvalidMFACode
+		}
+
+		return s.mfaStorage.UseStep(ctx, userID, step)
+	}
+
+	if err := s.mfaStorage.UseRecoveryCode(ctx, userID, jwt.HashToken(normalizeRecoveryCode(code))); err != nil {
+		return err
+	}
+
+	user, err := s.userStorage.GetUserByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to fetch user for security event", zap.Error(err))
+		return nil
+	}
+
+	s.sendSecurityEvent(ctx, user.Email, message.SecurityEventMessage{
+		Subject: "A recovery code was used",
+		Body:    "One of your two-factor recovery codes was used to sign in. If this was not you, reset your password and contact support.",
+		Event:   message.EventRecoveryCodeUsed,
+		UserID:  userID.String(),
+	})
+
+	return nil
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes returns recovery codes formatted as "xxxx-xxxx" together
+// with the hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes = append(codes, raw[:4]+"-"+raw[4:])
+		hashes = append(hashes, jwt.HashToken(raw))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
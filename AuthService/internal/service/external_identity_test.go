@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	passwordhash "Project/AuthService/pkg/password"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeIdentityStorage links identities in memory. Users it creates are added
+// to users so the service can read them back.
+type fakeIdentityStorage struct {
+	IdentityStorage
+
+	users      *fakeUserStorage
+	identities []models.Identity
+}
+
+func (f *fakeIdentityStorage) GetIdentity(_ context.Context, provider, subject string) (*models.Identity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, domain.ErrIdentityNotFound
+}
+
+func (f *fakeIdentityStorage) CreateIdentity(_ context.Context, identity *models.Identity) error {
+	f.identities = append(f.identities, *identity)
+	return nil
+}
+
+func (f *fakeIdentityStorage) CreateUserWithIdentity(_ context.Context, email, _, passwordHash string, identity *models.Identity) (uuid.UUID, error) {
+	user := &models.User{ID: uuid.New(), Email: email, PasswordHash: passwordHash, EmailConfirmed: true}
+	f.users.users[user.ID] = user
+
+	identity.UserID = user.ID
+	f.identities = append(f.identities, *identity)
+	return user.ID, nil
+}
+
+func TestLoginWithExternalIdentity(t *testing.T) {
+	const (
+		provider = "github"
+		subject  = "12345"
+	)
+
+	lockedUntil := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name string
+		// existing is the account already registered with user@example.com.
+		existing      *models.User
+		linked        bool
+		email         string
+		emailVerified bool
+		wantErr       error
+		// wantUser is "existing" or "new" for the user that is logged in.
+		wantUser        string
+		wantLinked      bool
+		wantConfirmed   bool
+		wantPasswordSet bool
+	}{
+		{
+			name:          "known identity logs in its user",
+			existing:      &models.User{Email: "user@example.com", EmailConfirmed: true},
+			linked:        true,
+			email:         "other@example.com",
+			emailVerified: true,
+			wantUser:      "existing",
+		},
+		{
+			name:          "verified email links the account with the same email",
+			existing:      &models.User{Email: "user@example.com", EmailConfirmed: true},
+			email:         "User@Example.com",
+			emailVerified: true,
+			wantUser:      "existing",
+			wantLinked:    true,
+		},
+		{
+			name:            "unconfirmed account is confirmed and its password replaced",
+			existing:        &models.User{Email: "user@example.com"},
+			email:           "user@example.com",
+			emailVerified:   true,
+			wantUser:        "existing",
+			wantLinked:      true,
+			wantConfirmed:   true,
+			wantPasswordSet: true,
+		},
+		{
+			name:          "unknown email creates an account",
+			email:         "new@example.com",
+			emailVerified: true,
+			wantUser:      "new",
+			wantLinked:    true,
+		},
+		{
+			name:     "unverified email is never linked",
+			existing: &models.User{Email: "user@example.com", EmailConfirmed: true},
+			email:    "user@example.com",
+			wantErr:  domain.ErrExternalEmailNotVerified,
+		},
+		{
+			name:          "invalid email is never linked",
+			email:         "not an email",
+			emailVerified: true,
+			wantErr:       domain.ErrExternalEmailNotVerified,
+		},
+		{
+			name:          "locked account",
+			existing:      &models.User{Email: "user@example.com", EmailConfirmed: true, LockedUntil: &lockedUntil},
+			linked:        true,
+			email:         "user@example.com",
+			emailVerified: true,
+			wantErr:       domain.ErrTooManyLoginAttempts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			s.passwordHasher = passwordhash.NewHasher(passwordhash.NewBcrypt(bcrypt.MinCost))
+			s.mfaStorage = &fakeMFAStorage{}
+
+			users := s.userStorage.(*fakeUserStorage)
+			identities := &fakeIdentityStorage{users: users}
+			s.identityStorage = identities
+
+			if tt.existing != nil {
+				tt.existing.ID = uuid.New()
+				users.users[tt.existing.ID] = tt.existing
+				if tt.linked {
+					identities.identities = append(identities.identities, models.Identity{UserID: tt.existing.ID, Provider: provider, Subject: subject})
+				}
+			}
+			linkedBefore := len(identities.identities)
+
+			result, err := s.LoginWithExternalIdentity(context.Background(), provider, subject, tt.email, tt.emailVerified, models.ClientInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoginWithExternalIdentity() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if linked := len(identities.identities) > linkedBefore; linked != tt.wantLinked {
+				t.Errorf("identity linked = %t, want %t", linked, tt.wantLinked)
+			}
+			if err != nil {
+				return
+			}
+
+			switch tt.wantUser {
+			case "existing":
+				if result.User.ID != tt.existing.ID {
+					t.Errorf("logged in %s, want the existing account %s", result.User.ID, tt.existing.ID)
+				}
+			case "new":
+				if tt.existing != nil && result.User.ID == tt.existing.ID {
+					t.Errorf("logged in the existing account, want a new one")
+				}
+				if result.User.Email != tt.email {
+					t.Errorf("new account email = %q, want %q", result.User.Email, tt.email)
+				}
+			}
+			if result.AccessToken == "" || result.RefreshToken == "" {
+				t.Errorf("no token pair issued")
+			}
+
+			if tt.wantLinked {
+				identity, err := identities.GetIdentity(context.Background(), provider, subject)
+				if err != nil || identity.UserID != result.User.ID {
+					t.Errorf("identity is linked to %v (%v), want %s", identity, err, result.User.ID)
+				}
+			}
+
+			confirmed := slices.Contains(users.confirmed, result.User.ID)
+			if confirmed != tt.wantConfirmed {
+				t.Errorf("email confirmed = %t, want %t", confirmed, tt.wantConfirmed)
+			}
+			_, passwordSet := users.passwordHashes[result.User.ID]
+			if passwordSet != tt.wantPasswordSet {
+				t.Errorf("password replaced = %t, want %t", passwordSet, tt.wantPasswordSet)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/internal/logger"
+	"Project/AuthService/internal/validation"
+	"Project/AuthService/pkg/jwt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// The fakes embed the interface they stand in for, so a call the test did not
+// expect panics instead of passing silently.
+
+type fakeSessionStorage struct {
+	SessionStorage
+
+	rotate func(oldHash, newHash string) (*models.Session, error)
+
+	accessTokens map[uuid.UUID]string
+	created      []models.Session
+}
+
+func (f *fakeSessionStorage) CreateSession(_ context.Context, session *models.Session, _ string) error {
+	f.created = append(f.created, *session)
+	return nil
+}
+
+func (f *fakeSessionStorage) RotateRefreshToken(_ context.Context, oldHash, newHash string, _ time.Time) (*models.Session, error) {
+	return f.rotate(oldHash, newHash)
+}
+
+func (f *fakeSessionStorage) UpdateSessionAccessToken(_ context.Context, sessionID uuid.UUID, accessToken string, _ time.Time) error {
+	if f.accessTokens == nil {
+		f.accessTokens = make(map[uuid.UUID]string)
+	}
+	f.accessTokens[sessionID] = accessToken
+	return nil
+}
+
+type fakeRoleStorage struct {
+	RoleStorage
+}
+
+func (f *fakeRoleStorage) GetUserAccess(_ context.Context, _ uuid.UUID) (*models.UserAccess, error) {
+	return &models.UserAccess{}, nil
+}
+
+type fakeRevocations struct {
+	TokenRevocations
+
+	revoked []string
+}
+
+func (f *fakeRevocations) Revoke(_ context.Context, jti string, _ time.Time) error {
+	f.revoked = append(f.revoked, jti)
+	return nil
+}
+
+func (f *fakeRevocations) IsRevoked(_ context.Context, jti string) (bool, error) {
+	return slices.Contains(f.revoked, jti), nil
+}
+
+type fakeUserStorage struct {
+	UserStorage
+
+	users          map[uuid.UUID]*models.User
+	lockedUntil    map[uuid.UUID]time.Time
+	passwordHashes map[uuid.UUID]string
+	confirmed      []uuid.UUID
+}
+
+func (f *fakeUserStorage) GetUserByEmail(_ context.Context, email string) (*models.User, error) {
+	for _, user := range f.users {
+		if validation.NormalizeEmail(user.Email) == email {
+			return user, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (f *fakeUserStorage) GetUserByID(_ context.Context, userID uuid.UUID) (*models.User, error) {
+	return f.users[userID], nil
+}
+
+func (f *fakeUserStorage) UpdatePassword(_ context.Context, userID uuid.UUID, passwordHash string) error {
+	if f.passwordHashes == nil {
+		f.passwordHashes = make(map[uuid.UUID]string)
+	}
+	f.passwordHashes[userID] = passwordHash
+	return nil
+}
+
+func (f *fakeUserStorage) ConfirmExternalEmail(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	f.confirmed = append(f.confirmed, userID)
+	return f.UpdatePassword(ctx, userID, passwordHash)
+}
+
+func (f *fakeUserStorage) SetLockedUntil(_ context.Context, userID uuid.UUID, lockedUntil time.Time) error {
+	if f.lockedUntil == nil {
+		f.lockedUntil = make(map[uuid.UUID]time.Time)
+	}
+	f.lockedUntil[userID] = lockedUntil
+	return nil
+}
+
+type fakeRedis struct {
+	RedisRepositories
+
+	values    map[string]string
+	counters  map[string]int64
+	locks     map[string]time.Duration
+	cooldowns map[string]bool
+	deleted   []string
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		values:    make(map[string]string),
+		counters:  make(map[string]int64),
+		locks:     make(map[string]time.Duration),
+		cooldowns: make(map[string]bool),
+	}
+}
+
+func (f *fakeRedis) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	f.values[key], _ = value.(string)
+	return nil
+}
+
+func (f *fakeRedis) Get(_ context.Context, key string) (string, error) {
+	value, ok := f.values[key]
+	if !ok {
+		return "", errors.New("redis: nil")
+	}
+	return value, nil
+}
+
+func (f *fakeRedis) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(f.values, key)
+	}
+	f.deleted = append(f.deleted, keys...)
+	return nil
+}
+
+func (f *fakeRedis) AcquireCooldown(_ context.Context, key string, _ time.Duration) (bool, error) {
+	if f.cooldowns[key] {
+		return false, nil
+	}
+	f.cooldowns[key] = true
+	return true, nil
+}
+
+func (f *fakeRedis) Increment(_ context.Context, key string, _ time.Duration) (int64, error) {
+	f.counters[key]++
+	return f.counters[key], nil
+}
+
+func (f *fakeRedis) Counter(_ context.Context, key string) (int64, error) {
+	return f.counters[key], nil
+}
+
+func (f *fakeRedis) ResetCounter(_ context.Context, key string) error {
+	delete(f.counters, key)
+	return nil
+}
+
+func (f *fakeRedis) IsLocked(_ context.Context, key string) (bool, error) {
+	_, ok := f.locks[key]
+	return ok, nil
+}
+
+func (f *fakeRedis) Lock(_ context.Context, key string, ttl time.Duration) error {
+	f.locks[key] = ttl
+	return nil
+}
+
+type fakeAuditLog struct {
+	AuthEventStorage
+
+	events []models.AuthEvent
+}
+
+func (f *fakeAuditLog) SaveAuthEvent(_ context.Context, event *models.AuthEvent) error {
+	f.events = append(f.events, *event)
+	return nil
+}
+
+type fakeBroker struct {
+	messages []interface{}
+}
+
+func (f *fakeBroker) SendMessage(_ context.Context, _ string, value interface{}) error {
+	f.messages = append(f.messages, value)
+	return nil
+}
+
+// newTestService returns a service whose dependencies are fakes. Tests replace
+// the ones they need to control.
+func newTestService(t *testing.T) *AuthenticationService {
+	t.Helper()
+
+	keyring, err := jwt.LoadKeyring(t.TempDir(), "test", true)
+	if err != nil {
+		t.Fatalf("failed to create keyring: %v", err)
+	}
+
+	return &AuthenticationService{
+		userStorage:     &fakeUserStorage{users: make(map[uuid.UUID]*models.User)},
+		sessionStorage:  &fakeSessionStorage{},
+		roleStorage:     &fakeRoleStorage{},
+		auditLog:        &fakeAuditLog{},
+		keyring:         keyring,
+		tokenIssuer:     "test-issuer",
+		tokenAudience:   "test-audience",
+		oauthAudience:   "test-oauth-audience",
+		logger:          &logger.Logger{Logger: zap.NewNop()},
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 24 * time.Hour,
+		KafkapProducer:  &fakeBroker{},
+		userEvents:      &fakeBroker{},
+		authEvents:      &fakeBroker{},
+		redisClient:     newFakeRedis(),
+		revocations:     &fakeRevocations{},
+	}
+}
+
+// signIn starts a session of user the way a login does and returns its access
+// token.
+func signIn(t *testing.T, s *AuthenticationService, user *models.User) string {
+	t.Helper()
+
+	s.userStorage.(*fakeUserStorage).users[user.ID] = user
+
+	accessToken, _, err := s.generateAccessToken(context.Background(), user, uuid.New())
+	if err != nil {
+		t.Fatalf("failed to generate access token: %v", err)
+	}
+
+	claims, err := jwt.ParseToken(accessToken, s.keyring, s.tokenAudience)
+	if err != nil {
+		t.Fatalf("failed to parse access token: %v", err)
+	}
+	s.redisClient.(*fakeRedis).values[claims.SessionID.String()] = accessToken
+
+	return accessToken
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/pkg/jwt"
+
+	"github.com/google/uuid"
+)
+
+// impersonate signs the user in as an admin acting on their behalf.
+func impersonate(t *testing.T, s *AuthenticationService, user *models.User) string {
+	t.Helper()
+
+	s.userStorage.(*fakeUserStorage).users[user.ID] = user
+
+	sessionID := uuid.New()
+	accessToken, _, err := jwt.GenerateToken(s.keyring, jwt.TokenClaims{
+		UserID:    user.ID,
+		SessionID: sessionID,
+		Email:     user.Email,
+		Issuer:    s.tokenIssuer,
+		Audience:  s.tokenAudience,
+		ActorID:   uuid.New(),
+	}, s.AccessTokenTTL)
+	if err != nil {
+		t.Fatalf("failed to generate access token: %v", err)
+	}
+	s.redisClient.(*fakeRedis).values[sessionID.String()] = accessToken
+
+	return accessToken
+}
+
+func TestAccountOwnerOperationsRejectImpersonation(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(s *AuthenticationService, accessToken string) error
+	}{
+		{
+			name: "LogoutAll",
+			call: func(s *AuthenticationService, accessToken string) error {
+				_, err := s.LogoutAll(context.Background(), accessToken, models.ClientInfo{})
+				return err
+			},
+		},
+		{
+			name: "RevokeAllOtherSessions",
+			call: func(s *AuthenticationService, accessToken string) error {
+				_, err := s.RevokeAllOtherSessions(context.Background(), accessToken)
+				return err
+			},
+		},
+		{
+			name: "ExportMyData",
+			call: func(s *AuthenticationService, accessToken string) error {
+				_, err := s.ExportMyData(context.Background(), accessToken)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			user := &models.User{ID: uuid.New(), Email: "user@example.com"}
+
+			accessToken := impersonate(t, s, user)
+			if err := tt.call(s, accessToken); !errors.Is(err, domain.ErrImpersonationNotAllowed) {
+				t.Fatalf("%s returned %v, want %v", tt.name, err, domain.ErrImpersonationNotAllowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"Project/AuthService/internal/domain/message"
+	"Project/AuthService/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+func TestRecordLoginFailure(t *testing.T) {
+	protection := LoginProtectionSettings{
+		MaxAccountFailures: 3,
+		FailureWindow:      15 * time.Minute,
+		BaseLockout:        time.Minute,
+		MaxLockout:         10 * time.Minute,
+	}
+
+	tests := []struct {
+		name       string
+		protection LoginProtectionSettings
+		// previousFailures is the count before this failure.
+		previousFailures int64
+		unknownEmail     bool
+		wantLockout      time.Duration
+		wantAlert        bool
+	}{
+		{
+			name:             "below the threshold",
+			protection:       protection,
+			previousFailures: 1,
+		},
+		{
+			name:             "reaching the threshold locks for the base lockout",
+			protection:       protection,
+			previousFailures: 2,
+			wantLockout:      time.Minute,
+			wantAlert:        true,
+		},
+		{
+			name:             "every further failure doubles the lockout",
+			protection:       protection,
+			previousFailures: 3,
+			wantLockout:      2 * time.Minute,
+		},
+		{
+			name:             "doubling continues",
+			protection:       protection,
+			previousFailures: 5,
+			wantLockout:      8 * time.Minute,
+		},
+		{
+			name:             "lockout is capped",
+			protection:       protection,
+			previousFailures: 6,
+			wantLockout:      10 * time.Minute,
+		},
+		{
+			name:             "overflowing shift is capped",
+			protection:       protection,
+			previousFailures: 100,
+			wantLockout:      10 * time.Minute,
+		},
+		{
+			name: "uncapped lockout stops doubling",
+			protection: LoginProtectionSettings{
+				MaxAccountFailures: 3,
+				FailureWindow:      15 * time.Minute,
+				BaseLockout:        time.Second,
+			},
+			previousFailures: 100,
+			wantLockout:      time.Second << maxLockoutDoublings,
+		},
+		{
+			name: "uncapped lockout saturates instead of overflowing",
+			protection: LoginProtectionSettings{
+				MaxAccountFailures: 3,
+				FailureWindow:      15 * time.Minute,
+				BaseLockout:        time.Minute,
+			},
+			previousFailures: 100,
+			wantLockout:      math.MaxInt64,
+		},
+		{
+			name:             "unknown email is locked without a user",
+			protection:       protection,
+			previousFailures: 2,
+			unknownEmail:     true,
+			wantLockout:      time.Minute,
+		},
+		{
+			name:             "no threshold never locks",
+			protection:       LoginProtectionSettings{BaseLockout: time.Minute},
+			previousFailures: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t)
+			s.loginProtection = tt.protection
+
+			const (
+				email = "user@example.com"
+				ip    = "203.0.113.7"
+			)
+
+			redis := s.redisClient.(*fakeRedis)
+			redis.counters[accountFailuresKey(email)] = tt.previousFailures
+
+			var user *models.User
+			if !tt.unknownEmail {
+				user = &models.User{ID: uuid.New(), Email: email}
+			}
+
+			before := time.Now()
+			s.recordLoginFailure(ctx, email, ip, user)
+
+			if got := redis.counters[accountFailuresKey(email)]; got != tt.previousFailures+1 {
+				t.Errorf("account failures = %d, want %d", got, tt.previousFailures+1)
+			}
+			if got := redis.counters[ipFailuresKey(ip)]; got != 1 {
+				t.Errorf("ip failures = %d, want 1", got)
+			}
+
+			if got := redis.locks[accountFailuresKey(email)]; got != tt.wantLockout {
+				t.Errorf("lockout = %s, want %s", got, tt.wantLockout)
+			}
+
+			lockedUntil, locked := s.userStorage.(*fakeUserStorage).lockedUntil[uuidOf(user)]
+			wantLocked := tt.wantLockout > 0 && user != nil
+			if locked != wantLocked {
+				t.Fatalf("user locked = %t, want %t", locked, wantLocked)
+			}
+			if locked && (lockedUntil.Before(before.Add(tt.wantLockout)) || lockedUntil.After(time.Now().Add(tt.wantLockout))) {
+				t.Errorf("locked until %s, want %s from now", lockedUntil, tt.wantLockout)
+			}
+
+			alerted := false
+			for _, msg := range s.KafkapProducer.(*fakeBroker).messages {
+				if event, ok := msg.(message.SecurityEventMessage); ok && event.Event == message.EventAccountLocked {
+					alerted = true
+				}
+			}
+			if alerted != tt.wantAlert {
+				t.Errorf("lockout alert sent = %t, want %t", alerted, tt.wantAlert)
+			}
+		})
+	}
+}
+
+func uuidOf(user *models.User) uuid.UUID {
+	if user == nil {
+		return uuid.Nil
+	}
+	return user.ID
+}
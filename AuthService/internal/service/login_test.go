@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	passwordhash "Project/AuthService/pkg/password"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoginRehashesPassword(t *testing.T) {
+	argon2 := passwordhash.NewArgon2id(passwordhash.Argon2Params{Time: 1, Memory: 64, Threads: 1, SaltLength: 8, KeyLength: 16})
+	legacy := passwordhash.NewBcrypt(bcrypt.MinCost)
+	hasher := passwordhash.NewHasher(argon2, legacy)
+
+	hash := func(t *testing.T, algorithm passwordhash.Algorithm) string {
+		t.Helper()
+		encoded, err := algorithm.Hash("correct horse")
+		if err != nil {
+			t.Fatalf("failed to hash password: %v", err)
+		}
+		return encoded
+	}
+
+	tests := []struct {
+		name       string
+		storedHash func(t *testing.T) string
+		password   string
+		wantErr    error
+		wantRehash bool
+	}{
+		{
+			name:       "legacy hash is upgraded",
+			storedHash: func(t *testing.T) string { return hash(t, legacy) },
+			password:   "correct horse",
+			wantRehash: true,
+		},
+		{
+			name: "outdated parameters are upgraded",
+			storedHash: func(t *testing.T) string {
+				return hash(t, passwordhash.NewArgon2id(passwordhash.Argon2Params{Time: 2, Memory: 64, Threads: 1, SaltLength: 8, KeyLength: 16}))
+			},
+			password:   "correct horse",
+			wantRehash: true,
+		},
+		{
+			name:       "current hash is kept",
+			storedHash: func(t *testing.T) string { return hash(t, argon2) },
+			password:   "correct horse",
+		},
+		{
+			name:       "wrong password never rehashes",
+			storedHash: func(t *testing.T) string { return hash(t, legacy) },
+			password:   "battery staple",
+			wantErr:    domain.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			s.passwordHasher = hasher
+			s.mfaStorage = &fakeMFAStorage{}
+
+			users := s.userStorage.(*fakeUserStorage)
+			user := &models.User{ID: uuid.New(), Email: "user@example.com", EmailConfirmed: true, PasswordHash: tt.storedHash(t)}
+			users.users[user.ID] = user
+
+			_, err := s.Login(context.Background(), user.Email, tt.password, models.ClientInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+
+			saved, rehashed := users.passwordHashes[user.ID]
+			if rehashed != tt.wantRehash {
+				t.Fatalf("password rehashed = %t, want %t", rehashed, tt.wantRehash)
+			}
+			if !rehashed {
+				return
+			}
+
+			if !strings.HasPrefix(saved, "$argon2id$v=19$m=64,t=1,p=1$") {
+				t.Errorf("saved hash %q, want one of the preferred algorithm", saved)
+			}
+			if err := hasher.Verify(tt.password, saved); err != nil {
+				t.Errorf("saved hash does not verify the password: %v", err)
+			}
+			if hasher.NeedsRehash(saved) {
+				t.Errorf("saved hash needs another rehash")
+			}
+		})
+	}
+}
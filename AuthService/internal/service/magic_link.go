@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/message"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/internal/validation"
+	"Project/AuthService/pkg/jwt"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMagicLinkTTL      = 15 * time.Minute
+	magicLinkRequestCooldown = time.Minute
+)
+
+// MagicLinkSettings configures passwordless login. URL is the page of the
+// frontend the emailed link opens; it gets the token as the "token" query
+// parameter and has to POST it to ConsumeMagicLink, so that mail scanners
+// following the link cannot use it up.
+type MagicLinkSettings struct {
+	Enabled bool
+	TTL     time.Duration
+	URL     string
+}
+
+// RequestMagicLink emails a single-use login link to the user. Unknown emails
+// are not reported to the caller so the endpoint cannot be used to find accounts.
+func (s *AuthenticationService) RequestMagicLink(ctx context.Context, email string) error {
+	if !s.magicLink.Enabled {
+		return domain.ErrMagicLinkDisabled
+	}
+
+	email = validation.NormalizeEmail(email)
+
+	acquired, err := s.redisClient.AcquireCooldown(ctx, "magic_link:"+email, magicLinkRequestCooldown)
+	if err != nil {
+		return fmt.Errorf("failed to check magic link cooldown: %w", err)
+	}
+
+	if !acquired {
+		return domain.ErrMagicLinkCooldown
+	}
+
+	user, err := s.userStorage.GetUserByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		s.logger.Info("Magic link requested for unknown email", zap.String("email", email))
+		return nil
+	}
+
+	if user.Suspended() {
+		return nil
+	}
+
+	token, err := jwt.GenerateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate magic link token: %w", err)
+	}
+
+	ttl := s.magicLink.TTL
+	if ttl <= 0 {
+		ttl = defaultMagicLinkTTL
+	}
+
+	if err := s.userStorage.SaveMagicLinkToken(ctx, user.ID, jwt.HashToken(token), time.Now().Add(ttl)); err != nil {
+		return fmt.Errorf("failed to save magic link token: %w", err)
+	}
+
+	link, err := url.Parse(s.magicLink.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse magic link URL: %w", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	message := message.ConfirmationMessage{
+		ToEmail: user.Email,
+		Subject: "Your login link",
+		Body:    fmt.Sprintf("Open this link to log in: %s\nIt works once and expires in %s. If you did not request it, ignore this email.", link, ttl),
+	}
+
+	if err := s.KafkapProducer.SendMessage(ctx, user.Email, message); err != nil {
+		return fmt.Errorf("failed to send magic link to kafka: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeMagicLink logs in the owner of a token sent by RequestMagicLink. Users
+// with two-factor authentication still have to finish the login with LoginMFA.
+func (s *AuthenticationService) ConsumeMagicLink(ctx context.Context, token string, client models.ClientInfo) (result *models.LoginResult, err error) {
+	event := newAuthEvent(models.AuthEventLoginMagicLink, client)
+	defer func() { s.recordLoginEvent(ctx, event, result, err) }()
+
+	if !s.magicLink.Enabled {
+		return nil, domain.ErrMagicLinkDisabled
+	}
+
+	userID, err := s.userStorage.ConsumeMagicLinkToken(ctx, jwt.HashToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume magic link: %w", err)
+	}
+	event.UserID = userID
+
+	user, err := s.userStorage.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	event.Email = validation.NormalizeEmail(user.Email)
+
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return nil, domain.ErrTooManyLoginAttempts
+	}
+
+	return s.completeLogin(ctx, user, client)
+}
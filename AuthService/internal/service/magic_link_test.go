@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/message"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/pkg/jwt"
+
+	"github.com/google/uuid"
+)
+
+type magicLinkToken struct {
+	userID    uuid.UUID
+	expiresAt time.Time
+	used      bool
+}
+
+// magicLinkUserStorage adds the magic link tokens to fakeUserStorage, consuming
+// them the way the SQL does.
+type magicLinkUserStorage struct {
+	*fakeUserStorage
+
+	tokens map[string]*magicLinkToken
+}
+
+func (f *magicLinkUserStorage) SaveMagicLinkToken(_ context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	for _, token := range f.tokens {
+		if token.userID == userID {
+			token.used = true
+		}
+	}
+	f.tokens[tokenHash] = &magicLinkToken{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (f *magicLinkUserStorage) ConsumeMagicLinkToken(_ context.Context, tokenHash string) (uuid.UUID, error) {
+	token, ok := f.tokens[tokenHash]
+	if !ok || token.used {
+		return uuid.Nil, domain.ErrMagicLinkInvalid
+	}
+	if time.Now().After(token.expiresAt) {
+		return uuid.Nil, domain.ErrMagicLinkExpired
+	}
+	token.used = true
+	return token.userID, nil
+}
+
+func newMagicLinkTestService(t *testing.T) (*AuthenticationService, *magicLinkUserStorage) {
+	t.Helper()
+
+	s := newTestService(t)
+	s.mfaStorage = &fakeMFAStorage{}
+	s.magicLink = MagicLinkSettings{Enabled: true, TTL: time.Minute, URL: "https://example.com/login/magic"}
+
+	users := &magicLinkUserStorage{
+		fakeUserStorage: s.userStorage.(*fakeUserStorage),
+		tokens:          make(map[string]*magicLinkToken),
+	}
+	s.userStorage = users
+
+	return s, users
+}
+
+// sentMagicLinkToken returns the token of the last link emailed by RequestMagicLink.
+func sentMagicLinkToken(t *testing.T, s *AuthenticationService) string {
+	t.Helper()
+
+	messages := s.KafkapProducer.(*fakeBroker).messages
+	if len(messages) == 0 {
+		t.Fatalf("no magic link was sent")
+	}
+	sent, ok := messages[len(messages)-1].(message.ConfirmationMessage)
+	if !ok {
+		t.Fatalf("sent %T, want a confirmation message", messages[len(messages)-1])
+	}
+
+	for _, field := range strings.Fields(sent.Body) {
+		link, err := url.Parse(field)
+		if err == nil && link.Query().Has("token") {
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("no link in %q", sent.Body)
+	return ""
+}
+
+func TestRequestMagicLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled bool
+		// email is requested after the user user@example.com was created.
+		email     string
+		suspended bool
+		requests  int
+		wantErr   error
+		wantSent  bool
+	}{
+		{
+			name:     "link is emailed",
+			email:    " User@Example.com",
+			requests: 1,
+			wantSent: true,
+		},
+		{
+			name:     "second request within the cooldown",
+			email:    "user@example.com",
+			requests: 2,
+			wantErr:  domain.ErrMagicLinkCooldown,
+			wantSent: true,
+		},
+		{
+			name:     "unknown email is not reported",
+			email:    "nobody@example.com",
+			requests: 1,
+		},
+		{
+			name:      "suspended user gets no link",
+			email:     "user@example.com",
+			suspended: true,
+			requests:  1,
+		},
+		{
+			name:     "disabled",
+			disabled: true,
+			email:    "user@example.com",
+			requests: 1,
+			wantErr:  domain.ErrMagicLinkDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, users := newMagicLinkTestService(t)
+			s.magicLink.Enabled = !tt.disabled
+
+			user := &models.User{ID: uuid.New(), Email: "user@example.com", EmailConfirmed: true}
+			if tt.suspended {
+				suspendedAt := time.Now()
+				user.SuspendedAt = &suspendedAt
+			}
+			users.users[user.ID] = user
+
+			var err error
+			for range tt.requests {
+				err = s.RequestMagicLink(context.Background(), tt.email)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RequestMagicLink() error = %v, want %v", err, tt.wantErr)
+			}
+
+			sent := len(s.KafkapProducer.(*fakeBroker).messages)
+			if (sent > 0) != tt.wantSent {
+				t.Fatalf("links sent = %d, want sent %t", sent, tt.wantSent)
+			}
+			if !tt.wantSent {
+				return
+			}
+			if sent != 1 {
+				t.Errorf("links sent = %d, want 1", sent)
+			}
+
+			token, ok := users.tokens[jwt.HashToken(sentMagicLinkToken(t, s))]
+			if !ok || token.userID != user.ID {
+				t.Errorf("emailed token is not saved for the user: %+v", token)
+			}
+		})
+	}
+}
+
+func TestConsumeMagicLink(t *testing.T) {
+	tests := []struct {
+		name string
+		// token is "sent" for the emailed token or any other value to send as is.
+		token       string
+		expired     bool
+		consumes    int
+		lockedUntil time.Duration
+		wantErr     error
+	}{
+		{
+			name:     "link logs in",
+			token:    "sent",
+			consumes: 1,
+		},
+		{
+			name:     "link works once",
+			token:    "sent",
+			consumes: 2,
+			wantErr:  domain.ErrMagicLinkInvalid,
+		},
+		{
+			name:     "expired link",
+			token:    "sent",
+			expired:  true,
+			consumes: 1,
+			wantErr:  domain.ErrMagicLinkExpired,
+		},
+		{
+			name:     "unknown link",
+			token:    "guess",
+			consumes: 1,
+			wantErr:  domain.ErrMagicLinkInvalid,
+		},
+		{
+			name:        "locked account",
+			token:       "sent",
+			consumes:    1,
+			lockedUntil: time.Minute,
+			wantErr:     domain.ErrTooManyLoginAttempts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, users := newMagicLinkTestService(t)
+
+			user := &models.User{ID: uuid.New(), Email: "user@example.com", EmailConfirmed: true}
+			if tt.lockedUntil != 0 {
+				lockedUntil := time.Now().Add(tt.lockedUntil)
+				user.LockedUntil = &lockedUntil
+			}
+			users.users[user.ID] = user
+
+			if err := s.RequestMagicLink(ctx, user.Email); err != nil {
+				t.Fatalf("failed to request magic link: %v", err)
+			}
+			if tt.expired {
+				for _, token := range users.tokens {
+					token.expiresAt = time.Now().Add(-time.Second)
+				}
+			}
+			token := tt.token
+			if token == "sent" {
+				token = sentMagicLinkToken(t, s)
+			}
+
+			var (
+				result *models.LoginResult
+				err    error
+			)
+			for range tt.consumes {
+				result, err = s.ConsumeMagicLink(ctx, token, models.ClientInfo{})
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ConsumeMagicLink() error = %v, want %v", err, tt.wantErr)
+			}
+
+			events := s.auditLog.(*fakeAuditLog).events
+			if len(events) != tt.consumes || events[len(events)-1].Event != models.AuthEventLoginMagicLink {
+				t.Errorf("audit log = %+v, want %d magic link logins", events, tt.consumes)
+			}
+			if err != nil {
+				return
+			}
+
+			if result.User.ID != user.ID || result.AccessToken == "" || result.RefreshToken == "" {
+				t.Errorf("login result %+v, want a session of the user", result)
+			}
+		})
+	}
+}
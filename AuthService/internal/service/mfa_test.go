@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/message"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/pkg/encryption"
+	"Project/AuthService/pkg/totp"
+
+	"github.com/google/uuid"
+)
+
+// fakeMFAStorage accepts steps and recovery codes the way the SQL does: a step
+// only when it is newer than the last one and a recovery code only once.
+type fakeMFAStorage struct {
+	MFAStorage
+
+	mfa           *models.MFA
+	recoveryCodes map[string]bool
+}
+
+func (f *fakeMFAStorage) GetMFA(_ context.Context, _ uuid.UUID) (*models.MFA, error) {
+	if f.mfa == nil {
+		return nil, domain.ErrMFANotEnabled
+	}
+	return f.mfa, nil
+}
+
+func (f *fakeMFAStorage) UseStep(_ context.Context, _ uuid.UUID, step int64) error {
+	if f.mfa.EnabledAt == nil || step <= f.mfa.LastUsedStep {
+		return domain.ErrInvalidMFACode
+	}
+	f.mfa.LastUsedStep = step
+	return nil
+}
+
+func (f *fakeMFAStorage) UseRecoveryCode(_ context.Context, _ uuid.UUID, codeHash string) error {
+	if !f.recoveryCodes[codeHash] {
+		return domain.ErrInvalidMFACode
+	}
+	f.recoveryCodes[codeHash] = false
+	return nil
+}
+
+func TestVerifySecondFactor(t *testing.T) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	cipher, err := encryption.NewCipher(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatalf("failed to create cipher: %v", err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("failed to generate secret: %v", err)
+	}
+	secretEncrypted, err := cipher.Encrypt(secret)
+	if err != nil {
+		t.Fatalf("failed to encrypt secret: %v", err)
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("failed to generate recovery codes: %v", err)
+	}
+
+	currentCode := func(t *testing.T) string {
+		t.Helper()
+		code, err := totp.GenerateCode(secret, time.Now())
+		if err != nil {
+			t.Fatalf("failed to generate code: %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name    string
+		pending bool
+		// codes are presented one after another.
+		codes     func(t *testing.T) []string
+		wantErr   []error
+		wantAlert bool
+	}{
+		{
+			name:    "current code",
+			codes:   func(t *testing.T) []string { return []string{currentCode(t)} },
+			wantErr: []error{nil},
+		},
+		{
+			name: "code replayed within its step",
+			codes: func(t *testing.T) []string {
+				code := currentCode(t)
+				return []string{code, code}
+			},
+			wantErr: []error{nil, domain.ErrInvalidMFACode},
+		},
+		{
+			name:    "wrong code",
+			codes:   func(t *testing.T) []string { return []string{"000000"} },
+			wantErr: []error{domain.ErrInvalidMFACode},
+		},
+		{
+			name:      "recovery code is used once",
+			codes:     func(t *testing.T) []string { return []string{codes[0], codes[0]} },
+			wantErr:   []error{nil, domain.ErrInvalidMFACode},
+			wantAlert: true,
+		},
+		{
+			name: "recovery code in upper case without the dash",
+			codes: func(t *testing.T) []string {
+				return []string{" " + strings.ToUpper(strings.ReplaceAll(codes[1], "-", "")) + " "}
+			},
+			wantErr:   []error{nil},
+			wantAlert: true,
+		},
+		{
+			name:    "pending secret",
+			pending: true,
+			codes:   func(t *testing.T) []string { return []string{currentCode(t)} },
+			wantErr: []error{domain.ErrMFANotEnabled},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t)
+			s.secretCipher = cipher
+
+			user := &models.User{ID: uuid.New(), Email: "user@example.com"}
+			s.userStorage.(*fakeUserStorage).users[user.ID] = user
+
+			mfa := &models.MFA{UserID: user.ID, SecretEncrypted: secretEncrypted}
+			if !tt.pending {
+				enabledAt := time.Now().Add(-time.Hour)
+				mfa.EnabledAt = &enabledAt
+			}
+			recoveryCodes := make(map[string]bool)
+			for _, hash := range hashes {
+				recoveryCodes[hash] = true
+			}
+			s.mfaStorage = &fakeMFAStorage{mfa: mfa, recoveryCodes: recoveryCodes}
+
+			for i, code := range tt.codes(t) {
+				if err := s.verifySecondFactor(ctx, user.ID, code); !errors.Is(err, tt.wantErr[i]) {
+					t.Errorf("code %d: verifySecondFactor() error = %v, want %v", i, err, tt.wantErr[i])
+				}
+			}
+
+			alerted := slices.ContainsFunc(s.KafkapProducer.(*fakeBroker).messages, func(msg interface{}) bool {
+				event, ok := msg.(message.SecurityEventMessage)
+				return ok && event.Event == message.EventRecoveryCodeUsed
+			})
+			if alerted != tt.wantAlert {
+				t.Errorf("recovery code alert sent = %t, want %t", alerted, tt.wantAlert)
+			}
+		})
+	}
+}
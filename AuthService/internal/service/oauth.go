@@ -0,0 +1,599 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/pkg/jwt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeRefreshToken      = "refresh_token"
+
+	oauthCodeTTL = 2 * time.Minute
+)
+
+// OAuthScopes are the scopes third-party clients can be registered for.
+var OAuthScopes = []string{"profile", "email", "posts:read", "posts:write"}
+
+type OAuthStorage interface {
+	CreateClient(ctx context.Context, client *models.OAuthClient) error
+	GetClient(ctx context.Context, clientID string) (*models.OAuthClient, error)
+	SaveAuthorizationCode(ctx context.Context, code *models.OAuthAuthorizationCode) error
+	ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*models.OAuthAuthorizationCode, error)
+	GetConsent(ctx context.Context, userID uuid.UUID, clientID string) (*models.OAuthConsent, error)
+	SaveConsent(ctx context.Context, userID uuid.UUID, clientID string, scopes []string) error
+	RevokeConsent(ctx context.Context, userID uuid.UUID, clientID string) error
+	CreateGrant(ctx context.Context, grant *models.OAuthGrant, refreshTokenHash string) error
+	GetGrant(ctx context.Context, grantID uuid.UUID) (*models.OAuthGrant, error)
+	GetGrantByRefreshToken(ctx context.Context, refreshTokenHash string) (*models.OAuthGrant, error)
+	RotateRefreshToken(ctx context.Context, oldHash, newHash string, newExpiresAt time.Time) (*models.OAuthGrant, error)
+	RevokeGrant(ctx context.Context, grantID uuid.UUID) error
+}
+
+// RegisterOAuthClient registers a client owned by the calling user. The client
+// secret of confidential clients is returned only once.
+func (s *AuthenticationService) RegisterOAuthClient(ctx context.Context, accessToken string, client *models.OAuthClient, confidential bool) (*models.OAuthClient, string, error) {
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := validateOAuthClient(client, confidential); err != nil {
+		return nil, "", err
+	}
+
+	client.ID = uuid.NewString()
+	client.OwnerUserID = &claims.UserID
+
+	var secret string
+	if confidential {
+		secret, err = jwt.GenerateOpaqueToken()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate client secret: %w", err)
+		}
+		client.SecretHash = jwt.HashToken(secret)
+	}
+
+	if err := s.oauthStorage.CreateClient(ctx, client); err != nil {
+		return nil, "", fmt.Errorf("failed to create oauth client: %w", err)
+	}
+
+	s.logger.Info("OAuth client registered", zap.String("clientID", client.ID), zap.String("ownerID", claims.UserID.String()))
+	return client, secret, nil
+}
+
+func validateOAuthClient(client *models.OAuthClient, confidential bool) error {
+	validationErr := &domain.ValidationError{}
+
+	if strings.TrimSpace(client.Name) == "" {
+		validationErr.Add("name", "must not be empty")
+	}
+
+	if len(client.GrantTypes) == 0 {
+		validationErr.Add("grant_types", "must not be empty")
+	}
+	for _, grantType := range client.GrantTypes {
+		switch grantType {
+		case GrantTypeAuthorizationCode:
+		case GrantTypeClientCredentials:
+			if !confidential {
+				validationErr.Add("grant_types", "client_credentials requires a confidential client")
+			}
+		case GrantTypeRefreshToken:
+			if !slices.Contains(client.GrantTypes, GrantTypeAuthorizationCode) {
+				validationErr.Add("grant_types", "refresh_token requires authorization_code")
+			}
+		default:
+			validationErr.Add("grant_types", fmt.Sprintf("unsupported grant type %q", grantType))
+		}
+	}
+
+	if slices.Contains(client.GrantTypes, GrantTypeAuthorizationCode) && len(client.RedirectURIs) == 0 {
+		validationErr.Add("redirect_uris", "must not be empty for the authorization_code grant")
+	}
+	for _, redirectURI := range client.RedirectURIs {
+		if err := validateRedirectURI(redirectURI); err != nil {
+			validationErr.Add("redirect_uris", err.Error())
+		}
+	}
+
+	if len(client.Scopes) == 0 {
+		validationErr.Add("scopes", "must not be empty")
+	}
+	for _, scope := range client.Scopes {
+		if !slices.Contains(OAuthScopes, scope) {
+			validationErr.Add("scopes", fmt.Sprintf("unknown scope %q", scope))
+		}
+	}
+
+	if len(validationErr.Violations) > 0 {
+		return validationErr
+	}
+
+	return nil
+}
+
+// validateRedirectURI accepts absolute URLs without a fragment. Plain HTTP is
+// only allowed for loopback addresses used by native apps (RFC 8252).
+func validateRedirectURI(redirectURI string) error {
+	u, err := url.Parse(redirectURI)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute URL", redirectURI)
+	}
+
+	if u.Fragment != "" {
+		return fmt.Errorf("%q must not have a fragment", redirectURI)
+	}
+
+	loopback := u.Hostname() == "localhost" || u.Hostname() == "127.0.0.1" || u.Hostname() == "::1"
+	if u.Scheme != "https" && !(u.Scheme == "http" && loopback) {
+		return fmt.Errorf("%q must use https", redirectURI)
+	}
+
+	return nil
+}
+
+// AuthorizeOAuthClient handles an authorization request of the logged in user.
+// Without a consent covering the requested scopes the user has to approve them
+// first, after that the authorization code is returned in the redirect URI.
+func (s *AuthenticationService) AuthorizeOAuthClient(ctx context.Context, accessToken string, req models.OAuthAuthorizationRequest, approve bool) (*models.OAuthAuthorization, error) {
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.oauthStorage.GetClient(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthClientNotFound) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "unknown client_id")
+		}
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+
+	if !slices.Contains(client.GrantTypes, GrantTypeAuthorizationCode) {
+		return nil, domain.NewOAuthError(domain.OAuthUnauthorizedClient, "client may not use the authorization_code grant")
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !slices.Contains(client.RedirectURIs, redirectURI) {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "redirect_uri is not registered for the client")
+	}
+
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "PKCE with code_challenge_method S256 is required")
+	}
+
+	scopes, err := requestedScopes(req.Scope, client.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	consent, err := s.oauthStorage.GetConsent(ctx, claims.UserID, client.ID)
+	if err != nil && !errors.Is(err, domain.ErrOAuthConsentNotFound) {
+		return nil, fmt.Errorf("failed to get consent: %w", err)
+	}
+
+	consented := consent != nil && containsAll(consent.Scopes, scopes)
+	if !consented {
+		if !approve {
+			return &models.OAuthAuthorization{ConsentRequired: true, Client: client, Scopes: scopes}, nil
+		}
+
+		granted := scopes
+		if consent != nil {
+			granted = union(consent.Scopes, scopes)
+		}
+
+		if err := s.oauthStorage.SaveConsent(ctx, claims.UserID, client.ID, granted); err != nil {
+			return nil, fmt.Errorf("failed to save consent: %w", err)
+		}
+	}
+
+	code, err := jwt.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+
+	err = s.oauthStorage.SaveAuthorizationCode(ctx, &models.OAuthAuthorizationCode{
+		CodeHash:      jwt.HashToken(code),
+		ClientID:      client.ID,
+		UserID:        claims.UserID,
+		RedirectURI:   redirectURI,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     time.Now().Add(oauthCodeTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save authorization code: %w", err)
+	}
+
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redirect uri: %w", err)
+	}
+
+	query := u.Query()
+	query.Set("code", code)
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	u.RawQuery = query.Encode()
+
+	return &models.OAuthAuthorization{Client: client, Scopes: scopes, RedirectTo: u.String()}, nil
+}
+
+// OAuthToken is the token endpoint (RFC 6749 section 3.2).
+func (s *AuthenticationService) OAuthToken(ctx context.Context, req models.OAuthTokenRequest) (*models.OAuthTokenResult, error) {
+	client, err := s.authenticateOAuthClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case GrantTypeAuthorizationCode, GrantTypeClientCredentials, GrantTypeRefreshToken:
+	default:
+		return nil, domain.NewOAuthError(domain.OAuthUnsupportedGrantType, fmt.Sprintf("grant type %q is not supported", req.GrantType))
+	}
+
+	if !slices.Contains(client.GrantTypes, req.GrantType) {
+		return nil, domain.NewOAuthError(domain.OAuthUnauthorizedClient, fmt.Sprintf("client may not use the %s grant", req.GrantType))
+	}
+
+	switch req.GrantType {
+	case GrantTypeAuthorizationCode:
+		return s.exchangeAuthorizationCode(ctx, client, req)
+	case GrantTypeClientCredentials:
+		return s.issueClientCredentials(ctx, client, req.Scope)
+	default:
+		return s.refreshOAuthToken(ctx, client, req)
+	}
+}
+
+func (s *AuthenticationService) exchangeAuthorizationCode(ctx context.Context, client *models.OAuthClient, req models.OAuthTokenRequest) (*models.OAuthTokenResult, error) {
+	codeHash := jwt.HashToken(req.Code)
+
+	code, err := s.oauthStorage.ConsumeAuthorizationCode(ctx, codeHash)
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthCodeInvalid) || errors.Is(err, domain.ErrOAuthCodeReused) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, err.Error())
+		}
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	if code.ClientID != client.ID || code.RedirectURI != req.RedirectURI {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "authorization code was issued to another client or redirect_uri")
+	}
+
+	sum := sha256.Sum256([]byte(req.CodeVerifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(code.CodeChallenge)) != 1 {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "code_verifier does not match the code_challenge")
+	}
+
+	grant := &models.OAuthGrant{
+		ID:                    uuid.New(),
+		ClientID:              client.ID,
+		UserID:                &code.UserID,
+		Scopes:                code.Scopes,
+		AuthorizationCodeHash: codeHash,
+	}
+
+	var refreshToken string
+	if slices.Contains(client.GrantTypes, GrantTypeRefreshToken) {
+		refreshToken, err = jwt.GenerateOpaqueToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		}
+
+		expiresAt := time.Now().Add(s.RefreshTokenTTL)
+		grant.RefreshTokenExpiresAt = &expiresAt
+	}
+
+	if err := s.oauthStorage.CreateGrant(ctx, grant, hashIfSet(refreshToken)); err != nil {
+		return nil, fmt.Errorf("failed to create grant: %w", err)
+	}
+
+	return s.issueOAuthAccessToken(grant, grant.Scopes, refreshToken)
+}
+
+func (s *AuthenticationService) issueClientCredentials(ctx context.Context, client *models.OAuthClient, scope string) (*models.OAuthTokenResult, error) {
+	if !client.Confidential() {
+		return nil, domain.NewOAuthError(domain.OAuthUnauthorizedClient, "public clients may not use the client_credentials grant")
+	}
+
+	scopes, err := requestedScopes(scope, client.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	grant := &models.OAuthGrant{
+		ID:       uuid.New(),
+		ClientID: client.ID,
+		Scopes:   scopes,
+	}
+
+	if err := s.oauthStorage.CreateGrant(ctx, grant, ""); err != nil {
+		return nil, fmt.Errorf("failed to create grant: %w", err)
+	}
+
+	return s.issueOAuthAccessToken(grant, scopes, "")
+}
+
+func (s *AuthenticationService) refreshOAuthToken(ctx context.Context, client *models.OAuthClient, req models.OAuthTokenRequest) (*models.OAuthTokenResult, error) {
+	oldHash := jwt.HashToken(req.RefreshToken)
+
+	grant, err := s.oauthStorage.GetGrantByRefreshToken(ctx, oldHash)
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthGrantNotFound) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "refresh token is invalid")
+		}
+		return nil, fmt.Errorf("failed to get grant: %w", err)
+	}
+
+	if grant.ClientID != client.ID {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "refresh token was issued to another client")
+	}
+
+	scopes, err := requestedScopes(req.Scope, grant.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := jwt.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	grant, err = s.oauthStorage.RotateRefreshToken(ctx, oldHash, jwt.HashToken(refreshToken), time.Now().Add(s.RefreshTokenTTL))
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthGrantNotFound) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "refresh token is invalid")
+		}
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	return s.issueOAuthAccessToken(grant, scopes, refreshToken)
+}
+
+func (s *AuthenticationService) issueOAuthAccessToken(grant *models.OAuthGrant, scopes []string, refreshToken string) (*models.OAuthTokenResult, error) {
+	subject := grant.ClientID
+	if grant.UserID != nil {
+		subject = grant.UserID.String()
+	}
+
+	accessToken, expiresAt, err := jwt.GenerateOAuthToken(s.keyring, jwt.OAuthTokenClaims{
+		ID:       uuid.New(),
+		GrantID:  grant.ID,
+		ClientID: grant.ClientID,
+		Subject:  subject,
+		Scopes:   scopes,
+		Issuer:   s.tokenIssuer,
+		Audience: s.oauthAudience,
+	}, s.AccessTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	return &models.OAuthTokenResult{
+		AccessToken:  accessToken,
+		ExpiresIn:    time.Until(expiresAt).Round(time.Second),
+		RefreshToken: refreshToken,
+		Scopes:       scopes,
+	}, nil
+}
+
+// IntrospectOAuthToken implements RFC 7662. Any confidential client may
+// introspect access tokens, which is how resource servers check them; refresh
+// tokens are only described to the client they were issued to.
+func (s *AuthenticationService) IntrospectOAuthToken(ctx context.Context, clientID, clientSecret, token string) (*models.OAuthIntrospection, error) {
+	client, err := s.authenticateOAuthClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.Confidential() {
+		return nil, domain.NewOAuthError(domain.OAuthUnauthorizedClient, "public clients may not introspect tokens")
+	}
+
+	inactive := &models.OAuthIntrospection{}
+
+	if claims, err := jwt.ParseOAuthToken(token, s.keyring, s.oauthAudience); err == nil {
+		grant, err := s.oauthStorage.GetGrant(ctx, claims.GrantID)
+		if err != nil {
+			if errors.Is(err, domain.ErrOAuthGrantNotFound) {
+				return inactive, nil
+			}
+			return nil, fmt.Errorf("failed to get grant: %w", err)
+		}
+
+		if grant.RevokedAt != nil {
+			return inactive, nil
+		}
+
+		return &models.OAuthIntrospection{
+			Active:    true,
+			ID:        claims.ID.String(),
+			ClientID:  claims.ClientID,
+			Subject:   claims.Subject,
+			Scopes:    claims.Scopes,
+			Issuer:    claims.Issuer,
+			IssuedAt:  claims.IssuedAt,
+			ExpiresAt: claims.ExpiresAt,
+		}, nil
+	}
+
+	grant, err := s.oauthStorage.GetGrantByRefreshToken(ctx, jwt.HashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthGrantNotFound) {
+			return inactive, nil
+		}
+		return nil, fmt.Errorf("failed to get grant: %w", err)
+	}
+
+	if grant.ClientID != client.ID || grant.RevokedAt != nil || grant.RefreshTokenExpiresAt == nil || time.Now().After(*grant.RefreshTokenExpiresAt) {
+		return inactive, nil
+	}
+
+	introspection := &models.OAuthIntrospection{
+		Active:    true,
+		ClientID:  grant.ClientID,
+		Scopes:    grant.Scopes,
+		Issuer:    s.tokenIssuer,
+		IssuedAt:  grant.CreatedAt,
+		ExpiresAt: *grant.RefreshTokenExpiresAt,
+	}
+	if grant.UserID != nil {
+		introspection.Subject = grant.UserID.String()
+	}
+
+	return introspection, nil
+}
+
+// RevokeOAuthToken implements RFC 7009. Revoking either token of a grant
+// revokes the whole grant. Unknown tokens and tokens of other clients are
+// ignored, as the RFC asks the response not to reveal them.
+func (s *AuthenticationService) RevokeOAuthToken(ctx context.Context, clientID, clientSecret, token string) error {
+	client, err := s.authenticateOAuthClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+
+	var (
+		grant       *models.OAuthGrant
+		accessToken *jwt.OAuthTokenClaims
+	)
+	if claims, err := jwt.ParseOAuthToken(token, s.keyring, s.oauthAudience); err == nil {
+		accessToken = claims
+		grant, err = s.oauthStorage.GetGrant(ctx, claims.GrantID)
+		if err != nil && !errors.Is(err, domain.ErrOAuthGrantNotFound) {
+			return fmt.Errorf("failed to get grant: %w", err)
+		}
+	} else {
+		grant, err = s.oauthStorage.GetGrantByRefreshToken(ctx, jwt.HashToken(token))
+		if err != nil && !errors.Is(err, domain.ErrOAuthGrantNotFound) {
+			return fmt.Errorf("failed to get grant: %w", err)
+		}
+	}
+
+	if grant == nil || grant.ClientID != client.ID {
+		return nil
+	}
+
+	if err := s.oauthStorage.RevokeGrant(ctx, grant.ID); err != nil {
+		return fmt.Errorf("failed to revoke grant: %w", err)
+	}
+
+	// Resource servers check access tokens locally, so a revoked one is put on
+	// the revocation list too.
+	if accessToken != nil {
+		if err := s.revocations.Revoke(ctx, accessToken.ID.String(), accessToken.ExpiresAt); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RevokeOAuthConsent withdraws the consent the user gave the client and revokes
+// the tokens issued to it.
+func (s *AuthenticationService) RevokeOAuthConsent(ctx context.Context, accessToken, clientID string) error {
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if err := s.oauthStorage.RevokeConsent(ctx, claims.UserID, clientID); err != nil {
+		return fmt.Errorf("failed to revoke consent: %w", err)
+	}
+
+	return nil
+}
+
+// authenticateOAuthClient checks the client credentials. Public clients are
+// identified by their ID alone.
+func (s *AuthenticationService) authenticateOAuthClient(ctx context.Context, clientID, clientSecret string) (*models.OAuthClient, error) {
+	invalidClient := domain.NewOAuthError(domain.OAuthInvalidClient, "client authentication failed")
+
+	if clientID == "" {
+		return nil, invalidClient
+	}
+
+	client, err := s.oauthStorage.GetClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthClientNotFound) {
+			return nil, invalidClient
+		}
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+
+	if client.Confidential() && subtle.ConstantTimeCompare([]byte(jwt.HashToken(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, invalidClient
+	}
+
+	return client, nil
+}
+
+// requestedScopes parses a space separated scope parameter. An empty parameter
+// requests every allowed scope.
+func requestedScopes(scope string, allowed []string) ([]string, error) {
+	scopes := strings.Fields(scope)
+	if len(scopes) == 0 {
+		return allowed, nil
+	}
+
+	for _, s := range scopes {
+		if !slices.Contains(allowed, s) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidScope, fmt.Sprintf("scope %q is not allowed", s))
+		}
+	}
+
+	return scopes, nil
+}
+
+func containsAll(set, subset []string) bool {
+	for _, s := range subset {
+		if !slices.Contains(set, s) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func union(a, b []string) []string {
+	result := slices.Clone(a)
+	for _, s := range b {
+		if !slices.Contains(result, s) {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+func hashIfSet(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	return jwt.HashToken(token)
+}
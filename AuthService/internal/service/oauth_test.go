@@ -0,0 +1,595 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/pkg/jwt"
+
+	"github.com/google/uuid"
+)
+
+// fakeOAuthStorage keeps clients, codes, consents and grants in memory. Codes
+// are consumed the way the SQL does: once, and never after they expired.
+type fakeOAuthStorage struct {
+	OAuthStorage
+
+	clients  map[string]*models.OAuthClient
+	codes    map[string]*models.OAuthAuthorizationCode
+	consumed map[string]bool
+	consents map[uuid.UUID]*models.OAuthConsent
+	grants   map[uuid.UUID]*models.OAuthGrant
+	// refreshTokens maps refresh token hashes to their grant.
+	refreshTokens map[string]uuid.UUID
+}
+
+func newFakeOAuthStorage() *fakeOAuthStorage {
+	return &fakeOAuthStorage{
+		clients:       make(map[string]*models.OAuthClient),
+		codes:         make(map[string]*models.OAuthAuthorizationCode),
+		consumed:      make(map[string]bool),
+		consents:      make(map[uuid.UUID]*models.OAuthConsent),
+		grants:        make(map[uuid.UUID]*models.OAuthGrant),
+		refreshTokens: make(map[string]uuid.UUID),
+	}
+}
+
+func (f *fakeOAuthStorage) GetClient(_ context.Context, clientID string) (*models.OAuthClient, error) {
+	client, ok := f.clients[clientID]
+	if !ok {
+		return nil, domain.ErrOAuthClientNotFound
+	}
+	return client, nil
+}
+
+func (f *fakeOAuthStorage) SaveAuthorizationCode(_ context.Context, code *models.OAuthAuthorizationCode) error {
+	f.codes[code.CodeHash] = code
+	return nil
+}
+
+func (f *fakeOAuthStorage) ConsumeAuthorizationCode(_ context.Context, codeHash string) (*models.OAuthAuthorizationCode, error) {
+	code, ok := f.codes[codeHash]
+	if !ok || time.Now().After(code.ExpiresAt) {
+		return nil, domain.ErrOAuthCodeInvalid
+	}
+	if f.consumed[codeHash] {
+		return nil, domain.ErrOAuthCodeReused
+	}
+	f.consumed[codeHash] = true
+	return code, nil
+}
+
+func (f *fakeOAuthStorage) GetConsent(_ context.Context, userID uuid.UUID, clientID string) (*models.OAuthConsent, error) {
+	consent, ok := f.consents[userID]
+	if !ok || consent.ClientID != clientID {
+		return nil, domain.ErrOAuthConsentNotFound
+	}
+	return consent, nil
+}
+
+func (f *fakeOAuthStorage) SaveConsent(_ context.Context, userID uuid.UUID, clientID string, scopes []string) error {
+	f.consents[userID] = &models.OAuthConsent{UserID: userID, ClientID: clientID, Scopes: scopes}
+	return nil
+}
+
+func (f *fakeOAuthStorage) CreateGrant(_ context.Context, grant *models.OAuthGrant, refreshTokenHash string) error {
+	grant.CreatedAt = time.Now()
+	f.grants[grant.ID] = grant
+	if refreshTokenHash != "" {
+		f.refreshTokens[refreshTokenHash] = grant.ID
+	}
+	return nil
+}
+
+func (f *fakeOAuthStorage) GetGrant(_ context.Context, grantID uuid.UUID) (*models.OAuthGrant, error) {
+	grant, ok := f.grants[grantID]
+	if !ok {
+		return nil, domain.ErrOAuthGrantNotFound
+	}
+	return grant, nil
+}
+
+func (f *fakeOAuthStorage) GetGrantByRefreshToken(ctx context.Context, refreshTokenHash string) (*models.OAuthGrant, error) {
+	grantID, ok := f.refreshTokens[refreshTokenHash]
+	if !ok {
+		return nil, domain.ErrOAuthGrantNotFound
+	}
+	return f.GetGrant(ctx, grantID)
+}
+
+func (f *fakeOAuthStorage) RevokeGrant(_ context.Context, grantID uuid.UUID) error {
+	if grant, ok := f.grants[grantID]; ok {
+		now := time.Now()
+		grant.RevokedAt = &now
+	}
+	return nil
+}
+
+const testRedirectURI = "https://partner.example.com/callback"
+
+// newOAuthTestService returns a service with a confidential client "partner"
+// whose secret is "partner-secret" and a public client "mobile".
+func newOAuthTestService(t *testing.T) (*AuthenticationService, *fakeOAuthStorage) {
+	t.Helper()
+
+	s := newTestService(t)
+	storage := newFakeOAuthStorage()
+	s.oauthStorage = storage
+
+	storage.clients["partner"] = &models.OAuthClient{
+		ID:           "partner",
+		Name:         "Partner",
+		SecretHash:   jwt.HashToken("partner-secret"),
+		RedirectURIs: []string{testRedirectURI},
+		Scopes:       []string{"profile", "posts:read"},
+		GrantTypes:   []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials},
+	}
+	storage.clients["mobile"] = &models.OAuthClient{
+		ID:           "mobile",
+		Name:         "Mobile",
+		RedirectURIs: []string{"http://127.0.0.1:8765/callback"},
+		Scopes:       []string{"profile"},
+		GrantTypes:   []string{GrantTypeAuthorizationCode},
+	}
+
+	return s, storage
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func oauthErrorCode(err error) string {
+	var oauthErr *domain.OAuthError
+	if errors.As(err, &oauthErr) {
+		return oauthErr.Code
+	}
+	return ""
+}
+
+func TestAuthorizeOAuthClient(t *testing.T) {
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	valid := models.OAuthAuthorizationRequest{
+		ClientID:            "partner",
+		RedirectURI:         testRedirectURI,
+		Scope:               "posts:read",
+		State:               "xyz",
+		CodeChallenge:       codeChallenge(verifier),
+		CodeChallengeMethod: "S256",
+	}
+
+	with := func(change func(req *models.OAuthAuthorizationRequest)) models.OAuthAuthorizationRequest {
+		req := valid
+		change(&req)
+		return req
+	}
+
+	tests := []struct {
+		name           string
+		req            models.OAuthAuthorizationRequest
+		approve        bool
+		consented      []string
+		wantErrCode    string
+		wantConsent    bool
+		wantRedirected bool
+	}{
+		{
+			name:        "first request asks for consent",
+			req:         valid,
+			wantConsent: true,
+		},
+		{
+			name:           "approval issues a code",
+			req:            valid,
+			approve:        true,
+			wantRedirected: true,
+		},
+		{
+			name:           "consented scopes skip the consent screen",
+			req:            valid,
+			consented:      []string{"profile", "posts:read"},
+			wantRedirected: true,
+		},
+		{
+			name:        "new scope asks for consent again",
+			req:         with(func(req *models.OAuthAuthorizationRequest) { req.Scope = "profile posts:read" }),
+			consented:   []string{"posts:read"},
+			wantConsent: true,
+		},
+		{
+			name:        "PKCE is required",
+			req:         with(func(req *models.OAuthAuthorizationRequest) { req.CodeChallenge = "" }),
+			approve:     true,
+			wantErrCode: domain.OAuthInvalidRequest,
+		},
+		{
+			name:        "plain PKCE is rejected",
+			req:         with(func(req *models.OAuthAuthorizationRequest) { req.CodeChallengeMethod = "plain" }),
+			approve:     true,
+			wantErrCode: domain.OAuthInvalidRequest,
+		},
+		{
+			name:        "unregistered redirect_uri",
+			req:         with(func(req *models.OAuthAuthorizationRequest) { req.RedirectURI = "https://evil.example.com/callback" }),
+			approve:     true,
+			wantErrCode: domain.OAuthInvalidRequest,
+		},
+		{
+			name:        "scope the client was not registered for",
+			req:         with(func(req *models.OAuthAuthorizationRequest) { req.Scope = "posts:write" }),
+			approve:     true,
+			wantErrCode: domain.OAuthInvalidScope,
+		},
+		{
+			name:        "unknown client",
+			req:         with(func(req *models.OAuthAuthorizationRequest) { req.ClientID = "unknown" }),
+			approve:     true,
+			wantErrCode: domain.OAuthInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, storage := newOAuthTestService(t)
+			user := &models.User{ID: uuid.New(), Email: "user@example.com"}
+			accessToken := signIn(t, s, user)
+			if tt.consented != nil {
+				storage.consents[user.ID] = &models.OAuthConsent{UserID: user.ID, ClientID: tt.req.ClientID, Scopes: tt.consented}
+			}
+
+			authorization, err := s.AuthorizeOAuthClient(context.Background(), accessToken, tt.req, tt.approve)
+			if code := oauthErrorCode(err); code != tt.wantErrCode {
+				t.Fatalf("AuthorizeOAuthClient() error = %v, want %q", err, tt.wantErrCode)
+			}
+			if err != nil {
+				if len(storage.codes) != 0 {
+					t.Errorf("a code was saved for a rejected request")
+				}
+				return
+			}
+
+			if authorization.ConsentRequired != tt.wantConsent {
+				t.Errorf("consent required = %t, want %t", authorization.ConsentRequired, tt.wantConsent)
+			}
+			if redirected := authorization.RedirectTo != ""; redirected != tt.wantRedirected {
+				t.Fatalf("redirected = %t, want %t", redirected, tt.wantRedirected)
+			}
+			if !tt.wantRedirected {
+				return
+			}
+
+			redirect, err := url.Parse(authorization.RedirectTo)
+			if err != nil {
+				t.Fatalf("invalid redirect %q: %v", authorization.RedirectTo, err)
+			}
+			if redirect.Query().Get("state") != tt.req.State {
+				t.Errorf("state = %q, want %q", redirect.Query().Get("state"), tt.req.State)
+			}
+
+			code, ok := storage.codes[jwt.HashToken(redirect.Query().Get("code"))]
+			if !ok {
+				t.Fatalf("the code in the redirect was not saved")
+			}
+			if code.UserID != user.ID || code.ClientID != tt.req.ClientID || code.CodeChallenge != tt.req.CodeChallenge {
+				t.Errorf("saved code %+v does not match the request", code)
+			}
+			if consent := storage.consents[user.ID]; consent == nil || !containsAll(consent.Scopes, code.Scopes) {
+				t.Errorf("consent %+v does not cover the scopes of the code %v", consent, code.Scopes)
+			}
+		})
+	}
+}
+
+func TestExchangeAuthorizationCode(t *testing.T) {
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name string
+		// code is issued to codeClient, the token request is made by req.
+		codeClient  string
+		expired     bool
+		req         models.OAuthTokenRequest
+		exchanges   int
+		wantErrCode string
+		wantRefresh bool
+	}{
+		{
+			name:        "confidential client with the right verifier",
+			codeClient:  "partner",
+			req:         models.OAuthTokenRequest{ClientID: "partner", ClientSecret: "partner-secret", CodeVerifier: verifier, RedirectURI: testRedirectURI},
+			wantRefresh: true,
+		},
+		{
+			name:       "public client with the right verifier",
+			codeClient: "mobile",
+			req:        models.OAuthTokenRequest{ClientID: "mobile", CodeVerifier: verifier, RedirectURI: testRedirectURI},
+		},
+		{
+			name:        "wrong verifier",
+			codeClient:  "partner",
+			req:         models.OAuthTokenRequest{ClientID: "partner", ClientSecret: "partner-secret", CodeVerifier: "another-verifier-another-verifier-another", RedirectURI: testRedirectURI},
+			wantErrCode: domain.OAuthInvalidGrant,
+		},
+		{
+			name:        "missing verifier",
+			codeClient:  "partner",
+			req:         models.OAuthTokenRequest{ClientID: "partner", ClientSecret: "partner-secret", RedirectURI: testRedirectURI},
+			wantErrCode: domain.OAuthInvalidGrant,
+		},
+		{
+			name:        "another redirect_uri",
+			codeClient:  "partner",
+			req:         models.OAuthTokenRequest{ClientID: "partner", ClientSecret: "partner-secret", CodeVerifier: verifier, RedirectURI: "https://partner.example.com/other"},
+			wantErrCode: domain.OAuthInvalidGrant,
+		},
+		{
+			name:        "code of another client",
+			codeClient:  "partner",
+			req:         models.OAuthTokenRequest{ClientID: "mobile", CodeVerifier: verifier, RedirectURI: testRedirectURI},
+			wantErrCode: domain.OAuthInvalidGrant,
+		},
+		{
+			name:        "code used twice",
+			codeClient:  "partner",
+			req:         models.OAuthTokenRequest{ClientID: "partner", ClientSecret: "partner-secret", CodeVerifier: verifier, RedirectURI: testRedirectURI},
+			exchanges:   2,
+			wantErrCode: domain.OAuthInvalidGrant,
+		},
+		{
+			name:        "expired code",
+			codeClient:  "partner",
+			expired:     true,
+			req:         models.OAuthTokenRequest{ClientID: "partner", ClientSecret: "partner-secret", CodeVerifier: verifier, RedirectURI: testRedirectURI},
+			wantErrCode: domain.OAuthInvalidGrant,
+		},
+		{
+			name:        "wrong client secret",
+			codeClient:  "partner",
+			req:         models.OAuthTokenRequest{ClientID: "partner", ClientSecret: "guess", CodeVerifier: verifier, RedirectURI: testRedirectURI},
+			wantErrCode: domain.OAuthInvalidClient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, storage := newOAuthTestService(t)
+
+			expiresAt := time.Now().Add(oauthCodeTTL)
+			if tt.expired {
+				expiresAt = time.Now().Add(-time.Second)
+			}
+			storage.codes[jwt.HashToken("the-code")] = &models.OAuthAuthorizationCode{
+				CodeHash:      jwt.HashToken("the-code"),
+				ClientID:      tt.codeClient,
+				UserID:        userID,
+				RedirectURI:   testRedirectURI,
+				Scopes:        []string{"posts:read"},
+				CodeChallenge: codeChallenge(verifier),
+				ExpiresAt:     expiresAt,
+			}
+
+			req := tt.req
+			req.GrantType = GrantTypeAuthorizationCode
+			req.Code = "the-code"
+
+			var (
+				result *models.OAuthTokenResult
+				err    error
+			)
+			for range max(tt.exchanges, 1) {
+				result, err = s.OAuthToken(context.Background(), req)
+			}
+			if code := oauthErrorCode(err); code != tt.wantErrCode {
+				t.Fatalf("OAuthToken() error = %v, want %q", err, tt.wantErrCode)
+			}
+			if err != nil {
+				return
+			}
+
+			claims, err := jwt.ParseOAuthToken(result.AccessToken, s.keyring, s.oauthAudience)
+			if err != nil {
+				t.Fatalf("issued access token is invalid: %v", err)
+			}
+			if _, err := jwt.ParseToken(result.AccessToken, s.keyring, s.tokenAudience); err == nil {
+				t.Errorf("issued access token is accepted as a first-party token")
+			}
+			if claims.Subject != userID.String() || claims.ClientID != req.ClientID || !slices.Equal(claims.Scopes, []string{"posts:read"}) {
+				t.Errorf("access token claims %+v, want the user, client and scopes of the code", claims)
+			}
+			if (result.RefreshToken != "") != tt.wantRefresh {
+				t.Errorf("refresh token issued = %t, want %t", result.RefreshToken != "", tt.wantRefresh)
+			}
+		})
+	}
+}
+
+func TestIntrospectOAuthToken(t *testing.T) {
+	type tokens struct {
+		access, refresh string
+	}
+
+	tests := []struct {
+		name         string
+		clientID     string
+		clientSecret string
+		// token picks the token to introspect from the ones issued to partner.
+		token       func(issued tokens) string
+		revoke      bool
+		wantErrCode string
+		wantActive  bool
+	}{
+		{
+			name:         "access token",
+			clientID:     "partner",
+			clientSecret: "partner-secret",
+			token:        func(issued tokens) string { return issued.access },
+			wantActive:   true,
+		},
+		{
+			name:         "refresh token of the client",
+			clientID:     "partner",
+			clientSecret: "partner-secret",
+			token:        func(issued tokens) string { return issued.refresh },
+			wantActive:   true,
+		},
+		{
+			name:         "access token of a revoked grant",
+			clientID:     "partner",
+			clientSecret: "partner-secret",
+			token:        func(issued tokens) string { return issued.access },
+			revoke:       true,
+		},
+		{
+			name:         "refresh token of a revoked grant",
+			clientID:     "partner",
+			clientSecret: "partner-secret",
+			token:        func(issued tokens) string { return issued.refresh },
+			revoke:       true,
+		},
+		{
+			name:         "unknown token",
+			clientID:     "partner",
+			clientSecret: "partner-secret",
+			token:        func(tokens) string { return "garbage" },
+		},
+		{
+			name:        "public clients may not introspect",
+			clientID:    "mobile",
+			token:       func(issued tokens) string { return issued.access },
+			wantErrCode: domain.OAuthUnauthorizedClient,
+		},
+		{
+			name:         "wrong client secret",
+			clientID:     "partner",
+			clientSecret: "guess",
+			token:        func(issued tokens) string { return issued.access },
+			wantErrCode:  domain.OAuthInvalidClient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, storage := newOAuthTestService(t)
+
+			userID := uuid.New()
+			refreshExpiresAt := time.Now().Add(time.Hour)
+			grant := &models.OAuthGrant{ID: uuid.New(), ClientID: "partner", UserID: &userID, Scopes: []string{"posts:read"}, RefreshTokenExpiresAt: &refreshExpiresAt}
+			if err := storage.CreateGrant(ctx, grant, jwt.HashToken("refresh-token")); err != nil {
+				t.Fatalf("failed to create grant: %v", err)
+			}
+			issued, err := s.issueOAuthAccessToken(grant, grant.Scopes, "refresh-token")
+			if err != nil {
+				t.Fatalf("failed to issue access token: %v", err)
+			}
+			if tt.revoke {
+				if err := storage.RevokeGrant(ctx, grant.ID); err != nil {
+					t.Fatalf("failed to revoke grant: %v", err)
+				}
+			}
+
+			introspection, err := s.IntrospectOAuthToken(ctx, tt.clientID, tt.clientSecret, tt.token(tokens{access: issued.AccessToken, refresh: issued.RefreshToken}))
+			if code := oauthErrorCode(err); code != tt.wantErrCode {
+				t.Fatalf("IntrospectOAuthToken() error = %v, want %q", err, tt.wantErrCode)
+			}
+			if err != nil {
+				return
+			}
+
+			if introspection.Active != tt.wantActive {
+				t.Fatalf("active = %t, want %t", introspection.Active, tt.wantActive)
+			}
+			if !tt.wantActive {
+				if introspection.Subject != "" || introspection.ClientID != "" || introspection.Scopes != nil {
+					t.Errorf("inactive token is described: %+v", introspection)
+				}
+				return
+			}
+			if introspection.Subject != userID.String() || introspection.ClientID != "partner" || !slices.Equal(introspection.Scopes, grant.Scopes) {
+				t.Errorf("introspection %+v, want the user, client and scopes of the grant", introspection)
+			}
+		})
+	}
+}
+
+func TestRevokeOAuthToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientID string
+		// refresh revokes the refresh token instead of the access token.
+		refresh          bool
+		wantGrantRevoked bool
+		wantJTIRevoked   bool
+	}{
+		{
+			name:             "access token",
+			clientID:         "partner",
+			wantGrantRevoked: true,
+			wantJTIRevoked:   true,
+		},
+		{
+			name:             "refresh token",
+			clientID:         "partner",
+			refresh:          true,
+			wantGrantRevoked: true,
+		},
+		{
+			name:     "token of another client is ignored",
+			clientID: "mobile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, storage := newOAuthTestService(t)
+
+			userID := uuid.New()
+			grant := &models.OAuthGrant{ID: uuid.New(), ClientID: "partner", UserID: &userID, Scopes: []string{"posts:read"}}
+			if err := storage.CreateGrant(ctx, grant, jwt.HashToken("refresh-token")); err != nil {
+				t.Fatalf("failed to create grant: %v", err)
+			}
+			issued, err := s.issueOAuthAccessToken(grant, grant.Scopes, "refresh-token")
+			if err != nil {
+				t.Fatalf("failed to issue access token: %v", err)
+			}
+			claims, err := jwt.ParseOAuthToken(issued.AccessToken, s.keyring, s.oauthAudience)
+			if err != nil {
+				t.Fatalf("failed to parse access token: %v", err)
+			}
+
+			token := issued.AccessToken
+			if tt.refresh {
+				token = issued.RefreshToken
+			}
+			secret := ""
+			if tt.clientID == "partner" {
+				secret = "partner-secret"
+			}
+
+			if err := s.RevokeOAuthToken(ctx, tt.clientID, secret, token); err != nil {
+				t.Fatalf("RevokeOAuthToken() error = %v", err)
+			}
+
+			if revoked := grant.RevokedAt != nil; revoked != tt.wantGrantRevoked {
+				t.Errorf("grant revoked = %t, want %t", revoked, tt.wantGrantRevoked)
+			}
+			revocations := s.revocations.(*fakeRevocations).revoked
+			if revoked := slices.Contains(revocations, claims.ID.String()); revoked != tt.wantJTIRevoked {
+				t.Errorf("jti revoked = %t, want %t (%v)", revoked, tt.wantJTIRevoked, revocations)
+			}
+		})
+	}
+}
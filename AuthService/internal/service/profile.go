@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/internal/validation"
+
+	"github.com/google/uuid"
+)
+
+const maxProfilesBatch = 100
+
+type ProfileStorage interface {
+	GetProfile(ctx context.Context, userID uuid.UUID) (*models.Profile, error)
+	GetProfileByUsername(ctx context.Context, username string) (*models.Profile, error)
+	GetProfiles(ctx context.Context, userIDs []uuid.UUID) ([]models.Profile, error)
+	SaveProfile(ctx context.Context, profile *models.Profile) error
+}
+
+// UpdateProfile replaces the profile of the caller. The username is required
+// since it is how other users find the profile.
+func (s *AuthenticationService) UpdateProfile(ctx context.Context, accessToken string, profile models.Profile) (*models.Profile, error) {
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.UserID = claims.UserID
+	profile.Username = validation.NormalizeUsername(profile.Username)
+
+	validationErr := &domain.ValidationError{}
+	if err := validation.ValidateUsername(profile.Username); err != nil {
+		validationErr.Add("username", err.Error())
+	}
+	if err := validation.ValidateDisplayName(profile.DisplayName); err != nil {
+		validationErr.Add("display_name", err.Error())
+	}
+	if err := validation.ValidateBio(profile.Bio); err != nil {
+		validationErr.Add("bio", err.Error())
+	}
+	if err := validation.ValidateAvatarURL(profile.AvatarURL); err != nil {
+		validationErr.Add("avatar_url", err.Error())
+	}
+	if len(validationErr.Violations) > 0 {
+		return nil, validationErr
+	}
+
+	if err := s.profileStorage.SaveProfile(ctx, &profile); err != nil {
+		return nil, fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	return &profile, nil
+}
+
+func (s *AuthenticationService) GetProfile(ctx context.Context, userID uuid.UUID) (*models.Profile, error) {
+	profile, err := s.profileStorage.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+func (s *AuthenticationService) GetProfileByUsername(ctx context.Context, username string) (*models.Profile, error) {
+	profile, err := s.profileStorage.GetProfileByUsername(ctx, validation.NormalizeUsername(username))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// GetProfilesBatch returns the profiles of the users that exist; unknown and
+// deleted users are left out.
+func (s *AuthenticationService) GetProfilesBatch(ctx context.Context, userIDs []uuid.UUID) ([]models.Profile, error) {
+	if len(userIDs) > maxProfilesBatch {
+		return nil, domain.NewValidationError("user_ids", fmt.Sprintf("must contain at most %d IDs", maxProfilesBatch))
+	}
+
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	profiles, err := s.profileStorage.GetProfiles(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profiles: %w", err)
+	}
+
+	return profiles, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/message"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/pkg/jwt"
+
+	"github.com/google/uuid"
+)
+
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name string
+		// rotateErr is what the storage reports for the presented token.
+		rotateErr error
+		// returnsSession means the storage returns the session of the token
+		// along with rotateErr.
+		returnsSession bool
+		wantErr        error
+		// wantFamilyRevoked means the cached access token of the session is
+		// dropped, its jti revoked and the owner alerted.
+		wantFamilyRevoked bool
+	}{
+		{
+			name:           "unused token is rotated",
+			returnsSession: true,
+		},
+		{
+			name:              "reused token revokes the family",
+			rotateErr:         domain.ErrRefreshTokenReused,
+			returnsSession:    true,
+			wantErr:           domain.ErrRefreshTokenReused,
+			wantFamilyRevoked: true,
+		},
+		{
+			name:      "unknown token",
+			rotateErr: domain.ErrRefreshTokenNotFound,
+			wantErr:   domain.ErrRefreshTokenNotFound,
+		},
+		{
+			name:      "expired token",
+			rotateErr: domain.ErrRefreshTokenExpired,
+			wantErr:   domain.ErrRefreshTokenExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t)
+
+			user := &models.User{ID: uuid.New(), Email: "user@example.com"}
+			s.userStorage.(*fakeUserStorage).users[user.ID] = user
+
+			sessionID := uuid.New()
+			oldAccessToken, _, err := jwt.GenerateToken(s.keyring, jwt.TokenClaims{
+				UserID:    user.ID,
+				SessionID: sessionID,
+				Email:     user.Email,
+				Issuer:    s.tokenIssuer,
+				Audience:  s.tokenAudience,
+			}, s.AccessTokenTTL)
+			if err != nil {
+				t.Fatalf("failed to generate access token: %v", err)
+			}
+			oldClaims, err := jwt.ParseToken(oldAccessToken, s.keyring, s.tokenAudience)
+			if err != nil {
+				t.Fatalf("failed to parse access token: %v", err)
+			}
+
+			const presented = "presented-refresh-token"
+			var rotatedTo string
+			sessions := s.sessionStorage.(*fakeSessionStorage)
+			sessions.rotate = func(oldHash, newHash string) (*models.Session, error) {
+				if oldHash != jwt.HashToken(presented) {
+					t.Errorf("rotated hash %q, want the hash of the presented token", oldHash)
+				}
+				rotatedTo = newHash
+
+				if !tt.returnsSession {
+					return nil, tt.rotateErr
+				}
+				return &models.Session{ID: sessionID, UserID: user.ID, AccessToken: oldAccessToken}, tt.rotateErr
+			}
+
+			_, accessToken, refreshToken, err := s.Refresh(ctx, presented, models.ClientInfo{})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Refresh() error = %v, want %v", err, tt.wantErr)
+			}
+
+			redis := s.redisClient.(*fakeRedis)
+			revocations := s.revocations.(*fakeRevocations)
+			alerts := s.KafkapProducer.(*fakeBroker).messages
+
+			if tt.wantErr == nil {
+				if refreshToken == presented || jwt.HashToken(refreshToken) != rotatedTo {
+					t.Errorf("Refresh() returned a refresh token that was not the one stored")
+				}
+				claims, err := jwt.ParseToken(accessToken, s.keyring, s.tokenAudience)
+				if err != nil {
+					t.Fatalf("Refresh() returned an invalid access token: %v", err)
+				}
+				if claims.SessionID != sessionID {
+					t.Errorf("access token session = %s, want %s", claims.SessionID, sessionID)
+				}
+				if redis.values[sessionID.String()] != accessToken {
+					t.Errorf("new access token is not cached for the session")
+				}
+			}
+
+			familyRevoked := slices.Contains(redis.deleted, sessionID.String()) &&
+				slices.Contains(revocations.revoked, oldClaims.ID.String())
+			if familyRevoked != tt.wantFamilyRevoked {
+				t.Errorf("family revoked = %t, want %t (deleted %v, revoked %v)", familyRevoked, tt.wantFamilyRevoked, redis.deleted, revocations.revoked)
+			}
+
+			alerted := slices.ContainsFunc(alerts, func(msg interface{}) bool {
+				event, ok := msg.(message.SecurityEventMessage)
+				return ok && event.Event == message.EventRefreshTokenReuse && event.SessionID == sessionID.String()
+			})
+			if alerted != tt.wantFamilyRevoked {
+				t.Errorf("reuse alert sent = %t, want %t", alerted, tt.wantFamilyRevoked)
+			}
+
+			events := s.auditLog.(*fakeAuditLog).events
+			if len(events) != 1 || events[0].Event != models.AuthEventRefresh {
+				t.Fatalf("audit log = %+v, want one refresh event", events)
+			}
+			wantOutcome := models.AuthOutcomeSuccess
+			if tt.wantErr != nil {
+				wantOutcome = models.AuthOutcomeFailure
+			}
+			if events[0].Outcome != wantOutcome {
+				t.Errorf("audit outcome = %q, want %q", events[0].Outcome, wantOutcome)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/internal/validation"
+	"Project/pkg/authz"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type RoleStorage interface {
+	ListUserRoles(ctx context.Context, userID uuid.UUID) ([]models.UserRole, error)
+	GetUserAccess(ctx context.Context, userID uuid.UUID) (*models.UserAccess, error)
+	AssignRole(ctx context.Context, userID uuid.UUID, role string, grantedBy *uuid.UUID) error
+	RevokeRole(ctx context.Context, userID uuid.UUID, role string) error
+}
+
+func (s *AuthenticationService) AssignRole(ctx context.Context, accessToken string, userID uuid.UUID, role string) error {
+	admin, err := s.authorize(ctx, accessToken, authz.PermissionManageRoles)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleStorage.AssignRole(ctx, userID, role, &admin.UserID); err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	s.logger.Info("Role assigned", zap.String("userID", userID.String()), zap.String("role", role), zap.String("adminID", admin.UserID.String()))
+	return nil
+}
+
+// RevokeRole removes a role from the user. Admins cannot take the admin role
+// from themselves so that at least one admin is always left.
+func (s *AuthenticationService) RevokeRole(ctx context.Context, accessToken string, userID uuid.UUID, role string) error {
+	admin, err := s.authorize(ctx, accessToken, authz.PermissionManageRoles)
+	if err != nil {
+		return err
+	}
+
+	if userID == admin.UserID && role == authz.RoleAdmin {
+		return domain.NewValidationError("role", "admins cannot revoke their own admin role")
+	}
+
+	if err := s.roleStorage.RevokeRole(ctx, userID, role); err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	s.logger.Info("Role revoked", zap.String("userID", userID.String()), zap.String("role", role), zap.String("adminID", admin.UserID.String()))
+	return nil
+}
+
+func (s *AuthenticationService) ListUserRoles(ctx context.Context, accessToken string, userID uuid.UUID) ([]models.UserRole, error) {
+	if _, err := s.authorize(ctx, accessToken, authz.PermissionManageRoles); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userStorage.GetUserByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	roles, err := s.roleStorage.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// BootstrapAdmins gives the admin role to the configured accounts so a fresh
+// installation has someone who can assign roles. Unknown emails are skipped.
+func (s *AuthenticationService) BootstrapAdmins(ctx context.Context, emails []string) error {
+	for _, email := range emails {
+		user, err := s.userStorage.GetUserByEmail(ctx, validation.NormalizeEmail(email))
+		if err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				s.logger.Warn("Bootstrap admin does not exist yet", zap.String("email", email))
+				continue
+			}
+			return fmt.Errorf("failed to fetch user: %w", err)
+		}
+
+		err = s.roleStorage.AssignRole(ctx, user.ID, authz.RoleAdmin, nil)
+		if err != nil && !errors.Is(err, domain.ErrRoleAlreadyAssigned) {
+			return fmt.Errorf("failed to assign admin role: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// authorize authenticates the caller and checks the permissions against the
+// roles stored now rather than the ones in the token, so a revoked role stops
+// working at once for the RPCs of AuthService itself. Impersonation tokens
+// never pass, even when the impersonated user has the permissions.
+func (s *AuthenticationService) authorize(ctx context.Context, accessToken string, permissions ...string) (*authz.Principal, error) {
+	claims, err := s.authenticateAccountOwner(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := s.roleStorage.GetUserAccess(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	principal := &authz.Principal{
+		UserID:      claims.UserID,
+		Roles:       access.Roles,
+		Permissions: access.Permissions,
+	}
+
+	if err := authz.Require(*principal, permissions...); err != nil {
+		return nil, domain.ErrPermissionDenied
+	}
+
+	return principal, nil
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"Project/AuthService/internal/domain/models"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuthEventStorage keeps the audit log of authentication events. The table is
+// append-only, a trigger rejects updates and deletes.
+type AuthEventStorage struct {
+	db *pgxpool.Pool
+}
+
+func NewAuthEventStorage(db *pgxpool.Pool) (*AuthEventStorage, error) {
+	return &AuthEventStorage{
+		db: db,
+	}, nil
+}
+
+func (r *AuthEventStorage) SaveAuthEvent(ctx context.Context, event *models.AuthEvent) error {
+	query := `
+		INSERT INTO auth_events (id, user_id, email, event, outcome, reason, ip, user_agent, session_id, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), $7, $8, $9, $10)
+	`
+	_, err := r.db.Exec(ctx, query,
+		event.ID,
+		nullUUID(event.UserID),
+		event.Email,
+		event.Event,
+		event.Outcome,
+		event.Reason,
+		event.IP,
+		event.UserAgent,
+		nullUUID(event.SessionID),
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save auth event: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuthEvents returns one page of the events matching the filter, newest
+// first, together with the number of all matching events.
+func (r *AuthEventStorage) ListAuthEvents(ctx context.Context, filter models.AuthEventFilter, page, pageSize int) ([]models.AuthEvent, int, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.UserID != uuid.Nil {
+		addCondition(`user_id = $%d`, filter.UserID)
+	}
+	if filter.Email != "" {
+		addCondition(`email = $%d`, filter.Email)
+	}
+	if filter.Event != "" {
+		addCondition(`event = $%d`, filter.Event)
+	}
+	if filter.Outcome != "" {
+		addCondition(`outcome = $%d`, filter.Outcome)
+	}
+	if filter.From != nil {
+		addCondition(`created_at >= $%d`, *filter.From)
+	}
+	if filter.To != nil {
+		addCondition(`created_at < $%d`, *filter.To)
+	}
+
+	where := "TRUE"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM auth_events WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count auth events: %w", err)
+	}
+
+	args = append(args, pageSize, (page-1)*pageSize)
+	query := fmt.Sprintf(`
+		SELECT id, user_id, COALESCE(email, ''), event, outcome, COALESCE(reason, ''),
+			COALESCE(ip, ''), COALESCE(user_agent, ''), session_id, created_at
+		FROM auth_events
+		WHERE %s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list auth events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.AuthEvent
+	for rows.Next() {
+		var (
+			event     models.AuthEvent
+			userID    *uuid.UUID
+			sessionID *uuid.UUID
+		)
+		err := rows.Scan(&event.ID, &userID, &event.Email, &event.Event, &event.Outcome, &event.Reason,
+			&event.IP, &event.UserAgent, &sessionID, &event.CreatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan auth event: %w", err)
+		}
+		if userID != nil {
+			event.UserID = *userID
+		}
+		if sessionID != nil {
+			event.SessionID = *sessionID
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list auth events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// nullUUID stores uuid.Nil as NULL.
+func nullUUID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+
+	return &id
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdentityStorage struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityStorage(db *pgxpool.Pool) (*IdentityStorage, error) {
+	return &IdentityStorage{
+		db: db,
+	}, nil
+}
+
+func (r *IdentityStorage) GetIdentity(ctx context.Context, provider, subject string) (*models.Identity, error) {
+	var (
+		identity models.Identity
+		email    *string
+	)
+
+	query := `SELECT id, user_id, provider, subject, email, created_at FROM identities WHERE provider = $1 AND subject = $2`
+	err := r.db.QueryRow(ctx, query, provider, subject).Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &email, &identity.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrIdentityNotFound
+		}
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	if email != nil {
+		identity.Email = *email
+	}
+
+	return &identity, nil
+}
+
+func (r *IdentityStorage) CreateIdentity(ctx context.Context, identity *models.Identity) error {
+	return createIdentity(ctx, r.db, identity)
+}
+
+// CreateUserWithIdentity creates a user with a confirmed email together with
+// its first identity.
+func (r *IdentityStorage) CreateUserWithIdentity(ctx context.Context, email, emailNormalized, passwordHash string, identity *models.Identity) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO users (email, email_normalized, password_hash, email_confirmed)
+		VALUES ($1, $2, $3, true)
+		RETURNING id
+	`
+	var userID uuid.UUID
+	if err := tx.QueryRow(ctx, query, email, emailNormalized, passwordHash).Scan(&userID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return uuid.Nil, domain.ErrEmailAlreadyExists
+		}
+		return uuid.Nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	identity.UserID = userID
+	if err := createIdentity(ctx, tx, identity); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit user: %w", err)
+	}
+
+	return userID, nil
+}
+
+// execer is implemented by both the pool and a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func createIdentity(ctx context.Context, db execer, identity *models.Identity) error {
+	query := `
+		INSERT INTO identities (user_id, provider, subject, email)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+	`
+	if _, err := db.Exec(ctx, query, identity.UserID, identity.Provider, identity.Subject, identity.Email); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return domain.ErrIdentityAlreadyLinked
+		}
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	return nil
+}
+
+func (r *IdentityStorage) ListIdentities(ctx context.Context, userID uuid.UUID) ([]models.Identity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, COALESCE(email, ''), created_at
+		FROM identities
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	defer rows.Close()
+
+	var identities []models.Identity
+	for rows.Next() {
+		var identity models.Identity
+		if err := rows.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan identity: %w", err)
+		}
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	return identities, nil
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MFAStorage struct {
+	db *pgxpool.Pool
+}
+
+func NewMFAStorage(db *pgxpool.Pool) (*MFAStorage, error) {
+	return &MFAStorage{
+		db: db,
+	}, nil
+}
+
+func (r *MFAStorage) GetMFA(ctx context.Context, userID uuid.UUID) (*models.MFA, error) {
+	var mfa models.MFA
+
+	query := `SELECT user_id, secret_encrypted, enabled_at, last_used_step FROM user_mfa WHERE user_id = $1`
+	err := r.db.QueryRow(ctx, query, userID).Scan(&mfa.UserID, &mfa.SecretEncrypted, &mfa.EnabledAt, &mfa.LastUsedStep)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrMFANotEnabled
+		}
+		return nil, fmt.Errorf("failed to get mfa: %w", err)
+	}
+
+	return &mfa, nil
+}
+
+// SavePendingSecret stores a new secret that is not active until EnableMFA.
+// An active secret is never replaced.
+func (r *MFAStorage) SavePendingSecret(ctx context.Context, userID uuid.UUID, secretEncrypted string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret_encrypted)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id)
+		DO UPDATE SET secret_encrypted = $2, last_used_step = 0, created_at = NOW()
+		WHERE user_mfa.enabled_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, userID, secretEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to save mfa secret: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+
+	return nil
+}
+
+// EnableMFA activates the pending secret and replaces the recovery codes of the
+// user in one transaction.
+func (r *MFAStorage) EnableMFA(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE user_mfa
+		SET enabled_at = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND enabled_at IS NULL
+	`
+	tag, err := tx.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to enable mfa: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec(ctx, `INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			return fmt.Errorf("failed to save recovery code: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit mfa activation: %w", err)
+	}
+
+	return nil
+}
+
+// UseStep records step as the last accepted TOTP step. It fails when a code of
+// the same or a later step was already accepted, which stops replays.
+func (r *MFAStorage) UseStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	query := `
+		UPDATE user_mfa
+		SET last_used_step = $2
+		WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_used_step < $2
+	`
+	tag, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to update mfa step: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+func (r *MFAStorage) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"Project/AuthService/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestUseStep(t *testing.T) {
+	const enabledStep = 100
+
+	tests := []struct {
+		name    string
+		enable  bool
+		steps   []int64
+		wantErr []error
+	}{
+		{
+			name:    "newer steps are accepted once",
+			enable:  true,
+			steps:   []int64{101, 101, 102},
+			wantErr: []error{nil, domain.ErrInvalidMFACode, nil},
+		},
+		{
+			name:    "step used to enable cannot be replayed",
+			enable:  true,
+			steps:   []int64{enabledStep},
+			wantErr: []error{domain.ErrInvalidMFACode},
+		},
+		{
+			name:    "older step after a newer one",
+			enable:  true,
+			steps:   []int64{102, 101},
+			wantErr: []error{nil, domain.ErrInvalidMFACode},
+		},
+		{
+			name:    "pending secret",
+			steps:   []int64{101},
+			wantErr: []error{domain.ErrInvalidMFACode},
+		},
+	}
+
+	db := testDB(t)
+	users, _ := NewUserStorage(db)
+	mfa, _ := NewMFAStorage(db)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			email := uuid.NewString() + "@example.com"
+			userID, err := users.CreateUser(ctx, email, email, "hash")
+			if err != nil {
+				t.Fatalf("failed to create user: %v", err)
+			}
+
+			if err := mfa.SavePendingSecret(ctx, userID, "secret"); err != nil {
+				t.Fatalf("failed to save secret: %v", err)
+			}
+			if tt.enable {
+				if err := mfa.EnableMFA(ctx, userID, enabledStep, nil); err != nil {
+					t.Fatalf("failed to enable mfa: %v", err)
+				}
+			}
+
+			for i, step := range tt.steps {
+				if err := mfa.UseStep(ctx, userID, step); !errors.Is(err, tt.wantErr[i]) {
+					t.Errorf("UseStep(%d) error = %v, want %v", step, err, tt.wantErr[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package postgres
+
+import (
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OAuthStorage struct {
+	db *pgxpool.Pool
+}
+
+func NewOAuthStorage(db *pgxpool.Pool) (*OAuthStorage, error) {
+	return &OAuthStorage{
+		db: db,
+	}, nil
+}
+
+func (r *OAuthStorage) CreateClient(ctx context.Context, client *models.OAuthClient) error {
+	query := `
+		INSERT INTO oauth_clients (id, name, secret_hash, redirect_uris, scopes, grant_types, owner_user_id)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(ctx, query, client.ID, client.Name, client.SecretHash, client.RedirectURIs, client.Scopes, client.GrantTypes, client.OwnerUserID)
+	if err != nil {
+		return fmt.Errorf("failed to create oauth client: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OAuthStorage) GetClient(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	var (
+		client     models.OAuthClient
+		secretHash *string
+	)
+
+	query := `
+		SELECT id, name, secret_hash, redirect_uris, scopes, grant_types, owner_user_id, created_at
+		FROM oauth_clients
+		WHERE id = $1
+	`
+	err := r.db.QueryRow(ctx, query, clientID).Scan(&client.ID, &client.Name, &secretHash, &client.RedirectURIs, &client.Scopes, &client.GrantTypes, &client.OwnerUserID, &client.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOAuthClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+
+	if secretHash != nil {
+		client.SecretHash = *secretHash
+	}
+
+	return &client, nil
+}
+
+func (r *OAuthStorage) SaveAuthorizationCode(ctx context.Context, code *models.OAuthAuthorizationCode) error {
+	query := `
+		INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(ctx, query, code.CodeHash, code.ClientID, code.UserID, code.RedirectURI, code.Scopes, code.CodeChallenge, code.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save authorization code: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeAuthorizationCode marks the code as used and returns it. A code that
+// is presented twice was most likely stolen, so the grants issued for it are
+// revoked (RFC 6749 section 4.1.2).
+func (r *OAuthStorage) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*models.OAuthAuthorizationCode, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		code   models.OAuthAuthorizationCode
+		usedAt *time.Time
+	)
+
+	selectQuery := `
+		SELECT code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, used_at
+		FROM oauth_authorization_codes
+		WHERE code_hash = $1
+		FOR UPDATE
+	`
+	err = tx.QueryRow(ctx, selectQuery, codeHash).Scan(&code.CodeHash, &code.ClientID, &code.UserID, &code.RedirectURI, &code.Scopes, &code.CodeChallenge, &code.ExpiresAt, &usedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOAuthCodeInvalid
+		}
+		return nil, fmt.Errorf("failed to get authorization code: %w", err)
+	}
+
+	if usedAt != nil {
+		revokeQuery := `UPDATE oauth_grants SET revoked_at = NOW() WHERE authorization_code_hash = $1 AND revoked_at IS NULL`
+		if _, err := tx.Exec(ctx, revokeQuery, codeHash); err != nil {
+			return nil, fmt.Errorf("failed to revoke grants of reused code: %w", err)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit grant revocation: %w", err)
+		}
+
+		return nil, domain.ErrOAuthCodeReused
+	}
+
+	if time.Now().After(code.ExpiresAt) {
+		return nil, domain.ErrOAuthCodeInvalid
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE oauth_authorization_codes SET used_at = NOW() WHERE code_hash = $1`, codeHash); err != nil {
+		return nil, fmt.Errorf("failed to mark authorization code as used: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit authorization code: %w", err)
+	}
+
+	return &code, nil
+}
+
+func (r *OAuthStorage) GetConsent(ctx context.Context, userID uuid.UUID, clientID string) (*models.OAuthConsent, error) {
+	var consent models.OAuthConsent
+
+	query := `SELECT user_id, client_id, scopes, granted_at FROM oauth_consents WHERE user_id = $1 AND client_id = $2`
+	err := r.db.QueryRow(ctx, query, userID, clientID).Scan(&consent.UserID, &consent.ClientID, &consent.Scopes, &consent.GrantedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOAuthConsentNotFound
+		}
+		return nil, fmt.Errorf("failed to get consent: %w", err)
+	}
+
+	return &consent, nil
+}
+
+func (r *OAuthStorage) SaveConsent(ctx context.Context, userID uuid.UUID, clientID string, scopes []string) error {
+	query := `
+		INSERT INTO oauth_consents (user_id, client_id, scopes)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, client_id)
+		DO UPDATE SET scopes = $3, granted_at = NOW()
+	`
+	if _, err := r.db.Exec(ctx, query, userID, clientID, scopes); err != nil {
+		return fmt.Errorf("failed to save consent: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeConsent deletes the consent and revokes every grant the user gave the
+// client.
+func (r *OAuthStorage) RevokeConsent(ctx context.Context, userID uuid.UUID, clientID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM oauth_consents WHERE user_id = $1 AND client_id = $2`, userID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to delete consent: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOAuthConsentNotFound
+	}
+
+	revokeQuery := `UPDATE oauth_grants SET revoked_at = NOW() WHERE user_id = $1 AND client_id = $2 AND revoked_at IS NULL`
+	if _, err := tx.Exec(ctx, revokeQuery, userID, clientID); err != nil {
+		return fmt.Errorf("failed to revoke grants: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit consent revocation: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OAuthStorage) CreateGrant(ctx context.Context, grant *models.OAuthGrant, refreshTokenHash string) error {
+	query := `
+		INSERT INTO oauth_grants (id, client_id, user_id, scopes, authorization_code_hash, refresh_token_hash, refresh_token_expires_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7)
+	`
+	_, err := r.db.Exec(ctx, query, grant.ID, grant.ClientID, grant.UserID, grant.Scopes, grant.AuthorizationCodeHash, refreshTokenHash, grant.RefreshTokenExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create grant: %w", err)
+	}
+
+	return nil
+}
+
+const grantColumns = `id, client_id, user_id, scopes, COALESCE(authorization_code_hash, ''), refresh_token_expires_at, revoked_at, created_at`
+
+func scanGrant(row pgx.Row) (*models.OAuthGrant, error) {
+	var grant models.OAuthGrant
+
+	err := row.Scan(&grant.ID, &grant.ClientID, &grant.UserID, &grant.Scopes, &grant.AuthorizationCodeHash, &grant.RefreshTokenExpiresAt, &grant.RevokedAt, &grant.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOAuthGrantNotFound
+		}
+		return nil, fmt.Errorf("failed to get grant: %w", err)
+	}
+
+	return &grant, nil
+}
+
+func (r *OAuthStorage) GetGrant(ctx context.Context, grantID uuid.UUID) (*models.OAuthGrant, error) {
+	query := `SELECT ` + grantColumns + ` FROM oauth_grants WHERE id = $1`
+	return scanGrant(r.db.QueryRow(ctx, query, grantID))
+}
+
+func (r *OAuthStorage) GetGrantByRefreshToken(ctx context.Context, refreshTokenHash string) (*models.OAuthGrant, error) {
+	query := `SELECT ` + grantColumns + ` FROM oauth_grants WHERE refresh_token_hash = $1`
+	return scanGrant(r.db.QueryRow(ctx, query, refreshTokenHash))
+}
+
+// RotateRefreshToken replaces a valid refresh token of an active grant with a
+// new one. Used, expired and revoked tokens return domain.ErrOAuthGrantNotFound.
+func (r *OAuthStorage) RotateRefreshToken(ctx context.Context, oldHash, newHash string, newExpiresAt time.Time) (*models.OAuthGrant, error) {
+	query := `
+		UPDATE oauth_grants
+		SET refresh_token_hash = $2, refresh_token_expires_at = $3
+		WHERE refresh_token_hash = $1 AND revoked_at IS NULL AND refresh_token_expires_at > NOW()
+		RETURNING ` + grantColumns
+	return scanGrant(r.db.QueryRow(ctx, query, oldHash, newHash, newExpiresAt))
+}
+
+func (r *OAuthStorage) RevokeGrant(ctx context.Context, grantID uuid.UUID) error {
+	query := `UPDATE oauth_grants SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	if _, err := r.db.Exec(ctx, query, grantID); err != nil {
+		return fmt.Errorf("failed to revoke grant: %w", err)
+	}
+
+	return nil
+}
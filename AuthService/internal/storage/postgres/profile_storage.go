@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// selectProfiles returns a profile for every user that is not deleted, empty
+// for users who have none.
+const selectProfiles = `
+	SELECT u.id, COALESCE(p.username, ''), COALESCE(p.display_name, ''), COALESCE(p.bio, ''), COALESCE(p.avatar_url, '')
+	FROM users u
+	LEFT JOIN profiles p ON p.user_id = u.id
+	WHERE u.deleted_at IS NULL
+`
+
+type ProfileStorage struct {
+	db *pgxpool.Pool
+}
+
+func NewProfileStorage(db *pgxpool.Pool) (*ProfileStorage, error) {
+	return &ProfileStorage{
+		db: db,
+	}, nil
+}
+
+func scanProfile(row pgx.Row) (*models.Profile, error) {
+	var profile models.Profile
+	if err := row.Scan(&profile.UserID, &profile.Username, &profile.DisplayName, &profile.Bio, &profile.AvatarURL); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *ProfileStorage) GetProfile(ctx context.Context, userID uuid.UUID) (*models.Profile, error) {
+	profile, err := scanProfile(r.db.QueryRow(ctx, selectProfiles+` AND u.id = $1`, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+func (r *ProfileStorage) GetProfileByUsername(ctx context.Context, username string) (*models.Profile, error) {
+	profile, err := scanProfile(r.db.QueryRow(ctx, selectProfiles+` AND p.username = $1`, username))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrProfileNotFound
+		}
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// GetProfiles returns the profiles of the users that exist, in no particular
+// order.
+func (r *ProfileStorage) GetProfiles(ctx context.Context, userIDs []uuid.UUID) ([]models.Profile, error) {
+	rows, err := r.db.Query(ctx, selectProfiles+` AND u.id = ANY($1)`, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []models.Profile
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan profile: %w", err)
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get profiles: %w", err)
+	}
+
+	return profiles, nil
+}
+
+func (r *ProfileStorage) SaveProfile(ctx context.Context, profile *models.Profile) error {
+	query := `
+		INSERT INTO profiles (user_id, username, display_name, bio, avatar_url)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id)
+		DO UPDATE SET username = $2, display_name = $3, bio = $4, avatar_url = $5, updated_at = NOW()
+	`
+	_, err := r.db.Exec(ctx, query, profile.UserID, profile.Username, profile.DisplayName, profile.Bio, profile.AvatarURL)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return domain.ErrUsernameTaken
+		}
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	return nil
+}
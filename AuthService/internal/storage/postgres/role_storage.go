@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const foreignKeyViolationCode = "23503"
+
+type RoleStorage struct {
+	db *pgxpool.Pool
+}
+
+func NewRoleStorage(db *pgxpool.Pool) (*RoleStorage, error) {
+	return &RoleStorage{
+		db: db,
+	}, nil
+}
+
+func (r *RoleStorage) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]models.UserRole, error) {
+	query := `
+		SELECT ur.role, ur.granted_by, ur.granted_at,
+			COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM user_roles ur
+		LEFT JOIN role_permissions rp ON rp.role = ur.role
+		WHERE ur.user_id = $1
+		GROUP BY ur.role, ur.granted_by, ur.granted_at
+		ORDER BY ur.role`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []models.UserRole
+	for rows.Next() {
+		var role models.UserRole
+		if err := rows.Scan(&role.Role, &role.GrantedBy, &role.GrantedAt, &role.Permissions); err != nil {
+			return nil, fmt.Errorf("failed to scan user role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (r *RoleStorage) GetUserAccess(ctx context.Context, userID uuid.UUID) (*models.UserAccess, error) {
+	roles, err := r.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	access := &models.UserAccess{Roles: []string{}, Permissions: []string{}}
+	for _, role := range roles {
+		access.Roles = append(access.Roles, role.Role)
+		for _, permission := range role.Permissions {
+			if !slices.Contains(access.Permissions, permission) {
+				access.Permissions = append(access.Permissions, permission)
+			}
+		}
+	}
+	slices.Sort(access.Permissions)
+
+	return access, nil
+}
+
+func (r *RoleStorage) AssignRole(ctx context.Context, userID uuid.UUID, role string, grantedBy *uuid.UUID) error {
+	query := `INSERT INTO user_roles (user_id, role, granted_by) VALUES ($1, $2, $3)`
+
+	_, err := r.db.Exec(ctx, query, userID, role, grantedBy)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch {
+			case pgErr.Code == uniqueViolationCode:
+				return domain.ErrRoleAlreadyAssigned
+			case pgErr.Code == foreignKeyViolationCode && pgErr.ConstraintName == "user_roles_role_fkey":
+				return domain.ErrRoleNotFound
+			case pgErr.Code == foreignKeyViolationCode:
+				return domain.ErrUserNotFound
+			}
+		}
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RoleStorage) RevokeRole(ctx context.Context, userID uuid.UUID, role string) error {
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role = $2`
+
+	tag, err := r.db.Exec(ctx, query, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRoleNotAssigned
+	}
+
+	return nil
+}
@@ -0,0 +1,410 @@
+package postgres
+
+import (
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SessionStorage struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionStorage(db *pgxpool.Pool) (*SessionStorage, error) {
+	return &SessionStorage{
+		db: db,
+	}, nil
+}
+
+func (r *SessionStorage) CreateSession(ctx context.Context, session *models.Session, refreshTokenHash string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sessionQuery := `
+		INSERT INTO sessions
+			(id, user_id, device_name, user_agent, ip, access_token, access_token_expires_at, refresh_token_expires_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = tx.Exec(ctx, sessionQuery,
+		session.ID,
+		session.UserID,
+		session.DeviceName,
+		session.UserAgent,
+		session.IP,
+		session.AccessToken,
+		session.AccessTokenExpiresAt,
+		session.RefreshTokenExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	tokenQuery := `
+		INSERT INTO refresh_tokens (family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := tx.Exec(ctx, tokenQuery, session.ID, refreshTokenHash, session.RefreshTokenExpiresAt); err != nil {
+		return fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit session: %w", err)
+	}
+
+	return nil
+}
+
+// CreateImpersonationSession creates a session for the impersonated user
+// together with its audit record. The session has no refresh token, so it
+// ends when its access token expires.
+func (r *SessionStorage) CreateImpersonationSession(ctx context.Context, session *models.Session, impersonation *models.Impersonation) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sessionQuery := `
+		INSERT INTO sessions
+			(id, user_id, device_name, user_agent, ip, access_token, access_token_expires_at, refresh_token_expires_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $7)
+	`
+	_, err = tx.Exec(ctx, sessionQuery,
+		session.ID,
+		session.UserID,
+		session.DeviceName,
+		session.UserAgent,
+		session.IP,
+		session.AccessToken,
+		session.AccessTokenExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	auditQuery := `
+		INSERT INTO impersonations (id, admin_id, user_id, session_id, reason, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err = tx.Exec(ctx, auditQuery,
+		impersonation.ID,
+		impersonation.AdminID,
+		impersonation.UserID,
+		impersonation.SessionID,
+		impersonation.Reason,
+		impersonation.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save impersonation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit impersonation session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SessionStorage) UpdateSessionAccessToken(ctx context.Context, sessionID uuid.UUID, accessToken string, accessExp time.Time) error {
+	query := `
+		UPDATE sessions
+		SET
+			access_token = $2,
+			access_token_expires_at = $3
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, sessionID, accessToken, accessExp)
+	if err != nil {
+		return fmt.Errorf("failed to update session access token: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RotateRefreshToken marks the presented refresh token as used and stores its
+// successor in the same family. Presenting a token that was already used revokes
+// the whole family; the session is still returned together with
+// domain.ErrRefreshTokenReused so the caller can alert the user.
+func (r *SessionStorage) RotateRefreshToken(ctx context.Context, oldHash, newHash string, newExp time.Time) (*models.Session, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		tokenID        uuid.UUID
+		session        models.Session
+		tokenExpiresAt time.Time
+		usedAt         *time.Time
+		revokedAt      *time.Time
+	)
+
+	selectQuery := `
+		SELECT rt.id, rt.expires_at, rt.used_at, COALESCE(rt.revoked_at, s.revoked_at), s.id, s.user_id
+		FROM refresh_tokens rt
+		JOIN sessions s ON s.id = rt.family_id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF rt, s
+	`
+	err = tx.QueryRow(ctx, selectQuery, oldHash).Scan(&tokenID, &tokenExpiresAt, &usedAt, &revokedAt, &session.ID, &session.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if usedAt != nil {
+		session.AccessToken, err = revokeFamily(ctx, tx, session.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit family revocation: %w", err)
+		}
+
+		return &session, domain.ErrRefreshTokenReused
+	}
+
+	if revokedAt != nil {
+		return nil, domain.ErrRefreshTokenNotFound
+	}
+
+	if time.Now().After(tokenExpiresAt) {
+		return nil, domain.ErrRefreshTokenExpired
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`, tokenID); err != nil {
+		return nil, fmt.Errorf("failed to mark refresh token as used: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO refresh_tokens (family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := tx.Exec(ctx, insertQuery, session.ID, newHash, newExp); err != nil {
+		return nil, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	sessionQuery := `
+		UPDATE sessions
+		SET
+			refresh_token_expires_at = $2,
+			last_used_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, sessionQuery, session.ID, newExp); err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit refresh token rotation: %w", err)
+	}
+
+	session.RefreshTokenExpiresAt = newExp
+	return &session, nil
+}
+
+// revokeFamily revokes the session and its refresh tokens and returns the access
+// token the session had, empty if it was revoked already.
+func revokeFamily(ctx context.Context, tx pgx.Tx, familyID uuid.UUID) (string, error) {
+	sessionQuery := `
+		UPDATE sessions s
+		SET
+			access_token = NULL,
+			revoked_at = NOW()
+		FROM (SELECT id, access_token FROM sessions WHERE id = $1 AND revoked_at IS NULL FOR UPDATE) old
+		WHERE s.id = old.id
+		RETURNING COALESCE(old.access_token, '')
+	`
+	var accessToken string
+	err := tx.QueryRow(ctx, sessionQuery, familyID).Scan(&accessToken)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	tokenQuery := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, tokenQuery, familyID); err != nil {
+		return "", fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return accessToken, nil
+}
+
+func (r *SessionStorage) GetSession(ctx context.Context, sessionID uuid.UUID) (*models.Session, error) {
+	var session models.Session
+
+	query := `
+		SELECT id, user_id, COALESCE(device_name, ''), COALESCE(user_agent, ''), COALESCE(ip, ''),
+			COALESCE(access_token, ''), access_token_expires_at, refresh_token_expires_at,
+			created_at, last_used_at
+		FROM sessions
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	err := r.db.QueryRow(ctx, query, sessionID).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.DeviceName,
+		&session.UserAgent,
+		&session.IP,
+		&session.AccessToken,
+		&session.AccessTokenExpiresAt,
+		&session.RefreshTokenExpiresAt,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return &session, nil
+}
+
+func (r *SessionStorage) ListSessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	query := `
+		SELECT id, user_id, COALESCE(device_name, ''), COALESCE(user_agent, ''), COALESCE(ip, ''), created_at, last_used_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND refresh_token_expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.UserID, &session.DeviceName, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes the session and returns the access token it had.
+func (r *SessionStorage) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM sessions WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL)`
+	if err := tx.QueryRow(ctx, query, sessionID, userID).Scan(&exists); err != nil {
+		return "", fmt.Errorf("failed to check session: %w", err)
+	}
+
+	if !exists {
+		return "", domain.ErrSessionNotFound
+	}
+
+	accessToken, err := revokeFamily(ctx, tx, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit session revocation: %w", err)
+	}
+
+	return accessToken, nil
+}
+
+// RevokeOtherSessions revokes every active session of the user except keepSessionID
+// and returns the IDs and access tokens of the revoked sessions. Pass uuid.Nil to
+// revoke all of them.
+func (r *SessionStorage) RevokeOtherSessions(ctx context.Context, userID, keepSessionID uuid.UUID) ([]models.Session, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE sessions s
+		SET
+			access_token = NULL,
+			revoked_at = NOW()
+		FROM (
+			SELECT id, access_token FROM sessions
+			WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+			FOR UPDATE
+		) old
+		WHERE s.id = old.id
+		RETURNING s.id, COALESCE(old.access_token, '')
+	`
+	rows, err := tx.Query(ctx, query, userID, keepSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	var (
+		revoked []models.Session
+		ids     []uuid.UUID
+	)
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.AccessToken); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan session id: %w", err)
+		}
+		session.UserID = userID
+		revoked = append(revoked, session)
+		ids = append(ids, session.ID)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	tokenQuery := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE family_id = ANY($1) AND revoked_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, tokenQuery, ids); err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit session revocation: %w", err)
+	}
+
+	return revoked, nil
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"Project/AuthService/internal/domain"
+	"Project/AuthService/internal/domain/models"
+	"Project/AuthService/pkg/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDB connects to the database in AUTH_TEST_DB_DSN, which has to be migrated
+// already, e.g. with make test-integration. Tests that need it are skipped
+// without it.
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("AUTH_TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("AUTH_TEST_DB_DSN is not set")
+	}
+
+	db, err := database.ConnectToDB(dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	return db
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	type presentation struct {
+		// token is "first" for the token of the new session, "second" for the
+		// one the first rotation issued or "unknown" for one never issued.
+		token   string
+		wantErr error
+	}
+
+	tests := []struct {
+		name          string
+		refreshTTL    time.Duration
+		presentations []presentation
+		// wantRevoked means the session ends up revoked.
+		wantRevoked bool
+	}{
+		{
+			name:       "unused token is rotated",
+			refreshTTL: time.Hour,
+			presentations: []presentation{
+				{token: "first"},
+				{token: "second"},
+			},
+		},
+		{
+			name:       "reused token revokes the family",
+			refreshTTL: time.Hour,
+			presentations: []presentation{
+				{token: "first"},
+				{token: "first", wantErr: domain.ErrRefreshTokenReused},
+				{token: "second", wantErr: domain.ErrRefreshTokenNotFound},
+			},
+			wantRevoked: true,
+		},
+		{
+			name:       "reuse after the revocation is still reported",
+			refreshTTL: time.Hour,
+			presentations: []presentation{
+				{token: "first"},
+				{token: "first", wantErr: domain.ErrRefreshTokenReused},
+				{token: "first", wantErr: domain.ErrRefreshTokenReused},
+			},
+			wantRevoked: true,
+		},
+		{
+			name:       "expired token",
+			refreshTTL: -time.Minute,
+			presentations: []presentation{
+				{token: "first", wantErr: domain.ErrRefreshTokenExpired},
+			},
+		},
+		{
+			name:       "unknown token",
+			refreshTTL: time.Hour,
+			presentations: []presentation{
+				{token: "unknown", wantErr: domain.ErrRefreshTokenNotFound},
+			},
+		},
+	}
+
+	db := testDB(t)
+	users, _ := NewUserStorage(db)
+	sessions, _ := NewSessionStorage(db)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			email := uuid.NewString() + "@example.com"
+			userID, err := users.CreateUser(ctx, email, email, "hash")
+			if err != nil {
+				t.Fatalf("failed to create user: %v", err)
+			}
+
+			session := &models.Session{
+				ID:                    uuid.New(),
+				UserID:                userID,
+				AccessToken:           "access-token",
+				AccessTokenExpiresAt:  time.Now().Add(time.Minute),
+				RefreshTokenExpiresAt: time.Now().Add(tt.refreshTTL),
+			}
+			if err := sessions.CreateSession(ctx, session, "hash-first-"+session.ID.String()); err != nil {
+				t.Fatalf("failed to create session: %v", err)
+			}
+
+			hashes := map[string]string{
+				"first":   "hash-first-" + session.ID.String(),
+				"second":  "hash-second-" + session.ID.String(),
+				"unknown": "hash-unknown-" + session.ID.String(),
+			}
+			next := map[string]string{"first": "second", "second": "third"}
+
+			for i, p := range tt.presentations {
+				newHash := "hash-" + next[p.token] + "-" + session.ID.String()
+				rotated, err := sessions.RotateRefreshToken(ctx, hashes[p.token], newHash, time.Now().Add(time.Hour))
+				if !errors.Is(err, p.wantErr) {
+					t.Fatalf("presentation %d of %s: error = %v, want %v", i, p.token, err, p.wantErr)
+				}
+
+				if p.wantErr == nil && (rotated == nil || rotated.ID != session.ID) {
+					t.Errorf("presentation %d rotated session %+v, want %s", i, rotated, session.ID)
+				}
+
+				if errors.Is(p.wantErr, domain.ErrRefreshTokenReused) && (rotated == nil || rotated.ID != session.ID) {
+					t.Errorf("presentation %d did not report the reused session", i)
+				}
+			}
+
+			stored, err := sessions.GetSession(ctx, session.ID)
+			revoked := errors.Is(err, domain.ErrSessionNotFound)
+			if err != nil && !revoked {
+				t.Fatalf("failed to get session: %v", err)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("session revoked = %t (%+v), want %t", revoked, stored, tt.wantRevoked)
+			}
+		})
+	}
+}
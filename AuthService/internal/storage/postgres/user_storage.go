@@ -1,17 +1,22 @@
 package postgres
 
 import (
+	"Project/AuthService/internal/domain"
 	"Project/AuthService/internal/domain/models"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const uniqueViolationCode = "23505"
+
 type UserStorage struct {
 	db *pgxpool.Pool
 }
@@ -26,26 +31,31 @@ func (r *UserStorage) CloseDb() {
 	r.db.Close()
 }
 
-func (r *UserStorage) CreateUser(ctx context.Context, email, password string) (uuid.UUID, error) {
+func (r *UserStorage) CreateUser(ctx context.Context, email, emailNormalized, password string) (uuid.UUID, error) {
 	var id uuid.UUID
 
-	query := `INSERT INTO users (email, password_hash) VALUES ($1, $2) RETURNING id`
-	err := r.db.QueryRow(ctx, query, email, password).Scan(&id)
+	query := `INSERT INTO users (email, email_normalized, password_hash) VALUES ($1, $2, $3) RETURNING id`
+	err := r.db.QueryRow(ctx, query, email, emailNormalized, password).Scan(&id)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return uuid.Nil, domain.ErrEmailAlreadyExists
+		}
 		return uuid.Nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
 	return id, nil
 }
 
-func (r *UserStorage) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+// GetUserByEmail looks the user up by the normalized email.
+func (r *UserStorage) GetUserByEmail(ctx context.Context, emailNormalized string) (*models.User, error) {
 	var user models.User
 
-	query := `SELECT id, email, password_hash FROM users WHERE email = $1`
-	err := r.db.QueryRow(ctx, query, email).Scan(&user.ID, &user.Email, &user.PasswordHash)
+	query := `SELECT id, email, password_hash, COALESCE(email_confirmed, false), created_at, locked_until, suspended_at, COALESCE(suspended_reason, '') FROM users WHERE email_normalized = $1 AND deleted_at IS NULL`
+	err := r.db.QueryRow(ctx, query, emailNormalized).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.EmailConfirmed, &user.CreatedAt, &user.LockedUntil, &user.SuspendedAt, &user.SuspendedReason)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
@@ -56,11 +66,11 @@ func (r *UserStorage) GetUserByEmail(ctx context.Context, email string) (*models
 func (r *UserStorage) GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	var user models.User
 
-	query := `SELECT id, email, password_hash FROM users WHERE id = $1`
-	err := r.db.QueryRow(ctx, query, userID).Scan(&user.ID, &user.Email, &user.PasswordHash)
+	query := `SELECT id, email, password_hash, COALESCE(email_confirmed, false), created_at, locked_until, suspended_at, COALESCE(suspended_reason, '') FROM users WHERE id = $1 AND deleted_at IS NULL`
+	err := r.db.QueryRow(ctx, query, userID).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.EmailConfirmed, &user.CreatedAt, &user.LockedUntil, &user.SuspendedAt, &user.SuspendedReason)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
 	}
@@ -68,11 +78,11 @@ func (r *UserStorage) GetUserByID(ctx context.Context, userID uuid.UUID) (*model
 	return &user, nil
 }
 
-func (r *UserStorage) UserExists(ctx context.Context, email string) (bool, error) {
+func (r *UserStorage) UserExists(ctx context.Context, emailNormalized string) (bool, error) {
 	var exists bool
 
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
-	err := r.db.QueryRow(ctx, query, email).Scan(&exists)
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email_normalized = $1)`
+	err := r.db.QueryRow(ctx, query, emailNormalized).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check user existence: %w", err)
 	}
@@ -80,47 +90,7 @@ func (r *UserStorage) UserExists(ctx context.Context, email string) (bool, error
 	return exists, nil
 }
 
-func (r *UserStorage) SaveTokens(ctx context.Context, userID uuid.UUID, accessToken string, accessTokenExpiresAt time.Time, refreshToken string, refreshExp time.Time) error {
-	query := `
-        INSERT INTO users_tokens 
-            (user_id, access_token, access_token_expires_at, refresh_token, refresh_token_expires_at) 
-        VALUES 
-            ($1, $2, $3, $4, $5)
-        ON CONFLICT (user_id) 
-        DO UPDATE SET 
-            access_token = $2,
-            access_token_expires_at = $3,
-            refresh_token = $4,
-            refresh_token_expires_at = $5
-    `
-	_, err := r.db.Exec(ctx, query, userID, accessToken, accessTokenExpiresAt, refreshToken, refreshExp)
-	if err != nil {
-		return fmt.Errorf("failed to save tokens: %w", err)
-	}
-
-	return nil
-}
-
-func (r *UserStorage) DeleteTokens(ctx context.Context, userID uuid.UUID) error {
-	query := `
-        UPDATE users_tokens 
-        SET 
-            access_token = NULL,
-            access_token_expires_at = NULL,
-            refresh_token = NULL,
-            refresh_token_expires_at = NULL,
-            deleted_at = NOW()
-        WHERE user_id = $1
-    `
-	_, err := r.db.Exec(ctx, query, userID)
-	if err != nil {
-		return fmt.Errorf("failed to delete tokens: %w", err)
-	}
-
-	return nil
-}
-
-func (r *UserStorage) ConfirmEmail(ctx context.Context, email, code string) (uuid.UUID, error) {
+func (r *UserStorage) ConfirmEmail(ctx context.Context, emailNormalized, code string) (uuid.UUID, error) {
 	var userID uuid.UUID
 
 	query := `
@@ -131,13 +101,13 @@ func (r *UserStorage) ConfirmEmail(ctx context.Context, email, code string) (uui
 			FROM users_code
 			WHERE confirmation_code = $1 AND confirmation_code_expires_at > NOW()
 		)
-		AND email = $2
+		AND email_normalized = $2
 		RETURNING id
 	`
-	err := r.db.QueryRow(ctx, query, code, email).Scan(&userID)
+	err := r.db.QueryRow(ctx, query, code, emailNormalized).Scan(&userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return uuid.Nil, fmt.Errorf("invalid confirmation code")
+			return uuid.Nil, domain.ErrInvalidConfirmationCode
 		}
 		return uuid.Nil, fmt.Errorf("failed to confirm email: %w", err)
 	}
@@ -160,24 +130,463 @@ func (r *UserStorage) SaveConfirmationCode(ctx context.Context, userID uuid.UUID
 	return nil
 }
 
-func (r *UserStorage) GetAccessToken(ctx context.Context, userID uuid.UUID) (string, time.Time, error) {
+// SavePasswordResetToken stores a new reset token for the user. Tokens issued
+// earlier are invalidated so only the latest emailed link works.
+func (r *UserStorage) SavePasswordResetToken(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	invalidateQuery := `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, invalidateQuery, userID); err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := tx.Exec(ctx, insertQuery, userID, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to save password reset token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword consumes the reset token and replaces the password hash of its
+// owner in one transaction, returning the ID of the user.
+func (r *UserStorage) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		userID    uuid.UUID
+		expiresAt time.Time
+		usedAt    *time.Time
+	)
+
+	selectQuery := `
+		SELECT user_id, expires_at, used_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+	err = tx.QueryRow(ctx, selectQuery, tokenHash).Scan(&userID, &expiresAt, &usedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, domain.ErrPasswordResetTokenInvalid
+		}
+		return uuid.Nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+
+	if usedAt != nil {
+		return uuid.Nil, domain.ErrPasswordResetTokenInvalid
+	}
+
+	if time.Now().After(expiresAt) {
+		return uuid.Nil, domain.ErrPasswordResetTokenExpired
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET password_hash = $2 WHERE id = $1`, userID, passwordHash); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	tokenQuery := `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, tokenQuery, userID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to mark password reset token as used: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit password reset: %w", err)
+	}
+
+	return userID, nil
+}
+
+// SaveMagicLinkToken stores a new login link token for the user. Links sent
+// earlier stop working so only the latest one can be used.
+func (r *UserStorage) SaveMagicLinkToken(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	invalidateQuery := `
+		UPDATE magic_link_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, invalidateQuery, userID); err != nil {
+		return fmt.Errorf("failed to invalidate magic link tokens: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO magic_link_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := tx.Exec(ctx, insertQuery, userID, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to save magic link token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit magic link token: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeMagicLinkToken marks the token as used and returns the ID of its
+// owner. Following the link proves the user controls the email, so the email
+// is confirmed as well.
+func (r *UserStorage) ConsumeMagicLinkToken(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		tokenID   uuid.UUID
+		userID    uuid.UUID
+		expiresAt time.Time
+		usedAt    *time.Time
+	)
+
+	selectQuery := `
+		SELECT id, user_id, expires_at, used_at
+		FROM magic_link_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+	err = tx.QueryRow(ctx, selectQuery, tokenHash).Scan(&tokenID, &userID, &expiresAt, &usedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, domain.ErrMagicLinkInvalid
+		}
+		return uuid.Nil, fmt.Errorf("failed to get magic link token: %w", err)
+	}
+
+	if usedAt != nil {
+		return uuid.Nil, domain.ErrMagicLinkInvalid
+	}
+
+	if time.Now().After(expiresAt) {
+		return uuid.Nil, domain.ErrMagicLinkExpired
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE magic_link_tokens SET used_at = NOW() WHERE id = $1`, tokenID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to mark magic link token as used: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET email_confirmed = true WHERE id = $1`, userID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to confirm email: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit magic link token: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (r *UserStorage) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $2, updated_at = NOW() WHERE id = $1`
+	tag, err := r.db.Exec(ctx, query, userID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SaveEmailChange stores the pending email change of the user, replacing an
+// earlier one that was not confirmed.
+func (r *UserStorage) SaveEmailChange(ctx context.Context, userID uuid.UUID, newEmail, newEmailNormalized, codeHash string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO email_changes (user_id, new_email, new_email_normalized, confirmation_code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id)
+		DO UPDATE SET new_email = $2, new_email_normalized = $3, confirmation_code_hash = $4, expires_at = $5, created_at = NOW()
+	`
+	if _, err := r.db.Exec(ctx, query, userID, newEmail, newEmailNormalized, codeHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to save email change: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange swaps the email of the user for the pending one when the
+// code matches and returns the previous and the new address.
+func (r *UserStorage) ConfirmEmailChange(ctx context.Context, userID uuid.UUID, codeHash string) (string, string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	var (
-		accessToken    string
-		accessTokenExp time.Time
+		oldEmail           string
+		newEmail           string
+		newEmailNormalized string
+		expiresAt          time.Time
 	)
 
+	selectQuery := `
+		SELECT u.email, ec.new_email, ec.new_email_normalized, ec.expires_at
+		FROM email_changes ec
+		JOIN users u ON u.id = ec.user_id
+		WHERE ec.user_id = $1 AND ec.confirmation_code_hash = $2
+		FOR UPDATE
+	`
+	err = tx.QueryRow(ctx, selectQuery, userID, codeHash).Scan(&oldEmail, &newEmail, &newEmailNormalized, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", domain.ErrEmailChangeCodeInvalid
+		}
+		return "", "", fmt.Errorf("failed to get email change: %w", err)
+	}
+
+	if time.Now().After(expiresAt) {
+		return "", "", domain.ErrEmailChangeCodeExpired
+	}
+
+	updateQuery := `
+		UPDATE users
+		SET email = $2, email_normalized = $3, email_confirmed = true, updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, updateQuery, userID, newEmail, newEmailNormalized); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return "", "", domain.ErrEmailAlreadyExists
+		}
+		return "", "", fmt.Errorf("failed to update email: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM email_changes WHERE user_id = $1`, userID); err != nil {
+		return "", "", fmt.Errorf("failed to delete email change: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", "", fmt.Errorf("failed to commit email change: %w", err)
+	}
+
+	return oldEmail, newEmail, nil
+}
+
+func (r *UserStorage) SetLockedUntil(ctx context.Context, userID uuid.UUID, lockedUntil time.Time) error {
+	query := `UPDATE users SET locked_until = $2 WHERE id = $1`
+	if _, err := r.db.Exec(ctx, query, userID, lockedUntil); err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	return nil
+}
+
+// ListUsers returns one page of the users matching the filter, newest first,
+// together with the number of all matching users. Deleted users are left out.
+func (r *UserStorage) ListUsers(ctx context.Context, filter models.UserFilter, page, pageSize int) ([]models.User, int, error) {
+	conditions := []string{"u.deleted_at IS NULL"}
+	var args []interface{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if filter.Query != "" {
+		addCondition(`(u.email ILIKE ? OR p.username ILIKE ?)`, "%"+escapeLike(filter.Query)+"%")
+	}
+	if filter.Confirmed != nil {
+		addCondition(`COALESCE(u.email_confirmed, false) = ?`, *filter.Confirmed)
+	}
+	if filter.Locked != nil {
+		addCondition(`(u.locked_until IS NOT NULL AND u.locked_until > NOW()) = ?`, *filter.Locked)
+	}
+	if filter.Suspended != nil {
+		addCondition(`(u.suspended_at IS NOT NULL) = ?`, *filter.Suspended)
+	}
+	if filter.CreatedAfter != nil {
+		addCondition(`u.created_at >= ?`, *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		addCondition(`u.created_at < ?`, *filter.CreatedBefore)
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM users u LEFT JOIN profiles p ON p.user_id = u.id WHERE ` + where
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	args = append(args, pageSize, (page-1)*pageSize)
+	query := fmt.Sprintf(`
+		SELECT u.id, u.email, COALESCE(u.email_confirmed, false), u.created_at, u.locked_until, u.suspended_at, COALESCE(u.suspended_reason, '')
+		FROM users u
+		LEFT JOIN profiles p ON p.user_id = u.id
+		WHERE %s
+		ORDER BY u.created_at DESC, u.id
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Email, &user.EmailConfirmed, &user.CreatedAt, &user.LockedUntil, &user.SuspendedAt, &user.SuspendedReason); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// escapeLike makes the wildcards of a search term match literally.
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
+
+// SuspendUser marks the user as suspended. Suspending an already suspended
+// user only replaces the reason.
+func (r *UserStorage) SuspendUser(ctx context.Context, userID uuid.UUID, reason string, suspendedBy uuid.UUID) error {
 	query := `
-		SELECT access_token, access_token_expires_at
-		FROM users_tokens
-		WHERE user_id = $1
+		UPDATE users
+		SET suspended_at = COALESCE(suspended_at, NOW()), suspended_reason = $2, suspended_by = $3, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
 	`
-	err := r.db.QueryRow(ctx, query, userID).Scan(&accessToken, &accessTokenExp)
+	tag, err := r.db.Exec(ctx, query, userID, reason, suspendedBy)
+	if err != nil {
+		return fmt.Errorf("failed to suspend user: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UserStorage) UnsuspendUser(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET suspended_at = NULL, suspended_reason = NULL, suspended_by = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to unsuspend user: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ConfirmExternalEmail confirms the email of the user on behalf of an identity
+// provider and replaces the password hash, so a password set by whoever
+// registered the unconfirmed email cannot be used anymore.
+func (r *UserStorage) ConfirmExternalEmail(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET email_confirmed = true, password_hash = $2, updated_at = NOW() WHERE id = $1`
+	if _, err := r.db.Exec(ctx, query, userID, passwordHash); err != nil {
+		return fmt.Errorf("failed to confirm email: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUser soft-deletes the user: the row stays so the ID is never reused,
+// but the email and password are replaced and everything else that belongs to
+// the user is deleted. The email becomes free for a new registration.
+func (r *UserStorage) DeleteUser(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var deletedAt time.Time
+
+	query := `
+		UPDATE users
+		SET email = 'deleted-' || id || '@deleted.invalid',
+			email_normalized = 'deleted-' || id || '@deleted.invalid',
+			password_hash = '',
+			email_confirmed = false,
+			locked_until = NULL,
+			deleted_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING deleted_at
+	`
+	err = tx.QueryRow(ctx, query, userID).Scan(&deletedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", time.Time{}, fmt.Errorf("access token not found")
+			return time.Time{}, domain.ErrUserNotFound
 		}
-		return "", time.Time{}, fmt.Errorf("failed to get access token: %w", err)
+		return time.Time{}, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	cleanup := []string{
+		`DELETE FROM sessions WHERE user_id = $1`,
+		`DELETE FROM users_code WHERE user_id = $1`,
+		`DELETE FROM password_reset_tokens WHERE user_id = $1`,
+		`DELETE FROM magic_link_tokens WHERE user_id = $1`,
+		`DELETE FROM email_changes WHERE user_id = $1`,
+		`DELETE FROM mfa_recovery_codes WHERE user_id = $1`,
+		`DELETE FROM user_mfa WHERE user_id = $1`,
+		`DELETE FROM identities WHERE user_id = $1`,
+		`DELETE FROM profiles WHERE user_id = $1`,
+		`DELETE FROM user_roles WHERE user_id = $1`,
+		`DELETE FROM oauth_authorization_codes WHERE user_id = $1`,
+		`DELETE FROM oauth_grants WHERE user_id = $1`,
+		`DELETE FROM oauth_consents WHERE user_id = $1`,
+		`UPDATE oauth_clients SET owner_user_id = NULL WHERE owner_user_id = $1`,
+	}
+	for _, query := range cleanup {
+		if _, err := tx.Exec(ctx, query, userID); err != nil {
+			return time.Time{}, fmt.Errorf("failed to delete user data: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit user deletion: %w", err)
 	}
 
-	return accessToken, accessTokenExp, nil
+	return deletedAt, nil
 }
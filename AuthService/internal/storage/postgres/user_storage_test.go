@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"Project/AuthService/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestConsumeMagicLinkToken(t *testing.T) {
+	type link struct {
+		hash string
+		ttl  time.Duration
+	}
+
+	type consumption struct {
+		hash    string
+		wantErr error
+	}
+
+	tests := []struct {
+		name         string
+		links        []link
+		consumptions []consumption
+		// wantConfirmed means the email of the user ends up confirmed.
+		wantConfirmed bool
+	}{
+		{
+			name:  "link works once",
+			links: []link{{hash: "first", ttl: time.Hour}},
+			consumptions: []consumption{
+				{hash: "first"},
+				{hash: "first", wantErr: domain.ErrMagicLinkInvalid},
+			},
+			wantConfirmed: true,
+		},
+		{
+			name:  "expired link",
+			links: []link{{hash: "first", ttl: -time.Minute}},
+			consumptions: []consumption{
+				{hash: "first", wantErr: domain.ErrMagicLinkExpired},
+			},
+		},
+		{
+			name:  "newer link replaces the earlier one",
+			links: []link{{hash: "first", ttl: time.Hour}, {hash: "second", ttl: time.Hour}},
+			consumptions: []consumption{
+				{hash: "first", wantErr: domain.ErrMagicLinkInvalid},
+				{hash: "second"},
+			},
+			wantConfirmed: true,
+		},
+		{
+			name:  "unknown link",
+			links: []link{{hash: "first", ttl: time.Hour}},
+			consumptions: []consumption{
+				{hash: "unknown", wantErr: domain.ErrMagicLinkInvalid},
+			},
+		},
+	}
+
+	db := testDB(t)
+	users, _ := NewUserStorage(db)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			email := uuid.NewString() + "@example.com"
+			userID, err := users.CreateUser(ctx, email, email, "hash")
+			if err != nil {
+				t.Fatalf("failed to create user: %v", err)
+			}
+
+			// Hashes are unique across users, so they are prefixed with the user.
+			hash := func(name string) string { return userID.String() + "-" + name }
+
+			for _, l := range tt.links {
+				if err := users.SaveMagicLinkToken(ctx, userID, hash(l.hash), time.Now().Add(l.ttl)); err != nil {
+					t.Fatalf("failed to save magic link token: %v", err)
+				}
+			}
+
+			for i, c := range tt.consumptions {
+				owner, err := users.ConsumeMagicLinkToken(ctx, hash(c.hash))
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("consumption %d of %s: error = %v, want %v", i, c.hash, err, c.wantErr)
+				}
+				if c.wantErr == nil && owner != userID {
+					t.Errorf("consumption %d returned user %s, want %s", i, owner, userID)
+				}
+			}
+
+			user, err := users.GetUserByID(ctx, userID)
+			if err != nil {
+				t.Fatalf("failed to get user: %v", err)
+			}
+			if user.EmailConfirmed != tt.wantConfirmed {
+				t.Errorf("email confirmed = %t, want %t", user.EmailConfirmed, tt.wantConfirmed)
+			}
+		})
+	}
+}
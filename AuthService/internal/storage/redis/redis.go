@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -24,19 +25,101 @@ type TokenCacheRepository struct{//зачем мне это??
 	
 }
 
-func getAccessTokenKey(userID string) string {
-	return fmt.Sprintf("access_token:%s", userID)
+func getAccessTokenKey(sessionID string) string {
+	return fmt.Sprintf("access_token:%s", sessionID)
+}
+
+func getCooldownKey(key string) string {
+	return fmt.Sprintf("cooldown:%s", key)
+}
+
+func getMFAChallengeKey(challengeHash string) string {
+	return fmt.Sprintf("mfa_challenge:%s", challengeHash)
+}
+
+func getCounterKey(key string) string {
+	return fmt.Sprintf("counter:%s", key)
+}
+
+func getLockKey(key string) string {
+	return fmt.Sprintf("lock:%s", key)
 }
 
 func (c *Client) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	key=getAccessTokenKey(key)
+	key = getAccessTokenKey(key)
 	return c.client.Set(ctx, key, value, ttl).Err()
 }
 
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	key = getAccessTokenKey(key)
 	return c.client.Get(ctx, key).Result()
 }
 
-func (c *Client) Delete(ctx context.Context, key string) error {
-	return c.client.Del(ctx, key).Err()
+func (c *Client) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, getAccessTokenKey(key))
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+// AcquireCooldown starts a cooldown for key and reports whether it was free.
+// While the cooldown lasts every further call returns false.
+func (c *Client) AcquireCooldown(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, getCooldownKey(key), 1, ttl).Result()
+}
+
+func (c *Client) SaveMFAChallenge(ctx context.Context, challengeHash, userID string, ttl time.Duration) error {
+	return c.client.Set(ctx, getMFAChallengeKey(challengeHash), userID, ttl).Err()
+}
+
+func (c *Client) GetMFAChallenge(ctx context.Context, challengeHash string) (string, error) {
+	return c.client.Get(ctx, getMFAChallengeKey(challengeHash)).Result()
+}
+
+func (c *Client) DeleteMFAChallenge(ctx context.Context, challengeHash string) error {
+	return c.client.Del(ctx, getMFAChallengeKey(challengeHash)).Err()
+}
+
+// incrementCounter bumps a counter and starts its expiry on the first
+// increment in one step, so a counter can never be left without a TTL.
+var incrementCounter = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// Increment bumps the counter under key and returns its new value. The counter
+// expires ttl after its first increment.
+func (c *Client) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrementCounter.Run(ctx, c.client, []string{getCounterKey(key)}, ttl.Milliseconds()).Int64()
+}
+
+// Counter returns the current value of the counter under key, zero if unset.
+func (c *Client) Counter(ctx context.Context, key string) (int64, error) {
+	count, err := c.client.Get(ctx, getCounterKey(key)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return count, err
+}
+
+func (c *Client) ResetCounter(ctx context.Context, key string) error {
+	return c.client.Del(ctx, getCounterKey(key)).Err()
+}
+
+func (c *Client) Lock(ctx context.Context, key string, ttl time.Duration) error {
+	return c.client.Set(ctx, getLockKey(key), 1, ttl).Err()
+}
+
+func (c *Client) IsLocked(ctx context.Context, key string) (bool, error) {
+	count, err := c.client.Exists(ctx, getLockKey(key)).Result()
+	return count > 0, err
 }
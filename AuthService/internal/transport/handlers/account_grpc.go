@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+
+	"Project/AuthService/internal/domain/models"
+	"Project/proto/gen"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *AuthHandlers) DeleteAccount(ctx context.Context, req *gen.DeleteAccountRequest) (*gen.DeleteAccountResponse, error) {
+	h.logger.Info("Deleting account")
+
+	if err := h.service.DeleteAccount(ctx, req.AccessToken, req.Password); err != nil {
+		h.logger.Error("Failed to delete account", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.DeleteAccountResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) ExportMyData(ctx context.Context, req *gen.ExportMyDataRequest) (*gen.ExportMyDataResponse, error) {
+	h.logger.Info("Exporting user data")
+
+	export, err := h.service.ExportMyData(ctx, req.AccessToken)
+	if err != nil {
+		h.logger.Error("Failed to export user data", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return mapUserExportToExportMyDataResponse(export), nil
+}
+
+func mapUserExportToExportMyDataResponse(export *models.UserExport) *gen.ExportMyDataResponse {
+	response := &gen.ExportMyDataResponse{
+		User:           mapUserToMeResponse(export.User, export.Access).User,
+		EmailConfirmed: export.User.EmailConfirmed,
+		CreatedAt:      export.User.CreatedAt.Unix(),
+		Roles:          mapUserRolesToListUserRolesResponse(export.Roles).Roles,
+		Sessions:       mapSessionsToListSessionsResponse(export.Sessions, uuid.Nil).Sessions,
+		Identities:     make([]*gen.ExternalIdentity, 0, len(export.Identities)),
+		Profile:        mapProfile(export.Profile),
+	}
+
+	for _, identity := range export.Identities {
+		response.Identities = append(response.Identities, &gen.ExternalIdentity{
+			Provider: identity.Provider,
+			Email:    identity.Email,
+			LinkedAt: identity.CreatedAt.Unix(),
+		})
+	}
+
+	return response
+}
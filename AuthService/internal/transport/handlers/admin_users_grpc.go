@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"Project/AuthService/internal/domain/models"
+	"Project/proto/gen"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *AuthHandlers) ListUsers(ctx context.Context, req *gen.ListUsersRequest) (*gen.ListUsersResponse, error) {
+	filter := models.UserFilter{
+		Query:         req.Query,
+		Confirmed:     req.Confirmed,
+		Locked:        req.Locked,
+		Suspended:     req.Suspended,
+		CreatedAfter:  unixTime(req.CreatedAfter),
+		CreatedBefore: unixTime(req.CreatedBefore),
+	}
+
+	users, total, err := h.service.ListUsers(ctx, req.AccessToken, filter, int(req.Page), int(req.PageSize))
+	if err != nil {
+		h.logger.Error("Failed to list users", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	response := &gen.ListUsersResponse{
+		Users:      make([]*gen.AdminUser, 0, len(users)),
+		TotalUsers: int32(total),
+	}
+	for _, user := range users {
+		response.Users = append(response.Users, mapAdminUser(user))
+	}
+
+	return response, nil
+}
+
+func mapAdminUser(user models.User) *gen.AdminUser {
+	adminUser := &gen.AdminUser{
+		Id:              user.ID.String(),
+		Email:           user.Email,
+		EmailConfirmed:  user.EmailConfirmed,
+		CreatedAt:       user.CreatedAt.Unix(),
+		SuspendedReason: user.SuspendedReason,
+	}
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		adminUser.LockedUntil = user.LockedUntil.Unix()
+	}
+	if user.SuspendedAt != nil {
+		adminUser.SuspendedAt = user.SuspendedAt.Unix()
+	}
+
+	return adminUser
+}
+
+func (h *AuthHandlers) SuspendUser(ctx context.Context, req *gen.SuspendUserRequest) (*gen.SuspendUserResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, invalidArgument("user_id", "must be a valid UUID")
+	}
+
+	if err := h.service.SuspendUser(ctx, req.AccessToken, userID, req.Reason); err != nil {
+		h.logger.Error("Failed to suspend user", zap.String("userID", userID.String()), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.SuspendUserResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) UnsuspendUser(ctx context.Context, req *gen.UnsuspendUserRequest) (*gen.UnsuspendUserResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, invalidArgument("user_id", "must be a valid UUID")
+	}
+
+	if err := h.service.UnsuspendUser(ctx, req.AccessToken, userID); err != nil {
+		h.logger.Error("Failed to unsuspend user", zap.String("userID", userID.String()), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.UnsuspendUserResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) ForceLogout(ctx context.Context, req *gen.ForceLogoutRequest) (*gen.ForceLogoutResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, invalidArgument("user_id", "must be a valid UUID")
+	}
+
+	revoked, err := h.service.ForceLogout(ctx, req.AccessToken, userID)
+	if err != nil {
+		h.logger.Error("Failed to log out user", zap.String("userID", userID.String()), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.ForceLogoutResponse{Revoked: int32(revoked)}, nil
+}
+
+func (h *AuthHandlers) ImpersonateUser(ctx context.Context, req *gen.ImpersonateUserRequest) (*gen.ImpersonateUserResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, invalidArgument("user_id", "must be a valid UUID")
+	}
+
+	client := clientInfoFromContext(ctx, "")
+
+	accessToken, expiresAt, err := h.service.ImpersonateUser(ctx, req.AccessToken, userID, req.Reason, client)
+	if err != nil {
+		h.logger.Error("Failed to impersonate user", zap.String("userID", userID.String()), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.ImpersonateUserResponse{AccessToken: accessToken, ExpiresAt: expiresAt.Unix()}, nil
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"Project/AuthService/internal/domain/models"
+	"Project/proto/gen"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *AuthHandlers) ListMyAuthEvents(ctx context.Context, req *gen.ListMyAuthEventsRequest) (*gen.ListAuthEventsResponse, error) {
+	events, total, err := h.service.ListMyAuthEvents(ctx, req.AccessToken, unixTime(req.From), unixTime(req.To), int(req.Page), int(req.PageSize))
+	if err != nil {
+		h.logger.Error("Failed to list auth events", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return mapAuthEventsToResponse(events, total), nil
+}
+
+func (h *AuthHandlers) ListAuthEvents(ctx context.Context, req *gen.ListAuthEventsRequest) (*gen.ListAuthEventsResponse, error) {
+	filter := models.AuthEventFilter{
+		Email:   req.Email,
+		Event:   req.Event,
+		Outcome: req.Outcome,
+		From:    unixTime(req.From),
+		To:      unixTime(req.To),
+	}
+
+	if req.UserId != "" {
+		userID, err := uuid.Parse(req.UserId)
+		if err != nil {
+			return nil, invalidArgument("user_id", "must be a valid UUID")
+		}
+		filter.UserID = userID
+	}
+
+	events, total, err := h.service.ListAuthEvents(ctx, req.AccessToken, filter, int(req.Page), int(req.PageSize))
+	if err != nil {
+		h.logger.Error("Failed to list auth events", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return mapAuthEventsToResponse(events, total), nil
+}
+
+// unixTime turns an optional Unix timestamp of a request into a time; 0 is nil.
+func unixTime(seconds int64) *time.Time {
+	if seconds == 0 {
+		return nil
+	}
+
+	t := time.Unix(seconds, 0)
+	return &t
+}
+
+func mapAuthEventsToResponse(events []models.AuthEvent, total int) *gen.ListAuthEventsResponse {
+	response := &gen.ListAuthEventsResponse{
+		Events:      make([]*gen.AuthEvent, 0, len(events)),
+		TotalEvents: int32(total),
+	}
+
+	for _, event := range events {
+		authEvent := &gen.AuthEvent{
+			Id:        event.ID.String(),
+			Email:     event.Email,
+			Event:     event.Event,
+			Outcome:   event.Outcome,
+			Reason:    event.Reason,
+			Ip:        event.IP,
+			UserAgent: event.UserAgent,
+			CreatedAt: event.CreatedAt.Unix(),
+		}
+		if event.UserID != uuid.Nil {
+			authEvent.UserId = event.UserID.String()
+		}
+		if event.SessionID != uuid.Nil {
+			authEvent.SessionId = event.SessionID.String()
+		}
+		response.Events = append(response.Events, authEvent)
+	}
+
+	return response
+}
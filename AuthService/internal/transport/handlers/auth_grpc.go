@@ -2,8 +2,6 @@ package handlers
 
 import (
 	"context"
-	
-	"fmt"
 	"time"
 
 	"Project/AuthService/internal/domain/models"
@@ -30,9 +28,9 @@ func NewAuthHandlers(service *service.AuthenticationService, logger *zap.Logger)
 func (h *AuthHandlers) Register(ctx context.Context, req *gen.RegisterRequest) (*gen.RegisterResponse, error) {
 	h.logger.Info("Registering new user", zap.String("email", req.Email))
 
-	if err := h.service.Register(ctx, req.Email, req.Password, req.RepeatPassword); err != nil {
-		h.logger.Error("Failed to register user", zap.String("email", req.Email),zap.String("password",req.Password),zap.String("repeatpassword",req.RepeatPassword))
-		return nil, fmt.Errorf("failed to register user: %w", err)
+	if err := h.service.Register(ctx, req.Email, req.Password, req.RepeatPassword, clientInfoFromContext(ctx, "")); err != nil {
+		h.logger.Error("Failed to register user", zap.String("email", req.Email), zap.Error(err))
+		return nil, toStatus(err)
 	}
 
 	h.logger.Info("User registered successfully", zap.String("email", req.Email))
@@ -43,63 +41,120 @@ func (h *AuthHandlers) Register(ctx context.Context, req *gen.RegisterRequest) (
 func (h *AuthHandlers) Login(ctx context.Context, req *gen.LoginRequest) (*gen.LoginResponse, error) {
 	h.logger.Info("Logging in user", zap.String("email", req.Email))
 
-	user, accessToken, refreshToken, err := h.service.Login(ctx, req.Email, req.Password)
+	client := clientInfoFromContext(ctx, req.DeviceName)
+
+	result, err := h.service.Login(ctx, req.Email, req.Password, client)
 	if err != nil {
 		h.logger.Error("Failed to login user", zap.String("email", req.Email), zap.Error(err))
-		return nil, fmt.Errorf("failed to login user: %w", err)
+		return nil, toStatus(err)
 	}
 
-	response := mapUserToLoginResponse(user, accessToken, refreshToken, h.service.AccessTokenTTL, h.service.RefreshTokenTTL)
+	response := mapLoginResultToLoginResponse(result, h.service.AccessTokenTTL, h.service.RefreshTokenTTL)
 
-	h.logger.Info("User logged in successfully", zap.String("userID", user.ID.String()), zap.String("email", user.Email))
+	if result.MFARequired {
+		h.logger.Info("Second factor required", zap.String("userID", result.User.ID.String()))
+		return response, nil
+	}
+
+	h.logger.Info("User logged in successfully", zap.String("userID", result.User.ID.String()), zap.String("email", result.User.Email))
 	return response, nil
-	
 }
 
-func mapUserToLoginResponse(user *models.User, accessToken, refreshToken string, accessTokenTTL, refreshTokenTTL time.Duration) *gen.LoginResponse {
-	return &gen.LoginResponse{
-		User: &gen.User{
-			Id:    user.ID.String(),
-			Email: user.Email,
-		},
-		AccessToken: &gen.AccessToken{
-			Token:     accessToken,
-			ExpiresAt: time.Now().Add(accessTokenTTL).Unix(),
-		},
-		RefreshToken: &gen.RefreshToken{
-			Token:     refreshToken,
-			ExpiresAt: time.Now().Add(refreshTokenTTL).Unix(),
-		},
+func (h *AuthHandlers) LoginMFA(ctx context.Context, req *gen.LoginMFARequest) (*gen.LoginResponse, error) {
+	h.logger.Info("Verifying second factor")
+
+	client := clientInfoFromContext(ctx, req.DeviceName)
+
+	result, err := h.service.LoginMFA(ctx, req.MfaToken, req.Code, client)
+	if err != nil {
+		h.logger.Error("Failed to verify second factor", zap.Error(err))
+		return nil, toStatus(err)
 	}
+
+	h.logger.Info("User logged in successfully", zap.String("userID", result.User.ID.String()), zap.String("email", result.User.Email))
+	return mapLoginResultToLoginResponse(result, h.service.AccessTokenTTL, h.service.RefreshTokenTTL), nil
 }
 
-func (h *AuthHandlers) Logout(ctx context.Context, req *gen.LogoutRequest) (*gen.LogoutResponse, error) {
-	userID, err := uuid.Parse(req.Id)
+func (h *AuthHandlers) LoginWithExternalIdentity(ctx context.Context, req *gen.LoginWithExternalIdentityRequest) (*gen.LoginResponse, error) {
+	h.logger.Info("Logging in with external identity", zap.String("provider", req.Provider))
+
+	if req.Provider == "" {
+		return nil, invalidArgument("provider", "must not be empty")
+	}
+	if req.Subject == "" {
+		return nil, invalidArgument("subject", "must not be empty")
+	}
+
+	client := clientInfoFromContext(ctx, req.DeviceName)
+
+	result, err := h.service.LoginWithExternalIdentity(ctx, req.Provider, req.Subject, req.Email, req.EmailVerified, client)
 	if err != nil {
-		h.logger.Error("Invalid user ID", zap.String("userID", req.Id), zap.Error(err))
-		return nil, fmt.Errorf("invalid user ID: %w", err)
+		h.logger.Error("Failed to login with external identity", zap.String("provider", req.Provider), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	h.logger.Info("User logged in successfully", zap.String("userID", result.User.ID.String()), zap.String("provider", req.Provider))
+	return mapLoginResultToLoginResponse(result, h.service.AccessTokenTTL, h.service.RefreshTokenTTL), nil
+}
+
+func mapLoginResultToLoginResponse(result *models.LoginResult, accessTokenTTL, refreshTokenTTL time.Duration) *gen.LoginResponse {
+	response := &gen.LoginResponse{
+		User: &gen.User{
+			Id:    result.User.ID.String(),
+			Email: result.User.Email,
+		},
+	}
+
+	if result.MFARequired {
+		response.MfaRequired = true
+		response.MfaToken = result.MFAToken
+		return response
+	}
+
+	response.AccessToken = &gen.AccessToken{
+		Token:     result.AccessToken,
+		ExpiresAt: time.Now().Add(accessTokenTTL).Unix(),
+	}
+	response.RefreshToken = &gen.RefreshToken{
+		Token:     result.RefreshToken,
+		ExpiresAt: time.Now().Add(refreshTokenTTL).Unix(),
 	}
 
-	h.logger.Info("Logging out user", zap.String("userID", userID.String()))
+	return response
+}
+
+func (h *AuthHandlers) Logout(ctx context.Context, req *gen.LogoutRequest) (*gen.LogoutResponse, error) {
+	h.logger.Info("Logging out user")
 
-	if err := h.service.Logout(ctx, userID); err != nil {
-		h.logger.Error("Failed to logout user", zap.String("userID", userID.String()), zap.Error(err))
-		return nil, fmt.Errorf("failed to logout user: %w", err)
+	if err := h.service.Logout(ctx, req.AccessToken, clientInfoFromContext(ctx, "")); err != nil {
+		h.logger.Error("Failed to logout user", zap.Error(err))
+		return nil, toStatus(err)
 	}
 
-	h.logger.Info("User logged out successfully", zap.String("userID", userID.String()))
+	h.logger.Info("User logged out successfully")
 	return &gen.LogoutResponse{Success: true}, nil
 }
 
+func (h *AuthHandlers) LogoutAll(ctx context.Context, req *gen.LogoutAllRequest) (*gen.LogoutAllResponse, error) {
+	h.logger.Info("Logging out user everywhere")
 
+	revoked, err := h.service.LogoutAll(ctx, req.AccessToken, clientInfoFromContext(ctx, ""))
+	if err != nil {
+		h.logger.Error("Failed to logout user everywhere", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	h.logger.Info("User logged out everywhere", zap.Int("revoked", revoked))
+	return &gen.LogoutAllResponse{Revoked: int32(revoked)}, nil
+}
 
 func (h *AuthHandlers) Refresh(ctx context.Context, req *gen.RefreshRequest) (*gen.RefreshResponse, error) {
-	h.logger.Info("Refreshing tokens", zap.String("refreshToken", req.RefreshToken))
+	h.logger.Info("Refreshing tokens")
 
-	user, accessToken, refreshToken, err := h.service.Refresh(ctx, req.RefreshToken)
+	user, accessToken, refreshToken, err := h.service.Refresh(ctx, req.RefreshToken, clientInfoFromContext(ctx, ""))
 	if err != nil {
-		h.logger.Error("Failed to refresh tokens", zap.String("refreshToken", req.RefreshToken), zap.Error(err))
-		return nil, fmt.Errorf("failed to refresh tokens: %w", err)
+		h.logger.Error("Failed to refresh tokens", zap.Error(err))
+		return nil, toStatus(err)
 	}
 
 	response := mapTokensToRefreshResponse(accessToken, refreshToken, h.service.AccessTokenTTL, h.service.RefreshTokenTTL)
@@ -124,23 +179,25 @@ func mapTokensToRefreshResponse(accessToken, refreshToken string, accessTokenTTL
 func (h *AuthHandlers) Me(ctx context.Context, req *gen.MeRequest) (*gen.MeResponse, error) {
 	h.logger.Info("Fetching user info", zap.String("accessToken", req.AccessToken))
 
-	user, err := h.service.Me(ctx, req.AccessToken)
+	user, access, err := h.service.Me(ctx, req.AccessToken)
 	if err != nil {
 		h.logger.Error("Failed to fetch user info", zap.String("accessToken", req.AccessToken), zap.Error(err))
-		return nil, fmt.Errorf("failed to fetch user info: %w", err)
+		return nil, toStatus(err)
 	}
 
-	response := mapUserToMeResponse(user)
+	response := mapUserToMeResponse(user, access)
 
 	h.logger.Info("User info fetched successfully", zap.String("userID", user.ID.String()), zap.String("email", user.Email))
 	return response, nil
 }
 
-func mapUserToMeResponse(user *models.User)*gen.MeResponse{
+func mapUserToMeResponse(user *models.User, access *models.UserAccess)*gen.MeResponse{
 	return &gen.MeResponse{
 		User: &gen.User{
-			Id:    user.ID.String(), 
-			Email: user.Email,
+			Id:          user.ID.String(), 
+			Email:       user.Email,
+			Roles:       access.Roles,
+			Permissions: access.Permissions,
 		},
 	}
 }
@@ -148,12 +205,227 @@ func mapUserToMeResponse(user *models.User)*gen.MeResponse{
 func (h *AuthHandlers) ConfirmEmail(ctx context.Context, req *gen.ConfirmEmailRequest) (*gen.ConfirmEmailResponse, error) {
 	h.logger.Info("Confirming email", zap.String("email", req.Email), zap.String("confirmation_code", req.ConfirmationCode))
 
-	userID, err := h.service.ConfirmEmail(ctx, req.Email, req.ConfirmationCode)
+	userID, err := h.service.ConfirmEmail(ctx, req.Email, req.ConfirmationCode, clientInfoFromContext(ctx, ""))
 	if err != nil {
 		h.logger.Error("Failed to confirm email", zap.String("email", req.Email), zap.Error(err))
-		return nil, fmt.Errorf("failed to confirm email: %w", err)
+		return nil, toStatus(err)
 	}
 	
 	h.logger.Info("Email confirmed successfully", zap.String("userID", userID.String()))
 	return &gen.ConfirmEmailResponse{Success: true}, nil
 }
+
+func (h *AuthHandlers) ResendConfirmationCode(ctx context.Context, req *gen.ResendConfirmationCodeRequest) (*gen.ResendConfirmationCodeResponse, error) {
+	h.logger.Info("Resending confirmation code", zap.String("email", req.Email))
+
+	if err := h.service.ResendConfirmationCode(ctx, req.Email); err != nil {
+		h.logger.Error("Failed to resend confirmation code", zap.String("email", req.Email), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.ResendConfirmationCodeResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) ListSessions(ctx context.Context, req *gen.ListSessionsRequest) (*gen.ListSessionsResponse, error) {
+	h.logger.Info("Listing sessions")
+
+	sessions, currentSessionID, err := h.service.ListSessions(ctx, req.AccessToken)
+	if err != nil {
+		h.logger.Error("Failed to list sessions", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return mapSessionsToListSessionsResponse(sessions, currentSessionID), nil
+}
+
+func mapSessionsToListSessionsResponse(sessions []models.Session, currentSessionID uuid.UUID) *gen.ListSessionsResponse {
+	response := &gen.ListSessionsResponse{
+		Sessions: make([]*gen.Session, 0, len(sessions)),
+	}
+
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, &gen.Session{
+			Id:         session.ID.String(),
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			Ip:         session.IP,
+			CreatedAt:  session.CreatedAt.Unix(),
+			LastUsedAt: session.LastUsedAt.Unix(),
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return response
+}
+
+func (h *AuthHandlers) RevokeSession(ctx context.Context, req *gen.RevokeSessionRequest) (*gen.RevokeSessionResponse, error) {
+	sessionID, err := uuid.Parse(req.SessionId)
+	if err != nil {
+		h.logger.Error("Invalid session ID", zap.String("sessionID", req.SessionId), zap.Error(err))
+		return nil, invalidArgument("session_id", "must be a valid UUID")
+	}
+
+	h.logger.Info("Revoking session", zap.String("sessionID", sessionID.String()))
+
+	if err := h.service.RevokeSession(ctx, req.AccessToken, sessionID); err != nil {
+		h.logger.Error("Failed to revoke session", zap.String("sessionID", sessionID.String()), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	h.logger.Info("Session revoked successfully", zap.String("sessionID", sessionID.String()))
+	return &gen.RevokeSessionResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) RevokeAllOtherSessions(ctx context.Context, req *gen.RevokeAllOtherSessionsRequest) (*gen.RevokeAllOtherSessionsResponse, error) {
+	h.logger.Info("Revoking all other sessions")
+
+	revoked, err := h.service.RevokeAllOtherSessions(ctx, req.AccessToken)
+	if err != nil {
+		h.logger.Error("Failed to revoke other sessions", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	h.logger.Info("Other sessions revoked successfully", zap.Int("revoked", revoked))
+	return &gen.RevokeAllOtherSessionsResponse{Revoked: int32(revoked)}, nil
+}
+
+func (h *AuthHandlers) GetJWKS(ctx context.Context, req *gen.GetJWKSRequest) (*gen.GetJWKSResponse, error) {
+	keys := h.service.JWKS()
+
+	response := &gen.GetJWKSResponse{
+		Keys: make([]*gen.JSONWebKey, 0, len(keys)),
+	}
+
+	for _, key := range keys {
+		response.Keys = append(response.Keys, &gen.JSONWebKey{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Alg: key.Alg,
+			Use: key.Use,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Crv,
+			X:   key.X,
+		})
+	}
+
+	return response, nil
+}
+
+func (h *AuthHandlers) RequestPasswordReset(ctx context.Context, req *gen.RequestPasswordResetRequest) (*gen.RequestPasswordResetResponse, error) {
+	h.logger.Info("Requesting password reset", zap.String("email", req.Email))
+
+	if err := h.service.RequestPasswordReset(ctx, req.Email); err != nil {
+		h.logger.Error("Failed to request password reset", zap.String("email", req.Email), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.RequestPasswordResetResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) ResetPassword(ctx context.Context, req *gen.ResetPasswordRequest) (*gen.ResetPasswordResponse, error) {
+	h.logger.Info("Resetting password")
+
+	if err := h.service.ResetPassword(ctx, req.Token, req.NewPassword, clientInfoFromContext(ctx, "")); err != nil {
+		h.logger.Error("Failed to reset password", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	h.logger.Info("Password reset successfully")
+	return &gen.ResetPasswordResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) RequestMagicLink(ctx context.Context, req *gen.RequestMagicLinkRequest) (*gen.RequestMagicLinkResponse, error) {
+	h.logger.Info("Requesting magic link", zap.String("email", req.Email))
+
+	if err := h.service.RequestMagicLink(ctx, req.Email); err != nil {
+		h.logger.Error("Failed to request magic link", zap.String("email", req.Email), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.RequestMagicLinkResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) ConsumeMagicLink(ctx context.Context, req *gen.ConsumeMagicLinkRequest) (*gen.LoginResponse, error) {
+	h.logger.Info("Logging in with magic link")
+
+	if req.Token == "" {
+		return nil, invalidArgument("token", "is required")
+	}
+
+	result, err := h.service.ConsumeMagicLink(ctx, req.Token, clientInfoFromContext(ctx, req.DeviceName))
+	if err != nil {
+		h.logger.Error("Failed to log in with magic link", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	response := mapLoginResultToLoginResponse(result, h.service.AccessTokenTTL, h.service.RefreshTokenTTL)
+
+	if result.MFARequired {
+		h.logger.Info("Second factor required", zap.String("userID", result.User.ID.String()))
+		return response, nil
+	}
+
+	h.logger.Info("User logged in with magic link", zap.String("userID", result.User.ID.String()))
+	return response, nil
+}
+
+func (h *AuthHandlers) ChangePassword(ctx context.Context, req *gen.ChangePasswordRequest) (*gen.ChangePasswordResponse, error) {
+	h.logger.Info("Changing password")
+
+	if err := h.service.ChangePassword(ctx, req.AccessToken, req.OldPassword, req.NewPassword, clientInfoFromContext(ctx, "")); err != nil {
+		h.logger.Error("Failed to change password", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	h.logger.Info("Password changed successfully")
+	return &gen.ChangePasswordResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) ChangeEmail(ctx context.Context, req *gen.ChangeEmailRequest) (*gen.ChangeEmailResponse, error) {
+	h.logger.Info("Changing email", zap.String("newEmail", req.NewEmail))
+
+	if err := h.service.ChangeEmail(ctx, req.AccessToken, req.NewEmail); err != nil {
+		h.logger.Error("Failed to change email", zap.String("newEmail", req.NewEmail), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.ChangeEmailResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) ConfirmEmailChange(ctx context.Context, req *gen.ConfirmEmailChangeRequest) (*gen.ConfirmEmailChangeResponse, error) {
+	h.logger.Info("Confirming email change")
+
+	if err := h.service.ConfirmEmailChange(ctx, req.AccessToken, req.ConfirmationCode); err != nil {
+		h.logger.Error("Failed to confirm email change", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	h.logger.Info("Email changed successfully")
+	return &gen.ConfirmEmailChangeResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) Enable2FA(ctx context.Context, req *gen.Enable2FARequest) (*gen.Enable2FAResponse, error) {
+	h.logger.Info("Enabling two-factor authentication")
+
+	secret, uri, err := h.service.Enable2FA(ctx, req.AccessToken)
+	if err != nil {
+		h.logger.Error("Failed to enable two-factor authentication", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.Enable2FAResponse{Secret: secret, OtpauthUri: uri}, nil
+}
+
+func (h *AuthHandlers) Verify2FA(ctx context.Context, req *gen.Verify2FARequest) (*gen.Verify2FAResponse, error) {
+	h.logger.Info("Verifying two-factor authentication setup")
+
+	recoveryCodes, err := h.service.Verify2FA(ctx, req.AccessToken, req.Code)
+	if err != nil {
+		h.logger.Error("Failed to verify two-factor authentication setup", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	h.logger.Info("Two-factor authentication enabled")
+	return &gen.Verify2FAResponse{RecoveryCodes: recoveryCodes}, nil
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"Project/AuthService/internal/domain/models"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	forwardedForKey = "x-forwarded-for"
+	userAgentKey    = "x-user-agent"
+)
+
+// clientInfoFromContext collects the device description of the caller. The gateway
+// forwards the original client address and user agent in metadata; direct gRPC
+// callers fall back to the peer address and the gRPC user agent.
+func clientInfoFromContext(ctx context.Context, deviceName string) models.ClientInfo {
+	client := models.ClientInfo{DeviceName: deviceName}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(forwardedForKey); len(values) > 0 {
+			client.IP = strings.TrimSpace(strings.Split(values[0], ",")[0])
+		}
+
+		if values := md.Get(userAgentKey); len(values) > 0 {
+			client.UserAgent = values[0]
+		} else if values := md.Get("user-agent"); len(values) > 0 {
+			client.UserAgent = values[0]
+		}
+	}
+
+	if client.IP == "" {
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			client.IP = p.Addr.String()
+			if host, _, err := net.SplitHostPort(client.IP); err == nil {
+				client.IP = host
+			}
+		}
+	}
+
+	return client
+}
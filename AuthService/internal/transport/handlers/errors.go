@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+
+	"Project/AuthService/internal/domain"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus converts a service error into a gRPC status. Domain errors keep their
+// message, anything unexpected becomes codes.Internal without details so
+// internals do not leak to clients.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationStatus(validationErr)
+	}
+
+	var oauthErr *domain.OAuthError
+	if errors.As(err, &oauthErr) {
+		return oauthStatus(oauthErr)
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrSessionNotFound),
+		errors.Is(err, domain.ErrOAuthConsentNotFound),
+		errors.Is(err, domain.ErrRoleNotFound),
+		errors.Is(err, domain.ErrRoleNotAssigned),
+		errors.Is(err, domain.ErrProfileNotFound):
+		return status.Error(codes.NotFound, err.Error())
+
+	case errors.Is(err, domain.ErrEmailAlreadyExists),
+		errors.Is(err, domain.ErrMFAAlreadyEnabled),
+		errors.Is(err, domain.ErrIdentityAlreadyLinked),
+		errors.Is(err, domain.ErrRoleAlreadyAssigned),
+		errors.Is(err, domain.ErrUsernameTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+
+	case errors.Is(err, domain.ErrUnauthenticated),
+		errors.Is(err, domain.ErrInvalidCredentials),
+		errors.Is(err, domain.ErrInvalidMFAChallenge),
+		errors.Is(err, domain.ErrRefreshTokenNotFound),
+		errors.Is(err, domain.ErrRefreshTokenExpired),
+		errors.Is(err, domain.ErrRefreshTokenReused):
+		return status.Error(codes.Unauthenticated, unwrapMessage(err))
+
+	case errors.Is(err, domain.ErrPermissionDenied),
+		errors.Is(err, domain.ErrAccountSuspended),
+		errors.Is(err, domain.ErrImpersonationNotAllowed):
+		return status.Error(codes.PermissionDenied, err.Error())
+
+	case errors.Is(err, domain.ErrTooManyLoginAttempts),
+		errors.Is(err, domain.ErrConfirmationResendCooldown),
+		errors.Is(err, domain.ErrMagicLinkCooldown):
+		return status.Error(codes.ResourceExhausted, err.Error())
+
+	case errors.Is(err, domain.ErrEmailNotConfirmed),
+		errors.Is(err, domain.ErrMFANotEnabled),
+		errors.Is(err, domain.ErrExternalEmailNotVerified):
+		return status.Error(codes.FailedPrecondition, err.Error())
+
+	case errors.Is(err, domain.ErrInvalidConfirmationCode),
+		errors.Is(err, domain.ErrInvalidMFACode),
+		errors.Is(err, domain.ErrPasswordResetTokenInvalid),
+		errors.Is(err, domain.ErrPasswordResetTokenExpired),
+		errors.Is(err, domain.ErrMagicLinkInvalid),
+		errors.Is(err, domain.ErrMagicLinkExpired),
+		errors.Is(err, domain.ErrEmailChangeCodeInvalid),
+		errors.Is(err, domain.ErrEmailChangeCodeExpired):
+		return status.Error(codes.InvalidArgument, err.Error())
+
+	case errors.Is(err, domain.ErrMagicLinkDisabled):
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+func validationStatus(err *domain.ValidationError) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range err.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+
+	st, detailsErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return st.Err()
+}
+
+// oauthStatus keeps the RFC 6749 error code in an ErrorInfo detail so the
+// gateway can answer in the format OAuth clients expect.
+func oauthStatus(err *domain.OAuthError) error {
+	code := codes.InvalidArgument
+	if err.Code == domain.OAuthInvalidClient {
+		code = codes.Unauthenticated
+	}
+
+	st, detailsErr := status.New(code, err.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason:   err.Code,
+		Domain:   "oauth2",
+		Metadata: map[string]string{"error_description": err.Description},
+	})
+	if detailsErr != nil {
+		return status.Error(code, err.Error())
+	}
+
+	return st.Err()
+}
+
+func invalidArgument(field, description string) error {
+	return validationStatus(domain.NewValidationError(field, description))
+}
+
+// unwrapMessage returns the message of the innermost domain error for
+// authentication failures so token parsing details are not sent to clients.
+func unwrapMessage(err error) string {
+	for _, target := range []error{
+		domain.ErrUnauthenticated,
+		domain.ErrInvalidCredentials,
+		domain.ErrInvalidMFAChallenge,
+		domain.ErrRefreshTokenNotFound,
+		domain.ErrRefreshTokenExpired,
+		domain.ErrRefreshTokenReused,
+	} {
+		if errors.Is(err, target) {
+			return target.Error()
+		}
+	}
+
+	return err.Error()
+}
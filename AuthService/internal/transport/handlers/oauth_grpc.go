@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"Project/AuthService/internal/domain/models"
+	"Project/proto/gen"
+
+	"go.uber.org/zap"
+)
+
+func (h *AuthHandlers) RegisterOAuthClient(ctx context.Context, req *gen.RegisterOAuthClientRequest) (*gen.RegisterOAuthClientResponse, error) {
+	h.logger.Info("Registering OAuth client", zap.String("name", req.Name))
+
+	client := &models.OAuthClient{
+		Name:         req.Name,
+		RedirectURIs: req.RedirectUris,
+		Scopes:       req.Scopes,
+		GrantTypes:   req.GrantTypes,
+	}
+
+	client, secret, err := h.service.RegisterOAuthClient(ctx, req.AccessToken, client, req.Confidential)
+	if err != nil {
+		h.logger.Error("Failed to register OAuth client", zap.String("name", req.Name), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.RegisterOAuthClientResponse{Client: mapOAuthClient(client), ClientSecret: secret}, nil
+}
+
+func (h *AuthHandlers) AuthorizeOAuthClient(ctx context.Context, req *gen.AuthorizeOAuthClientRequest) (*gen.AuthorizeOAuthClientResponse, error) {
+	h.logger.Info("Authorizing OAuth client", zap.String("clientID", req.ClientId))
+
+	authorization, err := h.service.AuthorizeOAuthClient(ctx, req.AccessToken, models.OAuthAuthorizationRequest{
+		ClientID:            req.ClientId,
+		RedirectURI:         req.RedirectUri,
+		Scope:               req.Scope,
+		State:               req.State,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+	}, req.Approve)
+	if err != nil {
+		h.logger.Error("Failed to authorize OAuth client", zap.String("clientID", req.ClientId), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.AuthorizeOAuthClientResponse{
+		ConsentRequired: authorization.ConsentRequired,
+		Client:          mapOAuthClient(authorization.Client),
+		Scopes:          authorization.Scopes,
+		RedirectTo:      authorization.RedirectTo,
+	}, nil
+}
+
+func (h *AuthHandlers) OAuthToken(ctx context.Context, req *gen.OAuthTokenRequest) (*gen.OAuthTokenResponse, error) {
+	clientID, clientSecret := oauthClientCredentials(req.Client)
+
+	h.logger.Info("Issuing OAuth token", zap.String("clientID", clientID), zap.String("grantType", req.GrantType))
+
+	result, err := h.service.OAuthToken(ctx, models.OAuthTokenRequest{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		GrantType:    req.GrantType,
+		Code:         req.Code,
+		RedirectURI:  req.RedirectUri,
+		CodeVerifier: req.CodeVerifier,
+		RefreshToken: req.RefreshToken,
+		Scope:        req.Scope,
+	})
+	if err != nil {
+		h.logger.Error("Failed to issue OAuth token", zap.String("clientID", clientID), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.OAuthTokenResponse{
+		AccessToken:  result.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(result.ExpiresIn.Seconds()),
+		RefreshToken: result.RefreshToken,
+		Scope:        strings.Join(result.Scopes, " "),
+	}, nil
+}
+
+func (h *AuthHandlers) IntrospectOAuthToken(ctx context.Context, req *gen.IntrospectOAuthTokenRequest) (*gen.IntrospectOAuthTokenResponse, error) {
+	clientID, clientSecret := oauthClientCredentials(req.Client)
+
+	introspection, err := h.service.IntrospectOAuthToken(ctx, clientID, clientSecret, req.Token)
+	if err != nil {
+		h.logger.Error("Failed to introspect OAuth token", zap.String("clientID", clientID), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	if !introspection.Active {
+		return &gen.IntrospectOAuthTokenResponse{}, nil
+	}
+
+	response := &gen.IntrospectOAuthTokenResponse{
+		Active:   true,
+		Scope:    strings.Join(introspection.Scopes, " "),
+		ClientId: introspection.ClientID,
+		Sub:      introspection.Subject,
+		Exp:      introspection.ExpiresAt.Unix(),
+		Iat:      introspection.IssuedAt.Unix(),
+		Iss:      introspection.Issuer,
+		Jti:      introspection.ID,
+	}
+	if introspection.ID != "" {
+		response.TokenType = "Bearer"
+	}
+
+	return response, nil
+}
+
+func (h *AuthHandlers) RevokeOAuthToken(ctx context.Context, req *gen.RevokeOAuthTokenRequest) (*gen.RevokeOAuthTokenResponse, error) {
+	clientID, clientSecret := oauthClientCredentials(req.Client)
+
+	h.logger.Info("Revoking OAuth token", zap.String("clientID", clientID))
+
+	if err := h.service.RevokeOAuthToken(ctx, clientID, clientSecret, req.Token); err != nil {
+		h.logger.Error("Failed to revoke OAuth token", zap.String("clientID", clientID), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.RevokeOAuthTokenResponse{}, nil
+}
+
+func (h *AuthHandlers) RevokeOAuthConsent(ctx context.Context, req *gen.RevokeOAuthConsentRequest) (*gen.RevokeOAuthConsentResponse, error) {
+	h.logger.Info("Revoking OAuth consent", zap.String("clientID", req.ClientId))
+
+	if req.ClientId == "" {
+		return nil, invalidArgument("client_id", "must not be empty")
+	}
+
+	if err := h.service.RevokeOAuthConsent(ctx, req.AccessToken, req.ClientId); err != nil {
+		h.logger.Error("Failed to revoke OAuth consent", zap.String("clientID", req.ClientId), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.RevokeOAuthConsentResponse{Success: true}, nil
+}
+
+func mapOAuthClient(client *models.OAuthClient) *gen.OAuthClient {
+	return &gen.OAuthClient{
+		ClientId:     client.ID,
+		Name:         client.Name,
+		RedirectUris: client.RedirectURIs,
+		Scopes:       client.Scopes,
+		GrantTypes:   client.GrantTypes,
+		Confidential: client.Confidential(),
+	}
+}
+
+func oauthClientCredentials(credentials *gen.OAuthClientCredentials) (string, string) {
+	return credentials.GetClientId(), credentials.GetClientSecret()
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+
+	"Project/AuthService/internal/domain/models"
+	"Project/proto/gen"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *AuthHandlers) UpdateProfile(ctx context.Context, req *gen.UpdateProfileRequest) (*gen.UpdateProfileResponse, error) {
+	h.logger.Info("Updating profile", zap.String("username", req.Username))
+
+	profile, err := h.service.UpdateProfile(ctx, req.AccessToken, models.Profile{
+		Username:    req.Username,
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+		AvatarURL:   req.AvatarUrl,
+	})
+	if err != nil {
+		h.logger.Error("Failed to update profile", zap.String("username", req.Username), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.UpdateProfileResponse{Profile: mapProfile(profile)}, nil
+}
+
+func (h *AuthHandlers) GetProfile(ctx context.Context, req *gen.GetProfileRequest) (*gen.GetProfileResponse, error) {
+	var (
+		profile *models.Profile
+		err     error
+	)
+
+	switch {
+	case req.UserId != "":
+		userID, parseErr := uuid.Parse(req.UserId)
+		if parseErr != nil {
+			return nil, invalidArgument("user_id", "must be a valid UUID")
+		}
+		profile, err = h.service.GetProfile(ctx, userID)
+	case req.Username != "":
+		profile, err = h.service.GetProfileByUsername(ctx, req.Username)
+	default:
+		return nil, invalidArgument("user_id", "user_id or username is required")
+	}
+
+	if err != nil {
+		h.logger.Error("Failed to get profile", zap.String("userID", req.UserId), zap.String("username", req.Username), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.GetProfileResponse{Profile: mapProfile(profile)}, nil
+}
+
+func (h *AuthHandlers) GetProfilesBatch(ctx context.Context, req *gen.GetProfilesBatchRequest) (*gen.GetProfilesBatchResponse, error) {
+	userIDs := make([]uuid.UUID, 0, len(req.UserIds))
+	for _, id := range req.UserIds {
+		userID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, invalidArgument("user_ids", "must be valid UUIDs")
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	profiles, err := h.service.GetProfilesBatch(ctx, userIDs)
+	if err != nil {
+		h.logger.Error("Failed to get profiles", zap.Int("count", len(userIDs)), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	response := &gen.GetProfilesBatchResponse{
+		Profiles: make([]*gen.Profile, 0, len(profiles)),
+	}
+	for _, profile := range profiles {
+		response.Profiles = append(response.Profiles, mapProfile(&profile))
+	}
+
+	return response, nil
+}
+
+func mapProfile(profile *models.Profile) *gen.Profile {
+	return &gen.Profile{
+		UserId:      profile.UserID.String(),
+		Username:    profile.Username,
+		DisplayName: profile.DisplayName,
+		Bio:         profile.Bio,
+		AvatarUrl:   profile.AvatarURL,
+	}
+}
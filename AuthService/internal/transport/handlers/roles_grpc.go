@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+
+	"Project/AuthService/internal/domain/models"
+	"Project/proto/gen"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *AuthHandlers) AssignRole(ctx context.Context, req *gen.AssignRoleRequest) (*gen.AssignRoleResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, invalidArgument("user_id", "must be a valid UUID")
+	}
+
+	h.logger.Info("Assigning role", zap.String("userID", userID.String()), zap.String("role", req.Role))
+
+	if err := h.service.AssignRole(ctx, req.AccessToken, userID, req.Role); err != nil {
+		h.logger.Error("Failed to assign role", zap.String("userID", userID.String()), zap.String("role", req.Role), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.AssignRoleResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) RevokeRole(ctx context.Context, req *gen.RevokeRoleRequest) (*gen.RevokeRoleResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, invalidArgument("user_id", "must be a valid UUID")
+	}
+
+	h.logger.Info("Revoking role", zap.String("userID", userID.String()), zap.String("role", req.Role))
+
+	if err := h.service.RevokeRole(ctx, req.AccessToken, userID, req.Role); err != nil {
+		h.logger.Error("Failed to revoke role", zap.String("userID", userID.String()), zap.String("role", req.Role), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.RevokeRoleResponse{Success: true}, nil
+}
+
+func (h *AuthHandlers) ListUserRoles(ctx context.Context, req *gen.ListUserRolesRequest) (*gen.ListUserRolesResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, invalidArgument("user_id", "must be a valid UUID")
+	}
+
+	roles, err := h.service.ListUserRoles(ctx, req.AccessToken, userID)
+	if err != nil {
+		h.logger.Error("Failed to list user roles", zap.String("userID", userID.String()), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return mapUserRolesToListUserRolesResponse(roles), nil
+}
+
+func mapUserRolesToListUserRolesResponse(roles []models.UserRole) *gen.ListUserRolesResponse {
+	response := &gen.ListUserRolesResponse{
+		Roles: make([]*gen.UserRole, 0, len(roles)),
+	}
+
+	for _, role := range roles {
+		userRole := &gen.UserRole{
+			Role:        role.Role,
+			Permissions: role.Permissions,
+			GrantedAt:   role.GrantedAt.Unix(),
+		}
+		if role.GrantedBy != nil {
+			userRole.GrantedBy = role.GrantedBy.String()
+		}
+		response.Roles = append(response.Roles, userRole)
+	}
+
+	return response
+}
+
+func (h *AuthHandlers) RevokeAccessToken(ctx context.Context, req *gen.RevokeAccessTokenRequest) (*gen.RevokeAccessTokenResponse, error) {
+	var jti uuid.UUID
+	if req.Jti != "" {
+		var err error
+		jti, err = uuid.Parse(req.Jti)
+		if err != nil {
+			return nil, invalidArgument("jti", "must be a valid UUID")
+		}
+	}
+
+	h.logger.Info("Revoking access token", zap.String("jti", req.Jti))
+
+	if err := h.service.RevokeAccessToken(ctx, req.AccessToken, jti); err != nil {
+		h.logger.Error("Failed to revoke access token", zap.String("jti", req.Jti), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.RevokeAccessTokenResponse{Success: true}, nil
+}
@@ -0,0 +1,74 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// CallerSecretKey is the metadata key the gateway sends the shared secret in.
+const CallerSecretKey = "x-caller-secret"
+
+// CallerInterceptor lets only the gateway call AuthService. Several RPCs trust
+// what the gateway vouches for, like the claims of an external identity and
+// the forwarded client address, so a caller without the shared secret is
+// rejected before any handler runs. Public methods, like GetJWKS that
+// FeedService polls, are let through without it.
+type CallerInterceptor struct {
+	secret        []byte
+	publicMethods map[string]bool
+	logger        *zap.Logger
+}
+
+func NewCallerInterceptor(secret string, publicMethods []string, logger *zap.Logger) *CallerInterceptor {
+	public := make(map[string]bool, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = true
+	}
+
+	return &CallerInterceptor{
+		secret:        []byte(secret),
+		publicMethods: public,
+		logger:        logger,
+	}
+}
+
+func (i *CallerInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := i.authenticate(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (i *CallerInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := i.authenticate(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+func (i *CallerInterceptor) authenticate(ctx context.Context, method string) error {
+	if i.publicMethods[method] {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(CallerSecretKey)
+	if len(values) != 1 || subtle.ConstantTimeCompare([]byte(values[0]), i.secret) != 1 {
+		i.logger.Warn("Rejected call without the caller secret", zap.String("method", method))
+		return status.Error(codes.Unauthenticated, "unknown caller")
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestCallerInterceptor(t *testing.T) {
+	const (
+		secret        = "gateway-secret"
+		privateMethod = "/server.Authentication/LoginWithExternalIdentity"
+		publicMethod  = "/server.Authentication/GetJWKS"
+	)
+
+	tests := []struct {
+		name   string
+		method string
+		// secrets are sent in the caller secret metadata, none when empty.
+		secrets    []string
+		wantCalled bool
+	}{
+		{
+			name:       "gateway",
+			method:     privateMethod,
+			secrets:    []string{secret},
+			wantCalled: true,
+		},
+		{
+			name:   "no secret",
+			method: privateMethod,
+		},
+		{
+			name:    "wrong secret",
+			method:  privateMethod,
+			secrets: []string{"guess"},
+		},
+		{
+			name:    "prefix of the secret",
+			method:  privateMethod,
+			secrets: []string{secret[:4]},
+		},
+		{
+			name:    "secret sent twice",
+			method:  privateMethod,
+			secrets: []string{"guess", secret},
+		},
+		{
+			name:       "public method without a secret",
+			method:     publicMethod,
+			wantCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := NewCallerInterceptor(secret, []string{publicMethod}, zap.NewNop())
+
+			md := metadata.MD{}
+			for _, sent := range tt.secrets {
+				md.Append(CallerSecretKey, sent)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return req, nil
+			}
+
+			_, err := interceptor.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if called != tt.wantCalled {
+				t.Fatalf("handler called = %t, want %t", called, tt.wantCalled)
+			}
+			if !tt.wantCalled && status.Code(err) != codes.Unauthenticated {
+				t.Errorf("error = %v, want Unauthenticated", err)
+			}
+			if tt.wantCalled && err != nil {
+				t.Errorf("error = %v, want none", err)
+			}
+		})
+	}
+}
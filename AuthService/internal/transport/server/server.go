@@ -5,6 +5,7 @@ import (
 	"net"
 
 	"Project/AuthService/internal/transport/handlers"
+	"Project/AuthService/internal/transport/interceptors"
 	"Project/proto/gen"
 
 	"go.uber.org/zap"
@@ -21,8 +22,12 @@ func NewGRPCServer(
 	log *zap.Logger,
 	port int,
 	authHandlers *handlers.AuthHandlers,
+	callerInterceptor *interceptors.CallerInterceptor,
 ) *GRPCServer {
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(callerInterceptor.Unary()),
+		grpc.ChainStreamInterceptor(callerInterceptor.Stream()),
+	)
 	gen.RegisterAuthenticationServer(grpcServer, authHandlers)
 	
 	return &GRPCServer{
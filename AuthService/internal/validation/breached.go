@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const hashPrefixLength = 5
+
+// BreachedPasswords checks passwords against an offline copy of a breached
+// password corpus in the k-anonymity range layout of Have I Been Pwned: the
+// directory holds one file per 5 character SHA-1 prefix, e.g. "21BD1.txt", with
+// lines of "<remaining 35 hex characters>:<count>". Only the file of the
+// password's prefix is read, so the corpus never has to fit in memory.
+type BreachedPasswords struct {
+	dir string
+}
+
+func NewBreachedPasswords(dir string) (*BreachedPasswords, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached passwords directory: %w", err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breached passwords path %s is not a directory", dir)
+	}
+
+	return &BreachedPasswords{dir: dir}, nil
+}
+
+func (b *BreachedPasswords) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	file, err := os.Open(filepath.Join(b.dir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to open breached passwords range: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate, _, _ := strings.Cut(line, ":")
+		if strings.EqualFold(candidate, suffix) {
+			return true, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breached passwords range: %w", err)
+	}
+
+	return false, nil
+}
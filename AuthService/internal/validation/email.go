@@ -0,0 +1,47 @@
+package validation
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+const maxEmailLength = 254
+
+var (
+	ErrEmailEmpty   = errors.New("must not be empty")
+	ErrEmailTooLong = errors.New("must be at most 254 characters")
+	ErrEmailInvalid = errors.New("must be a valid email address")
+)
+
+// ValidateEmail checks that email is a bare address such as "a@example.com",
+// without a display name or angle brackets.
+func ValidateEmail(email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return ErrEmailEmpty
+	}
+
+	if len(email) > maxEmailLength {
+		return ErrEmailTooLong
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return ErrEmailInvalid
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 1 || !strings.Contains(email[at+1:], ".") {
+		return ErrEmailInvalid
+	}
+
+	return nil
+}
+
+// NormalizeEmail returns the form emails are compared and stored as unique by.
+// Dots and "+tags" are kept because many providers treat them as different
+// mailboxes.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
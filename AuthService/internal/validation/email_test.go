@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateEmail(t *testing.T) {
+	tests := []struct {
+		email   string
+		wantErr error
+	}{
+		{email: "user@example.com"},
+		{email: "first.last+tag@mail.example.co.uk"},
+		{email: "  user@example.com  "},
+		{email: "", wantErr: ErrEmailEmpty},
+		{email: "   ", wantErr: ErrEmailEmpty},
+		{email: strings.Repeat("a", 243) + "@example.com", wantErr: ErrEmailTooLong},
+		{email: "user", wantErr: ErrEmailInvalid},
+		{email: "@example.com", wantErr: ErrEmailInvalid},
+		{email: "user@localhost", wantErr: ErrEmailInvalid},
+		{email: "User <user@example.com>", wantErr: ErrEmailInvalid},
+		{email: "<user@example.com>", wantErr: ErrEmailInvalid},
+		{email: "user@@example.com", wantErr: ErrEmailInvalid},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateEmail(tt.email); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ValidateEmail(%q) error = %v, want %v", tt.email, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "user@example.com", want: "user@example.com"},
+		{email: "User@Example.COM", want: "user@example.com"},
+		{email: "  user@example.com\n", want: "user@example.com"},
+		{email: "first.last+tag@example.com", want: "first.last+tag@example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeEmail(tt.email); got != tt.want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
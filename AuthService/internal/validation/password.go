@@ -0,0 +1,106 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPasswordBytes is the longest password bcrypt accepts. It applies to every
+// algorithm so switching back to bcrypt cannot lock anyone out.
+const maxPasswordBytes = 72
+
+// PasswordPolicy lists the rules a new password must follow. Zero values turn
+// a rule off.
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+
+	// MinStrength is the lowest accepted Strength score, from 0 to 4.
+	MinStrength int
+}
+
+type PasswordValidator struct {
+	policy   PasswordPolicy
+	breached *BreachedPasswords
+}
+
+// NewPasswordValidator creates a validator for policy. breached may be nil to
+// skip the breached password check.
+func NewPasswordValidator(policy PasswordPolicy, breached *BreachedPasswords) *PasswordValidator {
+	return &PasswordValidator{
+		policy:   policy,
+		breached: breached,
+	}
+}
+
+// Validate returns a description of every rule the password breaks. userInputs
+// are values such as the email of the user that the password should not be
+// built from.
+func (v *PasswordValidator) Validate(password string, userInputs ...string) ([]string, error) {
+	var problems []string
+
+	length := utf8.RuneCountInString(password)
+	if length < v.policy.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters long", v.policy.MinLength))
+	}
+
+	if v.policy.MaxLength > 0 && length > v.policy.MaxLength {
+		problems = append(problems, fmt.Sprintf("must be at most %d characters long", v.policy.MaxLength))
+	} else if len(password) > maxPasswordBytes {
+		problems = append(problems, fmt.Sprintf("must be at most %d bytes long", maxPasswordBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	var missing []string
+	if v.policy.RequireUpper && !hasUpper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if v.policy.RequireLower && !hasLower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if v.policy.RequireDigit && !hasDigit {
+		missing = append(missing, "a digit")
+	}
+	if v.policy.RequireSymbol && !hasSymbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		problems = append(problems, "must contain "+strings.Join(missing, ", "))
+	}
+
+	if v.policy.MinStrength > 0 && Strength(password, userInputs...) < v.policy.MinStrength {
+		problems = append(problems, "is too easy to guess")
+	}
+
+	if v.breached != nil && password != "" {
+		breached, err := v.breached.Contains(password)
+		if err != nil {
+			return nil, err
+		}
+
+		if breached {
+			problems = append(problems, "appeared in a data breach, choose a different one")
+		}
+	}
+
+	return problems, nil
+}
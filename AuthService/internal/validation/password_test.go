@@ -0,0 +1,170 @@
+package validation
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPasswordValidatorValidate(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength:     8,
+		MaxLength:     64,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	tests := []struct {
+		name       string
+		policy     PasswordPolicy
+		password   string
+		userInputs []string
+		want       []string
+	}{
+		{
+			name:     "follows every rule",
+			policy:   policy,
+			password: "Tr0ub4dor&3",
+		},
+		{
+			name:     "too short",
+			policy:   policy,
+			password: "Ab1!",
+			want:     []string{"must be at least 8 characters long"},
+		},
+		{
+			name:     "too long",
+			policy:   policy,
+			password: "Ab1!" + strings.Repeat("x", 61),
+			want:     []string{"must be at most 64 characters long"},
+		},
+		{
+			name:     "longer than bcrypt accepts without a max length",
+			policy:   PasswordPolicy{MinLength: 8},
+			password: strings.Repeat("x", 73),
+			want:     []string{"must be at most 72 bytes long"},
+		},
+		{
+			name:     "length counts characters, not bytes",
+			policy:   PasswordPolicy{MinLength: 8},
+			password: "пароль12",
+		},
+		{
+			name:     "missing classes are listed together",
+			policy:   policy,
+			password: "alllowercase",
+			want:     []string{"must contain an uppercase letter, a digit, a symbol"},
+		},
+		{
+			name:     "space counts as a symbol",
+			policy:   policy,
+			password: "Correct horse 1",
+		},
+		{
+			name:     "several rules at once",
+			policy:   policy,
+			password: "abc",
+			want: []string{
+				"must be at least 8 characters long",
+				"must contain an uppercase letter, a digit, a symbol",
+			},
+		},
+		{
+			name:     "common password is too easy to guess",
+			policy:   PasswordPolicy{MinStrength: 2},
+			password: "Password123",
+			want:     []string{"is too easy to guess"},
+		},
+		{
+			name:       "password built from the email is too easy to guess",
+			policy:     PasswordPolicy{MinStrength: 3},
+			password:   "johnsmith2024",
+			userInputs: []string{"johnsmith@example.com"},
+			want:       []string{"is too easy to guess"},
+		},
+		{
+			name:     "zero policy accepts anything",
+			password: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPasswordValidator(tt.policy, nil).Validate(tt.password, tt.userInputs...)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordValidatorBreached(t *testing.T) {
+	const breachedPassword = "hunter2hunter2"
+
+	dir := t.TempDir()
+	sum := sha1.Sum([]byte(breachedPassword))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	// Other suffixes of the range and lower case hex must not matter.
+	corpus := "0000000000000000000000000000000000A:3\r\n" + strings.ToLower(hash[5:]) + ":42\r\n"
+	if err := os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(corpus), 0o600); err != nil {
+		t.Fatalf("failed to write range: %v", err)
+	}
+
+	breached, err := NewBreachedPasswords(dir)
+	if err != nil {
+		t.Fatalf("NewBreachedPasswords() error = %v", err)
+	}
+	validator := NewPasswordValidator(PasswordPolicy{}, breached)
+
+	tests := []struct {
+		password string
+		want     []string
+	}{
+		{password: breachedPassword, want: []string{"appeared in a data breach, choose a different one"}},
+		{password: "not in the corpus"},
+		{password: ""},
+	}
+
+	for _, tt := range tests {
+		got, err := validator.Validate(tt.password)
+		if err != nil {
+			t.Fatalf("Validate(%q) error = %v", tt.password, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Validate(%q) = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestStrength(t *testing.T) {
+	tests := []struct {
+		password   string
+		userInputs []string
+		want       int
+	}{
+		{password: "password", want: 0},
+		{password: "PASSWORD", want: 0},
+		{password: "aaaaaaaaaaaaaaaa", want: 1},
+		{password: "abcdefghijklmnop", want: 1},
+		{password: "qwertyuiop123", want: 2},
+		{password: "correcthorsebatterystaple", want: 4},
+		{password: "x7#Kq!9z", want: 4},
+		{password: "johnsmith", want: 4},
+		{password: "johnsmith", userInputs: []string{"JohnSmith@example.com"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := Strength(tt.password, tt.userInputs...); got != tt.want {
+			t.Errorf("Strength(%q, %q) = %d, want %d", tt.password, tt.userInputs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxDisplayNameLength = 64
+	maxBioLength         = 500
+	maxAvatarURLLength   = 2048
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{2,29}$`)
+
+var (
+	ErrUsernameInvalid    = errors.New("must be 3 to 30 characters of letters, digits and underscores, starting with a letter")
+	ErrDisplayNameTooLong = errors.New("must be at most 64 characters")
+	ErrBioTooLong         = errors.New("must be at most 500 characters")
+	ErrAvatarURLInvalid   = errors.New("must be an absolute http or https URL of at most 2048 characters")
+)
+
+// NormalizeUsername lowercases the username; usernames are unique regardless
+// of case.
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// ValidateUsername checks a normalized username.
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return ErrUsernameInvalid
+	}
+	return nil
+}
+
+func ValidateDisplayName(displayName string) error {
+	if utf8.RuneCountInString(displayName) > maxDisplayNameLength {
+		return ErrDisplayNameTooLong
+	}
+	return nil
+}
+
+func ValidateBio(bio string) error {
+	if utf8.RuneCountInString(bio) > maxBioLength {
+		return ErrBioTooLong
+	}
+	return nil
+}
+
+// ValidateAvatarURL accepts an empty URL, which removes the avatar.
+func ValidateAvatarURL(avatarURL string) error {
+	if avatarURL == "" {
+		return nil
+	}
+
+	if len(avatarURL) > maxAvatarURLLength {
+		return ErrAvatarURLInvalid
+	}
+
+	u, err := url.Parse(avatarURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrAvatarURLInvalid
+	}
+
+	return nil
+}
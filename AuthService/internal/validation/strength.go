@@ -0,0 +1,135 @@
+package validation
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// commonPasswords are scored 0 regardless of their length.
+var commonPasswords = map[string]struct{}{
+	"password": {}, "password1": {}, "password123": {}, "passw0rd": {}, "p@ssw0rd": {},
+	"123456": {}, "12345678": {}, "123456789": {}, "1234567890": {}, "1q2w3e4r": {},
+	"qwerty": {}, "qwerty123": {}, "qwertyuiop": {}, "abc123": {}, "111111": {},
+	"iloveyou": {}, "admin": {}, "welcome": {}, "welcome1": {}, "letmein": {},
+	"monkey": {}, "dragon": {}, "football": {}, "baseball": {}, "sunshine": {},
+	"princess": {}, "superman": {}, "trustno1": {}, "master": {}, "shadow": {},
+	"changeme": {}, "secret": {}, "zaq12wsx": {}, "starwars": {}, "whatever": {},
+}
+
+// keyboardRows are walked as sequences, like "abc" and "123".
+var keyboardRows = []string{
+	"abcdefghijklmnopqrstuvwxyz",
+	"01234567890",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+}
+
+// Strength estimates how hard the password is to guess on the 0 to 4 scale of
+// zxcvbn: 0 is under 10^3 guesses, then 10^6, 10^8 and 10^10, and 4 is above.
+// Repeated characters, sequences, keyboard walks and parts of userInputs add
+// almost nothing to the estimate. It is a coarse estimate, not a replacement
+// for the breached password check.
+func Strength(password string, userInputs ...string) int {
+	lower := strings.ToLower(password)
+	if _, ok := commonPasswords[lower]; ok {
+		return 0
+	}
+
+	for _, input := range userInputs {
+		input = strings.ToLower(input)
+		if at := strings.IndexByte(input, '@'); at >= 0 {
+			input = input[:at]
+		}
+		if len(input) >= 3 {
+			lower = strings.ReplaceAll(lower, input, string(rune(0)))
+		}
+	}
+
+	charset := charsetSize(password)
+	runes := []rune(lower)
+
+	var bits float64
+	for i, r := range runes {
+		if i > 0 && predictable(runes[i-1], r) {
+			bits++
+			continue
+		}
+		bits += math.Log2(float64(charset))
+	}
+
+	guessesLog10 := bits * math.Log10(2)
+	switch {
+	case guessesLog10 < 3:
+		return 0
+	case guessesLog10 < 6:
+		return 1
+	case guessesLog10 < 8:
+		return 2
+	case guessesLog10 < 10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func charsetSize(password string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	size := 0
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if digit {
+		size += 10
+	}
+	if symbol {
+		size += 33
+	}
+	if other {
+		size += 100
+	}
+	if size == 0 {
+		size = 1
+	}
+
+	return size
+}
+
+// predictable reports whether next repeats prev or continues a sequence from it
+// in either direction.
+func predictable(prev, next rune) bool {
+	if prev == next {
+		return true
+	}
+
+	for _, row := range keyboardRows {
+		i := strings.IndexRune(row, prev)
+		if i < 0 {
+			continue
+		}
+		if (i+1 < len(row) && rune(row[i+1]) == next) || (i > 0 && rune(row[i-1]) == next) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,37 @@
+-- +goose Up
+DROP TABLE IF EXISTS users_tokens;
+
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_name VARCHAR(255),
+    user_agent TEXT,
+    ip VARCHAR(64),
+    access_token TEXT,
+    access_token_expires_at TIMESTAMP,
+    refresh_token TEXT,
+    refresh_token_expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_sessions_user_id;
+DROP TABLE IF EXISTS sessions;
+
+CREATE TABLE users_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    access_token VARCHAR(255),
+    refresh_token VARCHAR(255),
+    refresh_token_expires_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    access_token_expires_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_users_tokens_user_id ON users_tokens(user_id);
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE sessions DROP COLUMN IF EXISTS refresh_token;
+
+CREATE TABLE refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    family_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+DROP TABLE IF EXISTS refresh_tokens;
+
+ALTER TABLE sessions ADD COLUMN refresh_token TEXT;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN locked_until TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
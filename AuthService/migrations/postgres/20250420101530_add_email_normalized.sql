@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN email_normalized VARCHAR(255);
+UPDATE users SET email_normalized = LOWER(TRIM(email));
+ALTER TABLE users ALTER COLUMN email_normalized SET NOT NULL;
+CREATE UNIQUE INDEX idx_users_email_normalized ON users(email_normalized);
+
+ALTER TABLE email_changes ADD COLUMN new_email_normalized VARCHAR(255);
+UPDATE email_changes SET new_email_normalized = LOWER(TRIM(new_email));
+ALTER TABLE email_changes ALTER COLUMN new_email_normalized SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE email_changes DROP COLUMN IF EXISTS new_email_normalized;
+DROP INDEX IF EXISTS idx_users_email_normalized;
+ALTER TABLE users DROP COLUMN IF EXISTS email_normalized;
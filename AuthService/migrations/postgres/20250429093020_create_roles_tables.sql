@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE roles (
+    name VARCHAR(64) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE permissions (
+    name VARCHAR(64) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permissions (
+    role VARCHAR(64) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(64) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+CREATE TABLE user_roles (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(64) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    granted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Manages users and their roles'),
+    ('moderator', 'Moderates the content of other users');
+
+INSERT INTO permissions (name, description) VALUES
+    ('roles:manage', 'Assign and revoke roles'),
+    ('posts:delete_any', 'Delete posts of other users');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'roles:manage'),
+    ('admin', 'posts:delete_any'),
+    ('moderator', 'posts:delete_any');
+
+-- +goose Down
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
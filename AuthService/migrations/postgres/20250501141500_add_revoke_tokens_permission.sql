@@ -0,0 +1,9 @@
+-- +goose Up
+INSERT INTO permissions (name, description) VALUES
+    ('tokens:revoke', 'Revoke access tokens of other users');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'tokens:revoke');
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'tokens:revoke';
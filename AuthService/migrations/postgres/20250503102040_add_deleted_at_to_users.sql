@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
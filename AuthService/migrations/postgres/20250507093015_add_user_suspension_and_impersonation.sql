@@ -0,0 +1,38 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN suspended_at TIMESTAMP,
+    ADD COLUMN suspended_reason TEXT,
+    ADD COLUMN suspended_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE impersonations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    admin_id UUID NOT NULL REFERENCES users(id),
+    user_id UUID NOT NULL REFERENCES users(id),
+    session_id UUID NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_impersonations_admin_id ON impersonations(admin_id);
+CREATE INDEX idx_impersonations_user_id ON impersonations(user_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:manage', 'List, search, suspend and sign out users'),
+    ('users:impersonate', 'Act as another user for support');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:manage'),
+    ('admin', 'users:impersonate');
+
+-- +goose Down
+DELETE FROM permissions WHERE name IN ('users:manage', 'users:impersonate');
+
+DROP INDEX IF EXISTS idx_impersonations_user_id;
+DROP INDEX IF EXISTS idx_impersonations_admin_id;
+DROP TABLE IF EXISTS impersonations;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS suspended_by,
+    DROP COLUMN IF EXISTS suspended_reason,
+    DROP COLUMN IF EXISTS suspended_at;
@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE auth_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID,
+    email VARCHAR(255),
+    event VARCHAR(32) NOT NULL,
+    outcome VARCHAR(16) NOT NULL,
+    reason TEXT,
+    ip VARCHAR(64),
+    user_agent TEXT,
+    session_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_auth_events_user_id_created_at ON auth_events(user_id, created_at);
+CREATE INDEX idx_auth_events_created_at ON auth_events(created_at);
+
+-- +goose StatementBegin
+CREATE FUNCTION reject_auth_events_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'auth_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER auth_events_append_only
+    BEFORE UPDATE OR DELETE ON auth_events
+    FOR EACH ROW EXECUTE FUNCTION reject_auth_events_change();
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit:read', 'Read the authentication events of all users');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'audit:read');
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'audit:read';
+
+DROP TRIGGER IF EXISTS auth_events_append_only ON auth_events;
+DROP FUNCTION IF EXISTS reject_auth_events_change();
+DROP INDEX IF EXISTS idx_auth_events_created_at;
+DROP INDEX IF EXISTS idx_auth_events_user_id_created_at;
+DROP TABLE IF EXISTS auth_events;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE magic_link_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_magic_link_tokens_user_id ON magic_link_tokens(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_magic_link_tokens_user_id;
+DROP TABLE IF EXISTS magic_link_tokens;
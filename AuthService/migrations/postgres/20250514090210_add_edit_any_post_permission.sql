@@ -0,0 +1,10 @@
+-- +goose Up
+INSERT INTO permissions (name, description) VALUES
+    ('posts:edit_any', 'Edit posts of other users');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'posts:edit_any'),
+    ('moderator', 'posts:edit_any');
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'posts:edit_any';
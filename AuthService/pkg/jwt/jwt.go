@@ -1,6 +1,10 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 
 	"time"
@@ -10,18 +14,58 @@ import (
 	"github.com/google/uuid"
 )
 
-func GenerateToken(userID uuid.UUID, email string, secretKey string, TokenTTL time.Duration) (string, time.Time, error) {
+// TokenClaims are the claims of a session access token. ID is the "jti" a
+// revoked token is known by; GenerateToken picks a new one when it is empty.
+// ActorID is set on impersonation tokens to the admin acting as UserID and is
+// written as the RFC 8693 "act" claim.
+type TokenClaims struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	SessionID   uuid.UUID
+	Email       string
+	Roles       []string
+	Permissions []string
+	Issuer      string
+	Audience    string
+	ExpiresAt   time.Time
+	ActorID     uuid.UUID
+}
+
+// Impersonated reports whether the token was issued to an admin acting as the user.
+func (c TokenClaims) Impersonated() bool {
+	return c.ActorID != uuid.Nil
+}
+
+func GenerateToken(keyring *Keyring, tokenClaims TokenClaims, TokenTTL time.Duration) (string, time.Time, error) {
 
 	expiresAt := time.Now().Add(TokenTTL)
 
+	if tokenClaims.ID == uuid.Nil {
+		tokenClaims.ID = uuid.New()
+	}
+
 	claims := jwt.MapClaims{
-		"user_id": userID.String(),
-		"email":   email,
-		"exp":     expiresAt.Unix(),
+		"jti":         tokenClaims.ID.String(),
+		"user_id":     tokenClaims.UserID.String(),
+		"session_id":  tokenClaims.SessionID.String(),
+		"email":       tokenClaims.Email,
+		"roles":       nonNil(tokenClaims.Roles),
+		"permissions": nonNil(tokenClaims.Permissions),
+		"iss":         tokenClaims.Issuer,
+		"aud":         tokenClaims.Audience,
+		"iat":         time.Now().Unix(),
+		"exp":         expiresAt.Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(secretKey))
+	if tokenClaims.ActorID != uuid.Nil {
+		claims["act"] = map[string]string{"sub": tokenClaims.ActorID.String()}
+	}
+
+	key := keyring.SigningKey()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+
+	signedToken, err := token.SignedString(key.PrivateKey)
 	if err != nil {
 
 		return "", time.Time{}, fmt.Errorf("failed to generate token: %w", err)
@@ -34,42 +78,144 @@ func GenerateConfirmationCode() string {
 	return uniuri.NewLen(6)
 }
 
-func ExtractUserIDFromToken(tokenString string, secretKey string) (uuid.UUID, error) {
+// GenerateOpaqueToken returns a random URL-safe token for single-use secrets
+// such as refresh tokens. Only its HashToken digest should be persisted.
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func ExtractUserIDFromToken(tokenString string, keyring *Keyring, audience string) (uuid.UUID, error) {
+	claims, err := ParseToken(tokenString, keyring, audience)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return claims.UserID, nil
+}
+
+// ParseToken verifies the token with the keyring key named by its "kid" header.
+// The audience is only checked when it is not empty.
+func ParseToken(tokenString string, keyring *Keyring, audience string) (*TokenClaims, error) {
 
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keyring.Key(kid)
+		if !ok {
+
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		}
 
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if token.Method.Alg() != key.Algorithm {
 
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(secretKey), nil
+		return key.PublicKey, nil
 	})
 	if err != nil {
 
-		return uuid.Nil, fmt.Errorf("failed to parse token: %w", err)
+		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	if !token.Valid {
 
-		return uuid.Nil, fmt.Errorf("invalid token")
+		return nil, fmt.Errorf("invalid token")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
 
-		return uuid.Nil, fmt.Errorf("failed to extract claims from token")
+		return nil, fmt.Errorf("failed to extract claims from token")
+	}
+
+	if audience != "" && !claims.VerifyAudience(audience, true) {
+
+		return nil, fmt.Errorf("invalid token audience")
+	}
+
+	jti, err := uuid.Parse(stringClaim(claims, "jti"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jti as UUID: %w", err)
 	}
 
 	userIDStr, ok := claims["user_id"].(string)
 	if !ok {
 
-		return uuid.Nil, fmt.Errorf("user_id not found in token claims")
+		return nil, fmt.Errorf("user_id not found in token claims")
 	}
 
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to parse user_id as UUID: %w", err)
+		return nil, fmt.Errorf("failed to parse user_id as UUID: %w", err)
+	}
+
+	sessionIDStr, ok := claims["session_id"].(string)
+	if !ok {
+
+		return nil, fmt.Errorf("session_id not found in token claims")
+	}
+
+	sessionID, err := uuid.Parse(sessionIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse session_id as UUID: %w", err)
+	}
+
+	email, _ := claims["email"].(string)
+	issuer, _ := claims["iss"].(string)
+	tokenAudience, _ := claims["aud"].(string)
+
+	exp, _ := claims["exp"].(float64)
+
+	var actorID uuid.UUID
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		sub, _ := act["sub"].(string)
+		if actorID, err = uuid.Parse(sub); err != nil {
+			return nil, fmt.Errorf("failed to parse act.sub as UUID: %w", err)
+		}
+	}
+
+	return &TokenClaims{
+		ID:          jti,
+		UserID:      userID,
+		SessionID:   sessionID,
+		Email:       email,
+		Roles:       stringsClaim(claims, "roles"),
+		Permissions: stringsClaim(claims, "permissions"),
+		Issuer:      issuer,
+		Audience:    tokenAudience,
+		ExpiresAt:   time.Unix(int64(exp), 0),
+		ActorID:     actorID,
+	}, nil
+}
+
+// stringsClaim reads a JSON array of strings, ignoring anything else.
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+	values, _ := claims[name].([]interface{})
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// nonNil makes empty lists encode as [] instead of null.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
 	}
 
-	return userID, nil
+	return values
 }
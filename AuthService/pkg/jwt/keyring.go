@@ -0,0 +1,192 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Key is one signing key of the keyring. The key ID is the PEM file name
+// without its extension and is published as the "kid" token header.
+type Key struct {
+	ID         string
+	Algorithm  string
+	PrivateKey interface{}
+	PublicKey  interface{}
+}
+
+// Keyring holds every active key. Tokens are signed with the signing key only,
+// while all keys stay valid for verification, so a new key can be introduced and
+// an old one retired without invalidating tokens that are still in flight.
+type Keyring struct {
+	signingKeyID string
+	keys         map[string]*Key
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// LoadKeyring reads every *.pem PKCS#8 (or PKCS#1 RSA) private key from dir.
+// With generateMissing set an Ed25519 signing key is created when the directory
+// has none, which is only meant for local environments.
+func LoadKeyring(dir, signingKeyID string, generateMissing bool) (*Keyring, error) {
+	if generateMissing {
+		if err := generateKeyIfMissing(dir, signingKeyID); err != nil {
+			return nil, err
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	keyring := &Keyring{
+		signingKeyID: signingKeyID,
+		keys:         make(map[string]*Key, len(paths)),
+	}
+
+	for _, path := range paths {
+		key, err := loadKey(path)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[key.ID] = key
+	}
+
+	if _, ok := keyring.keys[signingKeyID]; !ok {
+		return nil, fmt.Errorf("signing key %q not found in %s", signingKeyID, dir)
+	}
+
+	return keyring, nil
+}
+
+func loadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM key %s", path)
+	}
+
+	var privateKey interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key %s: %w", path, err)
+	}
+
+	key := &Key{
+		ID:         strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		PrivateKey: privateKey,
+	}
+
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = jwt.SigningMethodRS256.Alg()
+		key.PublicKey = &k.PublicKey
+	case ed25519.PrivateKey:
+		key.Algorithm = jwt.SigningMethodEdDSA.Alg()
+		key.PublicKey = k.Public()
+	default:
+		return nil, fmt.Errorf("unsupported key type %T in %s", privateKey, path)
+	}
+
+	return key, nil
+}
+
+func generateKeyIfMissing(dir, keyID string) error {
+	path := filepath.Join(dir, keyID+".pem")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signing key: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create keys directory: %w", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write signing key: %w", err)
+	}
+
+	return nil
+}
+
+func (k *Keyring) SigningKey() *Key {
+	return k.keys[k.signingKeyID]
+}
+
+func (k *Keyring) Key(keyID string) (*Key, bool) {
+	key, ok := k.keys[keyID]
+	return key, ok
+}
+
+// JWKS returns the public part of every key in JWK format (RFC 7517).
+func (k *Keyring) JWKS() []JSONWebKey {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := make([]JSONWebKey, 0, len(ids))
+	for _, id := range ids {
+		key := k.keys[id]
+		jwk := JSONWebKey{
+			Kid: key.ID,
+			Alg: key.Algorithm,
+			Use: "sig",
+		}
+
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		jwks = append(jwks, jwk)
+	}
+
+	return jwks
+}
@@ -0,0 +1,117 @@
+package jwt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+// OAuthTokenClaims are the claims of access tokens issued to third-party
+// clients, following the JWT profile of RFC 9068. Subject is the user ID, or
+// the client ID for the client credentials grant.
+type OAuthTokenClaims struct {
+	ID        uuid.UUID
+	GrantID   uuid.UUID
+	ClientID  string
+	Subject   string
+	Scopes    []string
+	Issuer    string
+	Audience  string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+func GenerateOAuthToken(keyring *Keyring, tokenClaims OAuthTokenClaims, tokenTTL time.Duration) (string, time.Time, error) {
+	issuedAt := time.Now()
+	expiresAt := issuedAt.Add(tokenTTL)
+
+	claims := jwt.MapClaims{
+		"jti":       tokenClaims.ID.String(),
+		"grant_id":  tokenClaims.GrantID.String(),
+		"client_id": tokenClaims.ClientID,
+		"sub":       tokenClaims.Subject,
+		"scope":     strings.Join(tokenClaims.Scopes, " "),
+		"iss":       tokenClaims.Issuer,
+		"aud":       tokenClaims.Audience,
+		"iat":       issuedAt.Unix(),
+		"exp":       expiresAt.Unix(),
+	}
+
+	key := keyring.SigningKey()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	token.Header["typ"] = "at+jwt"
+
+	signedToken, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return signedToken, expiresAt, nil
+}
+
+// ParseOAuthToken verifies an access token issued by GenerateOAuthToken. Tokens
+// of first-party sessions are rejected.
+func ParseOAuthToken(tokenString string, keyring *Keyring, audience string) (*OAuthTokenClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keyring.Key(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		}
+
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.PublicKey, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	if typ, _ := token.Header["typ"].(string); typ != "at+jwt" {
+		return nil, fmt.Errorf("not an oauth access token")
+	}
+
+	if audience != "" && !claims.VerifyAudience(audience, true) {
+		return nil, fmt.Errorf("invalid token audience")
+	}
+
+	id, err := uuid.Parse(stringClaim(claims, "jti"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jti: %w", err)
+	}
+
+	grantID, err := uuid.Parse(stringClaim(claims, "grant_id"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse grant_id: %w", err)
+	}
+
+	issuedAt, _ := claims["iat"].(float64)
+	expiresAt, _ := claims["exp"].(float64)
+
+	return &OAuthTokenClaims{
+		ID:        id,
+		GrantID:   grantID,
+		ClientID:  stringClaim(claims, "client_id"),
+		Subject:   stringClaim(claims, "sub"),
+		Scopes:    strings.Fields(stringClaim(claims, "scope")),
+		Issuer:    stringClaim(claims, "iss"),
+		Audience:  stringClaim(claims, "aud"),
+		IssuedAt:  time.Unix(int64(issuedAt), 0),
+		ExpiresAt: time.Unix(int64(expiresAt), 0),
+	}, nil
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
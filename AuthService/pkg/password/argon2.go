@@ -0,0 +1,145 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2idPrefix = "$argon2id$"
+
+type Argon2Params struct {
+	// Time is the number of passes over the memory.
+	Time uint32
+	// Memory is in KiB.
+	Memory     uint32
+	Threads    uint8
+	SaltLength uint32
+	KeyLength  uint32
+}
+
+// DefaultArgon2Params follow the second recommendation of RFC 9106 with fewer
+// lanes, which keeps a hash around 50ms on a single core.
+var DefaultArgon2Params = Argon2Params{
+	Time:       3,
+	Memory:     64 * 1024,
+	Threads:    2,
+	SaltLength: 16,
+	KeyLength:  32,
+}
+
+// Argon2id produces "$argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>"
+// with unpadded base64 salt and hash, the format of the reference implementation.
+type Argon2id struct {
+	params Argon2Params
+}
+
+// NewArgon2id creates the algorithm. Zero fields of params fall back to
+// DefaultArgon2Params.
+func NewArgon2id(params Argon2Params) *Argon2id {
+	if params.Time == 0 {
+		params.Time = DefaultArgon2Params.Time
+	}
+	if params.Memory == 0 {
+		params.Memory = DefaultArgon2Params.Memory
+	}
+	if params.Threads == 0 {
+		params.Threads = DefaultArgon2Params.Threads
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = DefaultArgon2Params.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = DefaultArgon2Params.KeyLength
+	}
+
+	return &Argon2id{params: params}
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.params.Time, a.params.Memory, a.params.Threads, a.params.KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		a.params.Memory,
+		a.params.Time,
+		a.params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (a *Argon2id) Verify(password, encoded string) error {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(candidate, key) != 1 {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+func (a *Argon2id) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2idPrefix)
+}
+
+func (a *Argon2id) Outdated(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+
+	return params.Time != a.params.Time ||
+		params.Memory != a.params.Memory ||
+		params.Threads != a.params.Threads ||
+		uint32(len(salt)) != a.params.SaltLength ||
+		uint32(len(key)) != a.params.KeyLength
+}
+
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, ErrMalformedHash
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("%w: unsupported argon2 version %d", ErrMalformedHash, version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrMalformedHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrMalformedHash
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,76 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMismatch      = errors.New("password does not match")
+	ErrUnknownFormat = errors.New("unknown password hash format")
+	ErrMalformedHash = errors.New("malformed password hash")
+)
+
+// Algorithm is one password hashing scheme. Hashes are self-describing strings
+// in PHC format ("$<id>$<params>$<salt>$<hash>"), so a hash records the
+// algorithm and the parameters it was made with.
+type Algorithm interface {
+	Hash(password string) (string, error)
+	// Verify returns ErrMismatch when the password does not match the hash.
+	Verify(password, encoded string) error
+	// Recognizes reports whether encoded was produced by this algorithm.
+	Recognizes(encoded string) bool
+	// Outdated reports whether encoded was made with other parameters than the
+	// ones configured now.
+	Outdated(encoded string) bool
+}
+
+// Hasher hashes new passwords with the preferred algorithm and verifies hashes
+// of every algorithm it knows, so hashes can be upgraded one login at a time.
+type Hasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+}
+
+func NewHasher(preferred Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{
+		preferred:  preferred,
+		algorithms: append([]Algorithm{preferred}, legacy...),
+	}
+}
+
+// NewHasherByName builds a Hasher that prefers the named algorithm, "argon2id"
+// or "bcrypt", and still verifies hashes of the other one.
+func NewHasherByName(name string, bcryptCost int, argon2Params Argon2Params) (*Hasher, error) {
+	bcrypt := NewBcrypt(bcryptCost)
+	argon2 := NewArgon2id(argon2Params)
+
+	switch name {
+	case "argon2id", "":
+		return NewHasher(argon2, bcrypt), nil
+	case "bcrypt":
+		return NewHasher(bcrypt, argon2), nil
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", name)
+	}
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+func (h *Hasher) Verify(password, encoded string) error {
+	for _, algorithm := range h.algorithms {
+		if algorithm.Recognizes(encoded) {
+			return algorithm.Verify(password, encoded)
+		}
+	}
+
+	return ErrUnknownFormat
+}
+
+// NeedsRehash reports whether encoded should be replaced by a fresh hash of the
+// preferred algorithm with its current parameters.
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	return !h.preferred.Recognizes(encoded) || h.preferred.Outdated(encoded)
+}
@@ -0,0 +1,178 @@
+package password
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2Params keep the tests fast. Hashes made with them are as valid as
+// any others.
+var testArgon2Params = Argon2Params{Time: 1, Memory: 64, Threads: 1, SaltLength: 8, KeyLength: 16}
+
+func mustHash(t *testing.T, hasher interface{ Hash(string) (string, error) }, password string) string {
+	t.Helper()
+
+	hash, err := hasher.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	return hash
+}
+
+func TestArgon2idFormat(t *testing.T) {
+	hash := mustHash(t, NewArgon2id(testArgon2Params), "secret")
+
+	format := regexp.MustCompile(`^\$argon2id\$v=19\$m=64,t=1,p=1\$[A-Za-z0-9+/]{11}\$[A-Za-z0-9+/]{22}$`)
+	if !format.MatchString(hash) {
+		t.Errorf("Hash() = %q, want the PHC format of the reference implementation", hash)
+	}
+
+	if again := mustHash(t, NewArgon2id(testArgon2Params), "secret"); again == hash {
+		t.Errorf("two hashes of the same password are equal, the salt is not random")
+	}
+}
+
+func TestArgon2idVerify(t *testing.T) {
+	argon2 := NewArgon2id(testArgon2Params)
+	hash := mustHash(t, argon2, "secret")
+
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		wantErr  error
+	}{
+		{name: "matching password", password: "secret", encoded: hash},
+		{name: "wrong password", password: "Secret", encoded: hash, wantErr: ErrMismatch},
+		{name: "hash made with other parameters", password: "secret", encoded: mustHash(t, NewArgon2id(Argon2Params{Time: 2, Memory: 128, Threads: 2, SaltLength: 16, KeyLength: 32}), "secret")},
+		{name: "too few parts", password: "secret", encoded: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA", wantErr: ErrMalformedHash},
+		{name: "other version", password: "secret", encoded: "$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHQ$aGFzaGhhc2hoYXNoaGFzaA", wantErr: ErrMalformedHash},
+		{name: "parameters are not numbers", password: "secret", encoded: "$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHQ$aGFzaGhhc2hoYXNoaGFzaA", wantErr: ErrMalformedHash},
+		{name: "salt is not base64", password: "secret", encoded: "$argon2id$v=19$m=64,t=1,p=1$!!!$aGFzaGhhc2hoYXNoaGFzaA", wantErr: ErrMalformedHash},
+		{name: "empty hash", password: "secret", encoded: "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$", wantErr: ErrMalformedHash},
+		{name: "argon2i", password: "secret", encoded: "$argon2i$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$aGFzaGhhc2hoYXNoaGFzaA", wantErr: ErrMalformedHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := argon2.Verify(tt.password, tt.encoded); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHasher(t *testing.T) {
+	argon2 := NewArgon2id(testArgon2Params)
+	bcryptAlgorithm := NewBcrypt(bcrypt.MinCost)
+
+	argon2Hash := mustHash(t, argon2, "secret")
+	bcryptHash := mustHash(t, bcryptAlgorithm, "secret")
+
+	tests := []struct {
+		name            string
+		hasher          *Hasher
+		password        string
+		encoded         string
+		wantErr         error
+		wantNeedsRehash bool
+	}{
+		{
+			name:     "preferred algorithm",
+			hasher:   NewHasher(argon2, bcryptAlgorithm),
+			password: "secret",
+			encoded:  argon2Hash,
+		},
+		{
+			name:            "legacy algorithm verifies and needs a rehash",
+			hasher:          NewHasher(argon2, bcryptAlgorithm),
+			password:        "secret",
+			encoded:         bcryptHash,
+			wantNeedsRehash: true,
+		},
+		{
+			name:            "wrong password of a legacy hash",
+			hasher:          NewHasher(argon2, bcryptAlgorithm),
+			password:        "wrong",
+			encoded:         bcryptHash,
+			wantErr:         ErrMismatch,
+			wantNeedsRehash: true,
+		},
+		{
+			name:            "preferred algorithm with outdated parameters",
+			hasher:          NewHasher(NewArgon2id(Argon2Params{Time: 2, Memory: 64, Threads: 1, SaltLength: 8, KeyLength: 16})),
+			password:        "secret",
+			encoded:         argon2Hash,
+			wantNeedsRehash: true,
+		},
+		{
+			name:            "bcrypt with another cost",
+			hasher:          NewHasher(NewBcrypt(bcrypt.MinCost + 1)),
+			password:        "secret",
+			encoded:         bcryptHash,
+			wantNeedsRehash: true,
+		},
+		{
+			name:            "unknown format",
+			hasher:          NewHasher(argon2, bcryptAlgorithm),
+			password:        "secret",
+			encoded:         "5ebe2294ecd0e0f08eab7690d2a6ee69",
+			wantErr:         ErrUnknownFormat,
+			wantNeedsRehash: true,
+		},
+		{
+			name:            "algorithm the hasher does not know",
+			hasher:          NewHasher(argon2),
+			password:        "secret",
+			encoded:         bcryptHash,
+			wantErr:         ErrUnknownFormat,
+			wantNeedsRehash: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hasher.Verify(tt.password, tt.encoded); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := tt.hasher.NeedsRehash(tt.encoded); got != tt.wantNeedsRehash {
+				t.Errorf("NeedsRehash() = %t, want %t", got, tt.wantNeedsRehash)
+			}
+		})
+	}
+}
+
+func TestNewHasherByName(t *testing.T) {
+	tests := []struct {
+		name          string
+		wantPreferred string
+		wantErr       bool
+	}{
+		{name: "", wantPreferred: "$argon2id$"},
+		{name: "argon2id", wantPreferred: "$argon2id$"},
+		{name: "bcrypt", wantPreferred: "$2a$"},
+		{name: "md5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		hasher, err := NewHasherByName(tt.name, bcrypt.MinCost, testArgon2Params)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("NewHasherByName(%q) error = %v, want error %t", tt.name, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+
+		hash := mustHash(t, hasher, "secret")
+		if !strings.HasPrefix(hash, tt.wantPreferred) {
+			t.Errorf("NewHasherByName(%q) hashes as %q, want %s", tt.name, hash, tt.wantPreferred)
+		}
+		if hasher.NeedsRehash(hash) {
+			t.Errorf("NewHasherByName(%q) wants to rehash its own hash", tt.name)
+		}
+	}
+}
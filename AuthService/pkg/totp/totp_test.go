@@ -0,0 +1,141 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890"
+// in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCode(t *testing.T) {
+	// The RFC lists 8 digit codes, these are their last 6 digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := GenerateCode(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateCode(%d) error = %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / int64(Period.Seconds())
+
+	codeAt := func(t *testing.T, at time.Time) string {
+		t.Helper()
+		code, err := GenerateCode(rfcSecret, at)
+		if err != nil {
+			t.Fatalf("GenerateCode() error = %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     func(t *testing.T) string
+		secret   string
+		wantStep int64
+		wantOK   bool
+	}{
+		{
+			name:     "current step",
+			code:     func(t *testing.T) string { return codeAt(t, now) },
+			secret:   rfcSecret,
+			wantStep: step,
+			wantOK:   true,
+		},
+		{
+			name:     "previous step is tolerated",
+			code:     func(t *testing.T) string { return codeAt(t, now.Add(-Period)) },
+			secret:   rfcSecret,
+			wantStep: step - 1,
+			wantOK:   true,
+		},
+		{
+			name:     "next step is tolerated",
+			code:     func(t *testing.T) string { return codeAt(t, now.Add(Period)) },
+			secret:   rfcSecret,
+			wantStep: step + 1,
+			wantOK:   true,
+		},
+		{
+			name:   "two steps old",
+			code:   func(t *testing.T) string { return codeAt(t, now.Add(-2*Period)) },
+			secret: rfcSecret,
+		},
+		{
+			name:   "two steps ahead",
+			code:   func(t *testing.T) string { return codeAt(t, now.Add(2*Period)) },
+			secret: rfcSecret,
+		},
+		{
+			name:     "secret in lower case with spaces around",
+			code:     func(t *testing.T) string { return codeAt(t, now) },
+			secret:   " " + strings.ToLower(rfcSecret) + " ",
+			wantStep: step,
+			wantOK:   true,
+		},
+		{
+			name:   "code of another secret",
+			code:   func(t *testing.T) string { return codeAt(t, now) },
+			secret: "JBSWY3DPEHPK3PXP",
+		},
+		{
+			name:   "too short",
+			code:   func(t *testing.T) string { return codeAt(t, now)[1:] },
+			secret: rfcSecret,
+		},
+		{
+			name:   "8 digit code of the RFC",
+			code:   func(t *testing.T) string { return "14050471" },
+			secret: rfcSecret,
+		},
+		{
+			name:   "secret is not base32",
+			code:   func(t *testing.T) string { return "050471" },
+			secret: "not base32!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK := Validate(tt.code(t), tt.secret, now)
+			if gotOK != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate() = (%d, %t), want (%d, %t)", gotStep, gotOK, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+
+	code, err := GenerateCode(secret, time.Now())
+	if err != nil {
+		t.Fatalf("GenerateCode() with a generated secret error = %v", err)
+	}
+	if _, ok := Validate(code, secret, time.Now()); !ok {
+		t.Errorf("Validate() rejected the code of a generated secret")
+	}
+}
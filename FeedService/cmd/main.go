@@ -9,16 +9,21 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 )
 
+// fanOutShutdownTimeout bounds how long shutdown waits for queued posts to be
+// pushed into timelines.
+const fanOutShutdownTimeout = 10 * time.Second
+
 func main() {
 	configPath := config.InitFlags()
 
 	log, err := logger.New()
 	if err != nil {
-		fmt.Errorf("Failed load to logger %w", err)
+		fmt.Printf("Failed load to logger: %v\n", err)
 		return
 	}
 
@@ -37,6 +42,10 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	go application.UserDeletedConsumer.Start(ctx)
+
+	application.FeedService.StartFanOut()
+
 	go func() {
 		if err := application.GRPCServer.Run(); err != nil {
 			log.Error("GRPC server failed", zap.Error(err))
@@ -50,6 +59,13 @@ func main() {
 	case <-stop:
 		log.Info("Shutting down gracefully...")
 		application.GRPCServer.Stop()
+
+		fanOutCtx, cancelFanOut := context.WithTimeout(context.Background(), fanOutShutdownTimeout)
+		application.FeedService.StopFanOut(fanOutCtx)
+		cancelFanOut()
+
+		cancel()
+		application.UserDeletedConsumer.Close()
 	case <-ctx.Done():
 		log.Info("Forced shutdown...")
 	}
@@ -1,19 +1,36 @@
 package app
 
 import (
+	"Project/FeedService/internal/auth"
 	"Project/FeedService/internal/config"
+	"Project/FeedService/internal/kafka"
 	"Project/FeedService/internal/repositories/postgres"
+	"Project/FeedService/internal/repositories/redis"
 	"Project/FeedService/internal/service"
 	"Project/FeedService/internal/transport/handlers"
+	"Project/FeedService/internal/transport/interceptors"
 	"Project/FeedService/internal/transport/server"
 	"Project/FeedService/pkg/database"
+	"Project/FeedService/pkg/jwt"
+	"Project/pkg/revocation"
+	"Project/proto/gen"
+	"context"
 	"fmt"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
 	"Project/FeedService/internal/logger"
 )
 
 type App struct {
 	GRPCServer *server.GRPCServer
+	// FeedService pushes new posts into timelines in the background, see
+	// StartFanOut.
+	FeedService *service.FeedService
+	// UserDeletedConsumer removes the posts, follows and reactions of deleted
+	// accounts.
+	UserDeletedConsumer *kafka.UserDeletedConsumer
 }
 
 func NewApp(log *logger.Logger, cfg *config.Config) (*App, error) {
@@ -27,11 +44,75 @@ func NewApp(log *logger.Logger, cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("Failed to init user repositories:%w", err)
 	}
 
-	feedService:=service.NewFeedService(feedRepo,cfg.Auth.SecretKey)
+	authConn, err := grpc.NewClient(cfg.Auth.AuthServiceAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to AuthService:%w", err)
+	}
+
+	keySet := jwt.NewKeySet(gen.NewAuthenticationClient(authConn), cfg.Auth.JWKSRefreshInterval)
+
+	revokedTokens := revocation.NewCache(revocation.NewList(cfg.Redis.Addr), cfg.Auth.RevocationResyncInterval, log.Logger)
+	if err := revokedTokens.Start(context.Background()); err != nil {
+		return nil, fmt.Errorf("Failed to load revoked tokens:%w", err)
+	}
+
+	if cfg.Auth.Issuer == "" || cfg.Auth.Audience == "" || cfg.Auth.OAuthAudience == "" {
+		return nil, fmt.Errorf("Invalid auth config: issuer, audience and oauth_audience are required")
+	}
+
+	authInterceptor := interceptors.NewAuthInterceptor(
+		keySet,
+		revokedTokens,
+		cfg.Auth.Issuer,
+		cfg.Auth.Audience,
+		cfg.Auth.OAuthAudience,
+		[]string{
+			gen.FeedService_GetAllPosts_FullMethodName,
+			gen.FeedService_GetPost_FullMethodName,
+			gen.FeedService_ListFollowers_FullMethodName,
+			gen.FeedService_ListFollowing_FullMethodName,
+		},
+		map[string]string{
+			gen.FeedService_GetAllPosts_FullMethodName:     auth.ScopePostsRead,
+			gen.FeedService_GetPost_FullMethodName:         auth.ScopePostsRead,
+			gen.FeedService_ListFollowers_FullMethodName:   auth.ScopePostsRead,
+			gen.FeedService_ListFollowing_FullMethodName:   auth.ScopePostsRead,
+			gen.FeedService_GetHomeTimeline_FullMethodName: auth.ScopePostsRead,
+			gen.FeedService_CreatePost_FullMethodName:      auth.ScopePostsWrite,
+			gen.FeedService_UpdatePost_FullMethodName:      auth.ScopePostsWrite,
+			gen.FeedService_DeletePost_FullMethodName:      auth.ScopePostsWrite,
+			gen.FeedService_LikePost_FullMethodName:        auth.ScopePostsWrite,
+			gen.FeedService_UnlikePost_FullMethodName:      auth.ScopePostsWrite,
+			gen.FeedService_AddReaction_FullMethodName:     auth.ScopePostsWrite,
+			gen.FeedService_RemoveReaction_FullMethodName:  auth.ScopePostsWrite,
+		},
+		log.Logger,
+	)
+
+	if cfg.Timeline.CelebrityFollowers < 1 || cfg.Timeline.CacheSize < 1 || cfg.Timeline.ActiveTTL <= 0 ||
+		cfg.Timeline.FanOutWorkers < 1 || cfg.Timeline.FanOutQueueSize < 1 {
+		return nil, fmt.Errorf("Invalid timeline config: celebrity_followers, cache_size, active_ttl, fan_out_workers and fan_out_queue_size must be positive")
+	}
+
+	timelineCache := redis.NewTimelineCache(cfg.Redis.Addr, cfg.Timeline.CacheSize, cfg.Timeline.ActiveTTL)
+
+	feedService:=service.NewFeedService(feedRepo, timelineCache, service.TimelineSettings{
+		CelebrityFollowers: cfg.Timeline.CelebrityFollowers,
+		FanOutWorkers:      cfg.Timeline.FanOutWorkers,
+		FanOutQueueSize:    cfg.Timeline.FanOutQueueSize,
+	}, log.Logger)
+
+	feedHandlers:=handlers.NewFeedHandlers(feedService,log.Logger)
 
-	feedHandlers:=handlers.NewFeedHandlers(feedService,log.Logger,cfg.Auth.SecretKey)
+	grpsServer:=server.NewGRPCServer(log.Logger,cfg.Grpc.Port,feedHandlers,authInterceptor)
 
-	grpsServer:=server.NewGRPCServer(log.Logger,cfg.Grpc.Port,feedHandlers)
+	userDeletedConsumer := kafka.NewUserDeletedConsumer(
+		[]string{cfg.Kafka.Broker},
+		cfg.Kafka.UserDeletedTopic,
+		cfg.Kafka.GroupID,
+		feedService,
+		log,
+	)
 
-	return &App{GRPCServer: grpsServer}, nil
+	return &App{GRPCServer: grpsServer, FeedService: feedService, UserDeletedConsumer: userDeletedConsumer}, nil
 }
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+
+	"Project/pkg/authz"
+
+	"github.com/google/uuid"
+)
+
+// Scopes OAuth clients need for FeedService RPCs.
+const (
+	ScopePostsRead  = "posts:read"
+	ScopePostsWrite = "posts:write"
+)
+
+// Principal is the authenticated caller of a FeedService RPC, taken from a
+// verified access token. When an OAuth client calls on behalf of a user,
+// ClientID and the granted Scopes are set and the principal has no roles; for
+// the client credentials grant UserID is uuid.Nil as well.
+type Principal struct {
+	UserID      uuid.UUID
+	SessionID   uuid.UUID
+	Email       string
+	Roles       []string
+	Permissions []string
+	ClientID    string
+	Scopes      []string
+}
+
+// Authz returns the principal in the form the authz checks take.
+func (p *Principal) Authz() authz.Principal {
+	return authz.Principal{
+		UserID:      p.UserID,
+		Roles:       p.Roles,
+		Permissions: p.Permissions,
+	}
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"flag"
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,6 +13,9 @@ type Config struct {
 	Postgres *PostgresConfig `mapstructure:"postgres"`
 	Grpc     *GRPCConfig     `mapstructure:"grpc"`
 	Auth     *AuthConfig     `mapstructure:"auth"`
+	Redis    *RedisConfig    `mapstructure:"redis"`
+	Kafka    *KafkaConfig    `mapstructure:"kafka"`
+	Timeline *TimelineConfig `mapstructure:"timeline"`
 }
 type PostgresConfig struct {
 	StoragePath string `mapstructure:"storage_path"`
@@ -21,8 +25,39 @@ type GRPCConfig struct {
 	Port int `mapstructure:"port"`
 }
 
+// AuthConfig names the tokens FeedService accepts: they are issued by Issuer,
+// for Audience in first-party sessions and for OAuthAudience to OAuth clients.
+// All three are required.
 type AuthConfig struct {
-	SecretKey string `mapstructure:"secret_key"`
+	AuthServiceAddress       string        `mapstructure:"auth_service_address"`
+	Issuer                   string        `mapstructure:"issuer"`
+	Audience                 string        `mapstructure:"audience"`
+	OAuthAudience            string        `mapstructure:"oauth_audience"`
+	JWKSRefreshInterval      time.Duration `mapstructure:"jwks_refresh_interval"`
+	RevocationResyncInterval time.Duration `mapstructure:"revocation_resync_interval"`
+}
+
+type KafkaConfig struct {
+	Broker           string `mapstructure:"broker"`
+	UserDeletedTopic string `mapstructure:"user_deleted_topic"`
+	GroupID          string `mapstructure:"group_id"`
+}
+
+// TimelineConfig tunes the home timelines. Users with at least
+// CelebrityFollowers followers have their posts read with the timeline instead
+// of pushed into it. Cached timelines keep CacheSize posts and expire once they
+// have not been read for ActiveTTL. New posts are pushed by FanOutWorkers
+// workers and up to FanOutQueueSize of them wait for a worker.
+type TimelineConfig struct {
+	CelebrityFollowers int           `mapstructure:"celebrity_followers"`
+	CacheSize          int           `mapstructure:"cache_size"`
+	ActiveTTL          time.Duration `mapstructure:"active_ttl"`
+	FanOutWorkers      int           `mapstructure:"fan_out_workers"`
+	FanOutQueueSize    int           `mapstructure:"fan_out_queue_size"`
+}
+
+type RedisConfig struct {
+	Addr string `mapstructure:"addr"`
 }
 
 func InitFlags() string {
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrPostNotFound    = errors.New("post not found")
+)
+
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// ValidationError reports invalid request fields. The transport layer turns it
+// into codes.InvalidArgument with the violations attached as details.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func NewValidationError(field, description string) *ValidationError {
+	return &ValidationError{Violations: []FieldViolation{{Field: field, Description: description}}}
+}
+
+func (e *ValidationError) Add(field, description string) {
+	e.Violations = append(e.Violations, FieldViolation{Field: field, Description: description})
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+
+	return "validation failed: " + strings.Join(parts, "; ")
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostCursor is the position of the last post of a feed page; the next page
+// starts with the post right after it.
+type PostCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
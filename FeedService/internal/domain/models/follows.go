@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Follow is an entry of a followers or following list: the other user and
+// when the follow started.
+type Follow struct {
+	UserID     uuid.UUID
+	FollowedAt time.Time
+}
+
+// TimelineEntry is a post as it is kept in a cached home timeline.
+type TimelineEntry struct {
+	PostID    uuid.UUID
+	CreatedAt time.Time
+}
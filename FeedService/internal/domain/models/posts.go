@@ -12,4 +12,13 @@ type Post struct {
 	Content   string    
 	ImageURL  string    
 	CreatedAt time.Time 
+	UpdatedAt *time.Time
+	// DeletedAt is only set on soft-deleted posts, which only the data export
+	// returns.
+	DeletedAt *time.Time
+
+	// ReactionCounts holds the number of reactions of every type the post has
+	// and MyReactions those of the caller; both are only filled in for reads.
+	ReactionCounts map[string]int
+	MyReactions    []string
 }
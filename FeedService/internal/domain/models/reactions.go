@@ -0,0 +1,13 @@
+package models
+
+// Reactions users can leave on posts, each at most once per post.
+const (
+	ReactionLike  = "like"
+	ReactionLove  = "love"
+	ReactionHaha  = "haha"
+	ReactionWow   = "wow"
+	ReactionSad   = "sad"
+	ReactionAngry = "angry"
+)
+
+var Reactions = []string{ReactionLike, ReactionLove, ReactionHaha, ReactionWow, ReactionSad, ReactionAngry}
@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"Project/FeedService/internal/logger"
+	"Project/FeedService/internal/service"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+const retryDelay = 5 * time.Second
+
+// UserDeletedMessage is published by AuthService when an account is deleted.
+type UserDeletedMessage struct {
+	UserID    string    `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// UserDeletedConsumer removes the posts, follows and reactions of deleted users.
+// An offset is only committed once the posts, follows and reactions are all
+// gone, a failed deletion is retried.
+type UserDeletedConsumer struct {
+	reader  *kafka.Reader
+	logger  *logger.Logger
+	service *service.FeedService
+}
+
+func NewUserDeletedConsumer(brokers []string, topic string, groupID string, service *service.FeedService, log *logger.Logger) *UserDeletedConsumer {
+	return &UserDeletedConsumer{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: brokers,
+			GroupID: groupID,
+			Topic:   topic,
+		}),
+		logger:  log,
+		service: service,
+	}
+}
+
+func (c *UserDeletedConsumer) Start(ctx context.Context) {
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.logger.Info("Kafka Consumer stopped")
+				return
+			}
+			c.logger.Error("Failed to read message from Kafka", zap.Error(err))
+			continue
+		}
+
+		for !c.handle(ctx, msg) {
+			select {
+			case <-ctx.Done():
+				c.logger.Info("Kafka Consumer stopped")
+				return
+			case <-time.After(retryDelay):
+			}
+		}
+
+		if err := c.reader.CommitMessages(ctx, msg); err != nil {
+			c.logger.Error("Failed to commit message", zap.Error(err))
+		}
+	}
+}
+
+// handle reports whether the message is done with. Malformed messages are
+// skipped since retrying cannot fix them.
+func (c *UserDeletedConsumer) handle(ctx context.Context, msg kafka.Message) bool {
+	var event UserDeletedMessage
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		c.logger.Error("Failed to unmarshal message", zap.Error(err))
+		return true
+	}
+
+	userID, err := uuid.Parse(event.UserID)
+	if err != nil {
+		c.logger.Error("Invalid user ID in user deleted event", zap.String("userID", event.UserID))
+		return true
+	}
+
+	deleted, err := c.service.DeleteUserPosts(ctx, userID)
+	if err != nil {
+		c.logger.Error("Failed to delete posts of deleted user", zap.String("userID", event.UserID), zap.Error(err))
+		return false
+	}
+
+	if err := c.service.DeleteUserFollows(ctx, userID); err != nil {
+		c.logger.Error("Failed to delete follows of deleted user", zap.String("userID", event.UserID), zap.Error(err))
+		return false
+	}
+
+	if err := c.service.DeleteUserReactions(ctx, userID); err != nil {
+		c.logger.Error("Failed to delete reactions of deleted user", zap.String("userID", event.UserID), zap.Error(err))
+		return false
+	}
+
+	c.logger.Info("Deleted posts of deleted user", zap.String("userID", event.UserID), zap.Int64("posts", deleted))
+	return true
+}
+
+func (c *UserDeletedConsumer) Close() error {
+	return c.reader.Close()
+}
@@ -1,10 +1,14 @@
 package postgres
 
 import (
+	"Project/FeedService/internal/domain"
 	"Project/FeedService/internal/domain/models"
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,7 +16,6 @@ type FeedRepositories struct {
 	db *pgxpool.Pool
 }
 
-
 func NewPostRepositories(db *pgxpool.Pool) (*FeedRepositories, error) {
 	return &FeedRepositories{
 		db: db,
@@ -24,47 +27,219 @@ func (r *FeedRepositories) CloseDb() {
 }
 
 func (r *FeedRepositories) CreatePost(ctx context.Context, post *models.Post) error {
-    query := `
-        INSERT INTO posts (user_id, content, image_url, created_at)
-        VALUES ($1, $2, $3, $4)
+	query := `
+        INSERT INTO posts (id, user_id, content, image_url, created_at)
+        VALUES ($1, $2, $3, $4, $5)
     `
-    _, err := r.db.Exec(ctx, query, post.UserID, post.Content, post.ImageURL, post.CreatedAt)
-    if err != nil {
-        return fmt.Errorf("failed to create post: %w", err)
-    }
-    return nil
+	_, err := r.db.Exec(ctx, query, post.ID, post.UserID, post.Content, post.ImageURL, post.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create post: %w", err)
+	}
+	return nil
 }
 
 func (r *FeedRepositories) GetALLPosts(ctx context.Context, page, pageSize int) ([]models.Post, int, error) {
-    offset := (page - 1) * pageSize
-
-   
-    query := `
-        SELECT id, user_id, content, image_url, created_at
-        FROM posts
-        ORDER BY created_at DESC
-        LIMIT $1 OFFSET $2
-    `
-    rows, err := r.db.Query(ctx, query, pageSize, offset)
-    if err != nil {
-        return nil, 0, fmt.Errorf("failed to get posts: %w", err)
-    }
-    defer rows.Close()
-
-    var posts []models.Post
-    for rows.Next() {
-        var post models.Post
-        if err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.ImageURL, &post.CreatedAt); err != nil {
-            return nil, 0, fmt.Errorf("failed to scan post: %w", err)
-        }
-        posts = append(posts, post)
-    }
-	
-    var totalPosts int
-    countQuery := `SELECT COUNT(*) FROM posts`
-    if err := r.db.QueryRow(ctx, countQuery).Scan(&totalPosts); err != nil {
-        return nil, 0, fmt.Errorf("failed to count posts: %w", err)
-    }
-
-    return posts, totalPosts, nil
-}
\ No newline at end of file
+	offset := (page - 1) * pageSize
+
+	query := `
+		SELECT id, user_id, content, COALESCE(image_url, ''), created_at, updated_at
+		FROM posts
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.Query(ctx, query, pageSize, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	posts, err := scanPosts(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var totalPosts int
+	countQuery := `SELECT COUNT(*) FROM posts WHERE deleted_at IS NULL`
+	if err := r.db.QueryRow(ctx, countQuery).Scan(&totalPosts); err != nil {
+		return nil, 0, fmt.Errorf("failed to count posts: %w", err)
+	}
+
+	return posts, totalPosts, nil
+}
+
+// GetPostsPage returns up to limit posts older than the cursor, newest first; a
+// nil cursor starts at the newest post. Posts created at the same time are
+// ordered by ID so every post is returned exactly once.
+func (r *FeedRepositories) GetPostsPage(ctx context.Context, cursor *models.PostCursor, limit int) ([]models.Post, error) {
+	query := `
+		SELECT id, user_id, content, COALESCE(image_url, ''), created_at, updated_at
+		FROM posts
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`
+	args := []any{limit}
+
+	if cursor != nil {
+		query = `
+			SELECT id, user_id, content, COALESCE(image_url, ''), created_at, updated_at
+			FROM posts
+			WHERE deleted_at IS NULL AND (created_at, id) < ($2, $3)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $1
+		`
+		args = append(args, cursor.CreatedAt, cursor.ID)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	return scanPosts(rows)
+}
+
+// EstimatePostsCount returns the row count the planner keeps for the posts
+// table, which is cheap but only as fresh as the last ANALYZE and includes
+// deleted posts.
+func (r *FeedRepositories) EstimatePostsCount(ctx context.Context) (int, error) {
+	var estimate int64
+	query := `SELECT reltuples::bigint FROM pg_class WHERE oid = 'posts'::regclass`
+	if err := r.db.QueryRow(ctx, query).Scan(&estimate); err != nil {
+		return 0, fmt.Errorf("failed to estimate posts count: %w", err)
+	}
+
+	// reltuples is -1 until the table has been analyzed for the first time.
+	return int(max(estimate, 0)), nil
+}
+
+func (r *FeedRepositories) GetPostsByUser(ctx context.Context, userID uuid.UUID) ([]models.Post, error) {
+	query := `
+		SELECT id, user_id, content, COALESCE(image_url, ''), created_at, updated_at
+		FROM posts
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	return scanPosts(rows)
+}
+
+func (r *FeedRepositories) GetPost(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	query := `
+		SELECT id, user_id, content, COALESCE(image_url, ''), created_at, updated_at
+		FROM posts
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	var post models.Post
+	err := r.db.QueryRow(ctx, query, postID).Scan(&post.ID, &post.UserID, &post.Content, &post.ImageURL, &post.CreatedAt, &post.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPostNotFound
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	return &post, nil
+}
+
+// UpdatePost saves the new content of the post and keeps the previous version
+// in post_edits.
+func (r *FeedRepositories) UpdatePost(ctx context.Context, post *models.Post, editorID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	historyQuery := `
+		INSERT INTO post_edits (post_id, editor_id, previous_content, previous_image_url, edited_at)
+		SELECT id, $2, content, image_url, $3
+		FROM posts
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	tag, err := tx.Exec(ctx, historyQuery, post.ID, editorID, post.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save post edit: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPostNotFound
+	}
+
+	updateQuery := `
+		UPDATE posts
+		SET content = $2, image_url = $3, updated_at = $4
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, updateQuery, post.ID, post.Content, post.ImageURL, post.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update post: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit post update: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeletedPostsByUser returns the posts of the user that were deleted but not
+// removed yet, newest first, for the data export.
+func (r *FeedRepositories) GetDeletedPostsByUser(ctx context.Context, userID uuid.UUID) ([]models.Post, error) {
+	query := `
+		SELECT id, user_id, content, COALESCE(image_url, ''), created_at, updated_at, deleted_at
+		FROM posts
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+	for rows.Next() {
+		var post models.Post
+		if err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.ImageURL, &post.CreatedAt, &post.UpdatedAt, &post.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get deleted posts: %w", err)
+	}
+
+	return posts, nil
+}
+
+// DeletePost hides the post; it is kept together with its edits until the
+// author deletes their account.
+func (r *FeedRepositories) DeletePost(ctx context.Context, postID, deletedBy uuid.UUID) error {
+	query := `
+		UPDATE posts
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, postID, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete post: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPostNotFound
+	}
+
+	return nil
+}
+
+func (r *FeedRepositories) DeletePostsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM posts WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete posts: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"Project/FeedService/internal/domain/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Follow makes follower follow followee and reports whether it did not already.
+func (r *FeedRepositories) Follow(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	insertQuery := `
+		INSERT INTO follows (follower_id, followee_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, insertQuery, followerID, followeeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to follow user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if err := updateFollowStats(ctx, tx, followerID, followeeID, 1); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit follow: %w", err)
+	}
+
+	return true, nil
+}
+
+// Unfollow reports whether follower was following followee.
+func (r *FeedRepositories) Unfollow(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`, followerID, followeeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to unfollow user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if err := updateFollowStats(ctx, tx, followerID, followeeID, -1); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit unfollow: %w", err)
+	}
+
+	return true, nil
+}
+
+func updateFollowStats(ctx context.Context, tx pgx.Tx, followerID, followeeID uuid.UUID, delta int) error {
+	query := `
+		INSERT INTO follow_stats (user_id, followers_count, following_count)
+		VALUES ($1, GREATEST($2, 0), GREATEST($3, 0))
+		ON CONFLICT (user_id) DO UPDATE
+		SET followers_count = follow_stats.followers_count + $2,
+		    following_count = follow_stats.following_count + $3
+	`
+	if _, err := tx.Exec(ctx, query, followeeID, delta, 0); err != nil {
+		return fmt.Errorf("failed to update followers count: %w", err)
+	}
+	if _, err := tx.Exec(ctx, query, followerID, 0, delta); err != nil {
+		return fmt.Errorf("failed to update following count: %w", err)
+	}
+
+	return nil
+}
+
+// ListFollowers returns the users following userID, latest first, and how many
+// there are.
+func (r *FeedRepositories) ListFollowers(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]models.Follow, int, error) {
+	query := `
+		SELECT follower_id, created_at
+		FROM follows
+		WHERE followee_id = $1
+		ORDER BY created_at DESC, follower_id
+		LIMIT $2 OFFSET $3
+	`
+	return r.listFollows(ctx, query, `SELECT followers_count FROM follow_stats WHERE user_id = $1`, userID, page, pageSize)
+}
+
+// ListFollowing returns the users userID follows, latest first, and how many
+// there are.
+func (r *FeedRepositories) ListFollowing(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]models.Follow, int, error) {
+	query := `
+		SELECT followee_id, created_at
+		FROM follows
+		WHERE follower_id = $1
+		ORDER BY created_at DESC, followee_id
+		LIMIT $2 OFFSET $3
+	`
+	return r.listFollows(ctx, query, `SELECT following_count FROM follow_stats WHERE user_id = $1`, userID, page, pageSize)
+}
+
+func (r *FeedRepositories) listFollows(ctx context.Context, query, countQuery string, userID uuid.UUID, page, pageSize int) ([]models.Follow, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, countQuery, userID).Scan(&total); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to count follows: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list follows: %w", err)
+	}
+	defer rows.Close()
+
+	var follows []models.Follow
+	for rows.Next() {
+		var follow models.Follow
+		if err := rows.Scan(&follow.UserID, &follow.FollowedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan follow: %w", err)
+		}
+		follows = append(follows, follow)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list follows: %w", err)
+	}
+
+	return follows, total, nil
+}
+
+func (r *FeedRepositories) GetFollowersCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT followers_count FROM follow_stats WHERE user_id = $1`, userID).Scan(&count)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get followers count: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *FeedRepositories) GetFollowerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `SELECT follower_id FROM follows WHERE followee_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followers: %w", err)
+	}
+	defer rows.Close()
+
+	var followerIDs []uuid.UUID
+	for rows.Next() {
+		var followerID uuid.UUID
+		if err := rows.Scan(&followerID); err != nil {
+			return nil, fmt.Errorf("failed to scan follower: %w", err)
+		}
+		followerIDs = append(followerIDs, followerID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get followers: %w", err)
+	}
+
+	return followerIDs, nil
+}
+
+// GetTimelinePosts returns up to limit posts older than the cursor of the users
+// followerID follows, newest first. With celebrities it only reads the posts of
+// users with at least celebrityFollowers followers, otherwise only those of the
+// others.
+func (r *FeedRepositories) GetTimelinePosts(ctx context.Context, followerID uuid.UUID, celebrityFollowers int, celebrities bool, cursor *models.PostCursor, limit int) ([]models.Post, error) {
+	query := `
+		SELECT p.id, p.user_id, p.content, COALESCE(p.image_url, ''), p.created_at, p.updated_at
+		FROM follows f
+		JOIN posts p ON p.user_id = f.followee_id AND p.deleted_at IS NULL
+		LEFT JOIN follow_stats s ON s.user_id = f.followee_id
+		WHERE f.follower_id = $1
+		  AND (COALESCE(s.followers_count, 0) >= $2) = $3
+		  AND ($4::timestamp IS NULL OR (p.created_at, p.id) < ($4, $5))
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $6
+	`
+
+	var (
+		afterCreatedAt *time.Time
+		afterID        uuid.UUID
+	)
+	if cursor != nil {
+		afterCreatedAt, afterID = &cursor.CreatedAt, cursor.ID
+	}
+
+	rows, err := r.db.Query(ctx, query, followerID, celebrityFollowers, celebrities, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timeline posts: %w", err)
+	}
+
+	return scanPosts(rows)
+}
+
+// GetPostsByIDs returns the posts that still exist, in no particular order.
+func (r *FeedRepositories) GetPostsByIDs(ctx context.Context, postIDs []uuid.UUID) ([]models.Post, error) {
+	query := `
+		SELECT id, user_id, content, COALESCE(image_url, ''), created_at, updated_at
+		FROM posts
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`
+	rows, err := r.db.Query(ctx, query, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	return scanPosts(rows)
+}
+
+// DeleteFollowsByUser removes a deleted account from the follow graph.
+func (r *FeedRepositories) DeleteFollowsByUser(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := []string{
+		`UPDATE follow_stats s SET followers_count = s.followers_count - 1
+		 FROM follows f WHERE f.follower_id = $1 AND s.user_id = f.followee_id`,
+		`UPDATE follow_stats s SET following_count = s.following_count - 1
+		 FROM follows f WHERE f.followee_id = $1 AND s.user_id = f.follower_id`,
+		`DELETE FROM follows WHERE follower_id = $1 OR followee_id = $1`,
+		`DELETE FROM follow_stats WHERE user_id = $1`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(ctx, query, userID); err != nil {
+			return fmt.Errorf("failed to delete follows: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit follows deletion: %w", err)
+	}
+
+	return nil
+}
+
+// scanPosts reads every post of rows and closes them.
+func scanPosts(rows pgx.Rows) ([]models.Post, error) {
+	defer rows.Close()
+
+	var posts []models.Post
+	for rows.Next() {
+		var post models.Post
+		if err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.ImageURL, &post.CreatedAt, &post.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	return posts, nil
+}
@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"Project/FeedService/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// AddReaction records the reaction of the user to a post that is not deleted
+// and reports whether the user had not reacted so already.
+func (r *FeedRepositories) AddReaction(ctx context.Context, postID, userID uuid.UUID, reaction string) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The share lock keeps the post from being deleted until the reaction is in.
+	var exists int
+	err = tx.QueryRow(ctx, `SELECT 1 FROM posts WHERE id = $1 AND deleted_at IS NULL FOR SHARE`, postID).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, domain.ErrPostNotFound
+		}
+		return false, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO post_reactions (post_id, user_id, reaction)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, insertQuery, postID, userID, reaction)
+	if err != nil {
+		return false, fmt.Errorf("failed to add reaction: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	countQuery := `
+		INSERT INTO post_reaction_counts (post_id, reaction, count)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (post_id, reaction) DO UPDATE
+		SET count = post_reaction_counts.count + 1
+	`
+	if _, err := tx.Exec(ctx, countQuery, postID, reaction); err != nil {
+		return false, fmt.Errorf("failed to update reaction count: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit reaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// RemoveReaction reports whether the user had reacted so to the post.
+func (r *FeedRepositories) RemoveReaction(ctx context.Context, postID, userID uuid.UUID, reaction string) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	deleteQuery := `DELETE FROM post_reactions WHERE post_id = $1 AND user_id = $2 AND reaction = $3`
+	tag, err := tx.Exec(ctx, deleteQuery, postID, userID, reaction)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	countQuery := `UPDATE post_reaction_counts SET count = count - 1 WHERE post_id = $1 AND reaction = $2`
+	if _, err := tx.Exec(ctx, countQuery, postID, reaction); err != nil {
+		return false, fmt.Errorf("failed to update reaction count: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit reaction removal: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetReactionCounts returns the reaction counts of the posts by post and
+// reaction. Posts without reactions are missing.
+func (r *FeedRepositories) GetReactionCounts(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]map[string]int, error) {
+	query := `
+		SELECT post_id, reaction, count
+		FROM post_reaction_counts
+		WHERE post_id = ANY($1) AND count > 0
+	`
+	rows, err := r.db.Query(ctx, query, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reaction counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]map[string]int)
+	for rows.Next() {
+		var (
+			postID   uuid.UUID
+			reaction string
+			count    int
+		)
+		if err := rows.Scan(&postID, &reaction, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction count: %w", err)
+		}
+		if counts[postID] == nil {
+			counts[postID] = make(map[string]int)
+		}
+		counts[postID][reaction] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get reaction counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetUserReactions returns the reactions of the user to the posts by post.
+func (r *FeedRepositories) GetUserReactions(ctx context.Context, userID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	query := `
+		SELECT post_id, reaction
+		FROM post_reactions
+		WHERE user_id = $1 AND post_id = ANY($2)
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(ctx, query, userID, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	defer rows.Close()
+
+	reactions := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var (
+			postID   uuid.UUID
+			reaction string
+		)
+		if err := rows.Scan(&postID, &reaction); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction: %w", err)
+		}
+		reactions[postID] = append(reactions[postID], reaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+
+	return reactions, nil
+}
+
+// DeleteReactionsByUser removes the reactions of a deleted account.
+func (r *FeedRepositories) DeleteReactionsByUser(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	countQuery := `
+		UPDATE post_reaction_counts c SET count = c.count - 1
+		FROM post_reactions r
+		WHERE r.user_id = $1 AND c.post_id = r.post_id AND c.reaction = r.reaction
+	`
+	if _, err := tx.Exec(ctx, countQuery, userID); err != nil {
+		return fmt.Errorf("failed to update reaction counts: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM post_reactions WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete reactions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit reactions deletion: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package redis
+
+import (
+	"Project/FeedService/internal/domain/models"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// addToTimeline adds posts, given as score and ID pairs after the size limit,
+// only to timelines that are cached, which are those of users who read their
+// timeline recently, and drops the oldest posts beyond the size limit.
+var addToTimeline = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+for i = 2, #ARGV, 2 do
+	redis.call('ZADD', KEYS[1], ARGV[i], ARGV[i + 1])
+end
+redis.call('ZREMRANGEBYRANK', KEYS[1], 0, -(tonumber(ARGV[1]) + 1))
+return 1
+`)
+
+// TimelineCache keeps the home timelines of active users as sorted sets of post
+// IDs scored by their creation time in microseconds. A timeline holds the
+// newest size posts of the followed users that are not celebrities and expires
+// once its owner has not read it for ttl.
+type TimelineCache struct {
+	client *redis.Client
+	size   int
+	ttl    time.Duration
+}
+
+func NewTimelineCache(addr string, size int, ttl time.Duration) *TimelineCache {
+	return &TimelineCache{
+		client: redis.NewClient(&redis.Options{
+			Addr: addr,
+			DB:   0,
+		}),
+		size: size,
+		ttl:  ttl,
+	}
+}
+
+func timelineKey(userID uuid.UUID) string {
+	return fmt.Sprintf("timeline:%s", userID)
+}
+
+// Size is the number of posts a cached timeline holds at most.
+func (c *TimelineCache) Size() int {
+	return c.size
+}
+
+// Page returns up to limit entries of the timeline older than the cursor,
+// newest first. covered is false when the cache cannot tell the whole page,
+// either because the timeline is not cached or because the page reaches past
+// its oldest entry.
+func (c *TimelineCache) Page(ctx context.Context, userID uuid.UUID, cursor *models.PostCursor, limit int) (entries []models.TimelineEntry, covered bool, err error) {
+	key := timelineKey(userID)
+
+	size, err := c.client.ZCard(ctx, key).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get timeline size: %w", err)
+	}
+	if size == 0 {
+		return nil, false, nil
+	}
+
+	maxScore := "+inf"
+	if cursor != nil {
+		maxScore = strconv.FormatInt(cursor.CreatedAt.UnixMicro(), 10)
+	}
+
+	// Entries at the time of the cursor are filtered out by ID, so more than
+	// one batch may be needed.
+	for offset := int64(0); len(entries) < limit; offset += int64(limit) {
+		batch, err := c.client.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+			Min:    "-inf",
+			Max:    maxScore,
+			Offset: offset,
+			Count:  int64(limit),
+		}).Result()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read timeline: %w", err)
+		}
+
+		for _, member := range batch {
+			entry, ok := parseTimelineEntry(member)
+			if !ok || (cursor != nil && !pastCursor(entry, cursor)) {
+				continue
+			}
+			if len(entries) < limit {
+				entries = append(entries, entry)
+			}
+		}
+
+		if len(batch) < limit {
+			break
+		}
+	}
+
+	if err := c.client.Expire(ctx, key, c.ttl).Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to extend timeline: %w", err)
+	}
+
+	return entries, len(entries) == limit || size < int64(c.size), nil
+}
+
+// pastCursor reports whether the entry comes after the cursor in a timeline,
+// that is whether it is older or as old with a lower ID.
+func pastCursor(entry models.TimelineEntry, cursor *models.PostCursor) bool {
+	if !entry.CreatedAt.Equal(cursor.CreatedAt) {
+		return entry.CreatedAt.Before(cursor.CreatedAt)
+	}
+
+	return entry.PostID.String() < cursor.ID.String()
+}
+
+func parseTimelineEntry(member redis.Z) (models.TimelineEntry, bool) {
+	id, ok := member.Member.(string)
+	if !ok {
+		return models.TimelineEntry{}, false
+	}
+
+	postID, err := uuid.Parse(id)
+	if err != nil {
+		return models.TimelineEntry{}, false
+	}
+
+	return models.TimelineEntry{PostID: postID, CreatedAt: time.UnixMicro(int64(member.Score)).UTC()}, true
+}
+
+// Store replaces the cached timeline of the user. An empty timeline is not
+// cached at all.
+func (c *TimelineCache) Store(ctx context.Context, userID uuid.UUID, entries []models.TimelineEntry) error {
+	key := timelineKey(userID)
+
+	members := make([]*redis.Z, 0, len(entries))
+	for _, entry := range entries {
+		members = append(members, &redis.Z{Score: float64(entry.CreatedAt.UnixMicro()), Member: entry.PostID.String()})
+	}
+
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		if len(members) > 0 {
+			pipe.ZAdd(ctx, key, members...)
+			pipe.Expire(ctx, key, c.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store timeline: %w", err)
+	}
+
+	return nil
+}
+
+// Add puts a new post into the cached timelines of the followers of its author.
+func (c *TimelineCache) Add(ctx context.Context, followerIDs []uuid.UUID, entry models.TimelineEntry) error {
+	if len(followerIDs) == 0 {
+		return nil
+	}
+
+	score := entry.CreatedAt.UnixMicro()
+	postID := entry.PostID.String()
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, followerID := range followerIDs {
+			addToTimeline.Eval(ctx, pipe, []string{timelineKey(followerID)}, c.size, score, postID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add post to timelines: %w", err)
+	}
+
+	return nil
+}
+
+// Merge adds entries to the cached timeline of the user. It does nothing when the
+// timeline is not cached.
+func (c *TimelineCache) Merge(ctx context.Context, userID uuid.UUID, entries []models.TimelineEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, 1+2*len(entries))
+	args = append(args, c.size)
+	for _, entry := range entries {
+		args = append(args, entry.CreatedAt.UnixMicro(), entry.PostID.String())
+	}
+
+	if err := addToTimeline.Run(ctx, c.client, []string{timelineKey(userID)}, args...).Err(); err != nil {
+		return fmt.Errorf("failed to merge into timeline: %w", err)
+	}
+
+	return nil
+}
+
+// Invalidate drops the cached timeline of the user, e.g. after they followed or
+// unfollowed somebody. It is rebuilt on the next read.
+func (c *TimelineCache) Invalidate(ctx context.Context, userID uuid.UUID) error {
+	if err := c.client.Del(ctx, timelineKey(userID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate timeline: %w", err)
+	}
+
+	return nil
+}
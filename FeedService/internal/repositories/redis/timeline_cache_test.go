@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"Project/FeedService/internal/domain/models"
+
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPastCursor(t *testing.T) {
+	now := time.Date(2025, 5, 16, 10, 0, 0, 0, time.UTC)
+	low := uuid.MustParse("00000000-0000-4000-8000-000000000001")
+	high := uuid.MustParse("ffffffff-0000-4000-8000-000000000001")
+
+	tests := []struct {
+		name   string
+		entry  models.TimelineEntry
+		cursor models.PostCursor
+		want   bool
+	}{
+		{
+			name:   "older post is past the cursor",
+			entry:  models.TimelineEntry{PostID: high, CreatedAt: now.Add(-time.Microsecond)},
+			cursor: models.PostCursor{ID: low, CreatedAt: now},
+			want:   true,
+		},
+		{
+			name:   "newer post is not past the cursor",
+			entry:  models.TimelineEntry{PostID: low, CreatedAt: now.Add(time.Microsecond)},
+			cursor: models.PostCursor{ID: high, CreatedAt: now},
+		},
+		{
+			name:   "same time with a lower id is past the cursor",
+			entry:  models.TimelineEntry{PostID: low, CreatedAt: now},
+			cursor: models.PostCursor{ID: high, CreatedAt: now},
+			want:   true,
+		},
+		{
+			name:   "same time with a higher id is not past the cursor",
+			entry:  models.TimelineEntry{PostID: high, CreatedAt: now},
+			cursor: models.PostCursor{ID: low, CreatedAt: now},
+		},
+		{
+			name:   "the cursor post itself is not past the cursor",
+			entry:  models.TimelineEntry{PostID: low, CreatedAt: now},
+			cursor: models.PostCursor{ID: low, CreatedAt: now},
+		},
+		{
+			name:   "same time in another zone compares by id",
+			entry:  models.TimelineEntry{PostID: low, CreatedAt: now},
+			cursor: models.PostCursor{ID: high, CreatedAt: now.In(time.FixedZone("MSK", 3*60*60))},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pastCursor(tt.entry, &tt.cursor); got != tt.want {
+				t.Errorf("pastCursor() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"Project/FeedService/internal/domain"
+	"Project/FeedService/internal/domain/models"
+
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// pagedPostsRepo serves GetPostsPage from memory with the ordering and the
+// row comparison of the SQL query. Other repository methods are not expected.
+type pagedPostsRepo struct {
+	FeedRepositories
+
+	posts []models.Post
+}
+
+func (r *pagedPostsRepo) GetPostsPage(_ context.Context, cursor *models.PostCursor, limit int) ([]models.Post, error) {
+	sorted := slices.Clone(r.posts)
+	slices.SortFunc(sorted, func(a, b models.Post) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return slices.Compare(b.ID[:], a.ID[:])
+	})
+
+	var page []models.Post
+	for _, post := range sorted {
+		if cursor != nil && !beforeCursor(post, *cursor) {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, post)
+	}
+
+	return page, nil
+}
+
+func (r *pagedPostsRepo) EstimatePostsCount(_ context.Context) (int, error) {
+	return len(r.posts), nil
+}
+
+func (r *pagedPostsRepo) GetReactionCounts(_ context.Context, _ []uuid.UUID) (map[uuid.UUID]map[string]int, error) {
+	return nil, nil
+}
+
+// beforeCursor is (created_at, id) < (cursor.CreatedAt, cursor.ID) in Postgres,
+// which compares UUIDs byte by byte.
+func beforeCursor(post models.Post, cursor models.PostCursor) bool {
+	if !post.CreatedAt.Equal(cursor.CreatedAt) {
+		return post.CreatedAt.Before(cursor.CreatedAt)
+	}
+
+	return slices.Compare(post.ID[:], cursor.ID[:]) < 0
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("0b6f0c1e-8d5a-4a53-9a59-6c1f2f3a4b5c")
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+	}{
+		{name: "utc with microseconds", createdAt: time.Date(2025, 5, 16, 10, 12, 45, 123456000, time.UTC)},
+		{name: "other time zone", createdAt: time.Date(2025, 5, 16, 13, 12, 45, 123456000, time.FixedZone("MSK", 3*60*60))},
+		{name: "whole seconds", createdAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "before the epoch", createdAt: time.Date(1969, 12, 31, 23, 59, 59, 999999000, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := encodeCursor(models.PostCursor{CreatedAt: tt.createdAt, ID: id})
+
+			cursor, err := decodeCursor(token)
+			if err != nil {
+				t.Fatalf("decodeCursor(%q) error = %v", token, err)
+			}
+
+			if !cursor.CreatedAt.Equal(tt.createdAt) {
+				t.Errorf("created at = %s, want %s", cursor.CreatedAt, tt.createdAt)
+			}
+			if cursor.CreatedAt.Location() != time.UTC {
+				t.Errorf("created at is in %s, want UTC", cursor.CreatedAt.Location())
+			}
+			if cursor.ID != id {
+				t.Errorf("id = %s, want %s", cursor.ID, id)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsMalformedTokens(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "not a cursor!"},
+		{name: "padded base64", token: base64.URLEncoding.EncodeToString([]byte("1:0b6f0c1e-8d5a-4a53-9a59-6c1f2f3a4b5c"))},
+		{name: "no separator", token: encode("1747390365123456")},
+		{name: "time is not a number", token: encode("yesterday:0b6f0c1e-8d5a-4a53-9a59-6c1f2f3a4b5c")},
+		{name: "id is not a uuid", token: encode("1747390365123456:42")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := decodeCursor(tt.token); err == nil {
+				t.Errorf("decodeCursor(%q) = %+v, want an error", tt.token, cursor)
+			}
+		})
+	}
+}
+
+func TestGetPostsPagesByCreatedAtAndID(t *testing.T) {
+	base := time.Date(2025, 5, 16, 10, 0, 0, 0, time.UTC)
+
+	// Posts created at the same time are told apart by ID, so each of these
+	// groups has to be split across pages without losing or repeating posts.
+	var posts []models.Post
+	for i, createdAt := range []time.Time{
+		base.Add(3 * time.Second),
+		base.Add(2 * time.Second),
+		base.Add(2 * time.Second),
+		base.Add(2 * time.Second),
+		base.Add(time.Second),
+		base.Add(time.Second),
+		base,
+	} {
+		posts = append(posts, models.Post{ID: uuid.New(), Content: string(rune('a' + i)), CreatedAt: createdAt})
+	}
+
+	want, err := (&pagedPostsRepo{posts: posts}).GetPostsPage(context.Background(), nil, len(posts))
+	if err != nil {
+		t.Fatalf("failed to sort posts: %v", err)
+	}
+
+	for _, pageSize := range []int{1, 2, 3, len(posts), len(posts) + 1} {
+		t.Run(fmt.Sprintf("page size %d", pageSize), func(t *testing.T) {
+			repo := &pagedPostsRepo{posts: posts}
+			s := &FeedService{repo: repo}
+
+			var (
+				got    []models.Post
+				cursor string
+			)
+			for pages := 0; ; pages++ {
+				if pages > len(posts) {
+					t.Fatalf("paging did not end")
+				}
+
+				page, next, total, err := s.GetPosts(context.Background(), cursor, pageSize, pages == 0)
+				if err != nil {
+					t.Fatalf("GetPosts() error = %v", err)
+				}
+				if len(page) > pageSize {
+					t.Fatalf("page has %d posts, want at most %d", len(page), pageSize)
+				}
+				if pages == 0 && total != len(posts) {
+					t.Errorf("total = %d, want %d", total, len(posts))
+				}
+				got = append(got, page...)
+
+				// A post created after the first page is newer than every
+				// cursor and must not show up on later pages.
+				if pages == 0 {
+					repo.posts = append(repo.posts, models.Post{ID: uuid.New(), CreatedAt: base.Add(time.Hour)})
+				}
+
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("got %d posts, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i].ID != want[i].ID {
+					t.Errorf("post %d = %s (%s), want %s (%s)", i, got[i].Content, got[i].ID, want[i].Content, want[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPostsValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		cursor   string
+		pageSize int
+		field    string
+	}{
+		{name: "page size too small", pageSize: 0, field: "page_size"},
+		{name: "page size too large", pageSize: maxPageSize + 1, field: "page_size"},
+		{name: "invalid cursor", cursor: "garbage!", pageSize: 10, field: "cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &FeedService{repo: &pagedPostsRepo{}}
+
+			_, _, _, err := s.GetPosts(context.Background(), tt.cursor, tt.pageSize, false)
+
+			var validationErr *domain.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("GetPosts() error = %v, want a validation error", err)
+			}
+			if len(validationErr.Violations) != 1 || validationErr.Violations[0].Field != tt.field {
+				t.Errorf("violations = %+v, want one for %s", validationErr.Violations, tt.field)
+			}
+		})
+	}
+}
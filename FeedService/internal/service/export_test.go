@@ -0,0 +1,67 @@
+package service
+
+import (
+	"Project/FeedService/internal/auth"
+	"Project/FeedService/internal/domain/models"
+
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// exportPostsRepo serves the posts of a single user, split into visible and
+// deleted ones the way the SQL queries split them.
+type exportPostsRepo struct {
+	FeedRepositories
+
+	posts []models.Post
+}
+
+func (r *exportPostsRepo) GetPostsByUser(_ context.Context, userID uuid.UUID) ([]models.Post, error) {
+	var posts []models.Post
+	for _, post := range r.posts {
+		if post.UserID == userID && post.DeletedAt == nil {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (r *exportPostsRepo) GetDeletedPostsByUser(_ context.Context, userID uuid.UUID) ([]models.Post, error) {
+	var posts []models.Post
+	for _, post := range r.posts {
+		if post.UserID == userID && post.DeletedAt != nil {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func TestExportMyPostsIncludesDeletedPosts(t *testing.T) {
+	userID := uuid.New()
+	deletedAt := time.Now().UTC()
+
+	visible := models.Post{ID: uuid.New(), UserID: userID, Content: "still here"}
+	deleted := models.Post{ID: uuid.New(), UserID: userID, Content: "deleted", DeletedAt: &deletedAt}
+	other := models.Post{ID: uuid.New(), UserID: uuid.New(), Content: "someone else's"}
+
+	s := &FeedService{repo: &exportPostsRepo{posts: []models.Post{deleted, visible, other}}}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID})
+
+	posts, err := s.ExportMyPosts(ctx)
+	if err != nil {
+		t.Fatalf("ExportMyPosts returned error: %v", err)
+	}
+
+	if len(posts) != 2 {
+		t.Fatalf("exported %d posts, want 2", len(posts))
+	}
+	if posts[0].ID != visible.ID || posts[0].DeletedAt != nil {
+		t.Errorf("first exported post = %+v, want the visible post", posts[0])
+	}
+	if posts[1].ID != deleted.ID || posts[1].DeletedAt == nil || !posts[1].DeletedAt.Equal(deletedAt) {
+		t.Errorf("second exported post = %+v, want the deleted post with its deletion time", posts[1])
+	}
+}
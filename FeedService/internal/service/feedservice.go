@@ -1,67 +1,349 @@
 package service
 
 import (
+	"Project/FeedService/internal/auth"
+	"Project/FeedService/internal/domain"
 	"Project/FeedService/internal/domain/models"
 	"Project/FeedService/internal/repositories/postgres"
+	"Project/FeedService/internal/repositories/redis"
+	"Project/pkg/authz"
 
 	"context"
+	"encoding/base64"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"google.golang.org/grpc/metadata"
+	"go.uber.org/zap"
 )
 
+const maxPageSize = 100
+
 type FeedService struct {
 	repo      FeedRepositories
-	secretKey string
+	timelines TimelineCache
+	logger    *zap.Logger
+
+	// celebrityFollowers is the number of followers from which the posts of a
+	// user are no longer pushed into timelines but read when a timeline is.
+	celebrityFollowers int
+
+	// New posts wait in fanOuts for one of fanOutWorkers workers to push them
+	// into timelines, see StartFanOut.
+	fanOutWorkers int
+	fanOuts       chan models.Post
+	fanOutCtx     context.Context
+	cancelFanOut  context.CancelFunc
+	fanOutDone    sync.WaitGroup
+}
+
+// TimelineSettings tunes the home timelines. Posts of users with at least
+// CelebrityFollowers followers are read with the timeline instead of pushed
+// into it. Other new posts are pushed by FanOutWorkers workers and wait in a
+// queue of FanOutQueueSize posts while the workers are busy.
+type TimelineSettings struct {
+	CelebrityFollowers int
+	FanOutWorkers      int
+	FanOutQueueSize    int
 }
 
 type FeedRepositories interface {
 	CreatePost(ctx context.Context, post *models.Post) error
 	GetALLPosts(ctx context.Context, page, pageSize int) ([]models.Post, int, error)
+	GetPostsPage(ctx context.Context, cursor *models.PostCursor, limit int) ([]models.Post, error)
+	EstimatePostsCount(ctx context.Context) (int, error)
+	GetPost(ctx context.Context, postID uuid.UUID) (*models.Post, error)
+	UpdatePost(ctx context.Context, post *models.Post, editorID uuid.UUID) error
+	DeletePost(ctx context.Context, postID, deletedBy uuid.UUID) error
+	GetPostsByUser(ctx context.Context, userID uuid.UUID) ([]models.Post, error)
+	GetDeletedPostsByUser(ctx context.Context, userID uuid.UUID) ([]models.Post, error)
+	DeletePostsByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	Follow(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error)
+	Unfollow(ctx context.Context, followerID, followeeID uuid.UUID) (bool, error)
+	ListFollowers(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]models.Follow, int, error)
+	ListFollowing(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]models.Follow, int, error)
+	GetFollowersCount(ctx context.Context, userID uuid.UUID) (int, error)
+	GetFollowerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	GetTimelinePosts(ctx context.Context, followerID uuid.UUID, celebrityFollowers int, celebrities bool, cursor *models.PostCursor, limit int) ([]models.Post, error)
+	GetPostsByIDs(ctx context.Context, postIDs []uuid.UUID) ([]models.Post, error)
+	DeleteFollowsByUser(ctx context.Context, userID uuid.UUID) error
+	AddReaction(ctx context.Context, postID, userID uuid.UUID, reaction string) (bool, error)
+	RemoveReaction(ctx context.Context, postID, userID uuid.UUID, reaction string) (bool, error)
+	GetReactionCounts(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]map[string]int, error)
+	GetUserReactions(ctx context.Context, userID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+	DeleteReactionsByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type TimelineCache interface {
+	Size() int
+	Page(ctx context.Context, userID uuid.UUID, cursor *models.PostCursor, limit int) ([]models.TimelineEntry, bool, error)
+	Store(ctx context.Context, userID uuid.UUID, entries []models.TimelineEntry) error
+	Add(ctx context.Context, followerIDs []uuid.UUID, entry models.TimelineEntry) error
+	Merge(ctx context.Context, userID uuid.UUID, entries []models.TimelineEntry) error
+	Invalidate(ctx context.Context, userID uuid.UUID) error
 }
 
-func NewFeedService(feedRepo *postgres.FeedRepositories, secretKey string) *FeedService {
+func NewFeedService(feedRepo *postgres.FeedRepositories, timelines *redis.TimelineCache, timelineSettings TimelineSettings, logger *zap.Logger) *FeedService {
+	fanOutCtx, cancelFanOut := context.WithCancel(context.Background())
+
 	return &FeedService{
-		repo:      feedRepo,
-		secretKey: secretKey,
+		repo:               feedRepo,
+		timelines:          timelines,
+		logger:             logger,
+		celebrityFollowers: timelineSettings.CelebrityFollowers,
+		fanOutWorkers:      timelineSettings.FanOutWorkers,
+		fanOuts:            make(chan models.Post, timelineSettings.FanOutQueueSize),
+		fanOutCtx:          fanOutCtx,
+		cancelFanOut:       cancelFanOut,
 	}
 }
 
 func (s *FeedService) CreatePost(ctx context.Context, content, imageURL string) (*models.Post, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
+	principal, ok := auth.PrincipalFromContext(ctx)
 	if !ok {
-		return nil, fmt.Errorf("metadata is missing")
+		return nil, domain.ErrUnauthenticated
 	}
 
-	userIDSTR := md.Get("user_id")[0]
-
-	userID, err := uuid.Parse(userIDSTR)
-	if err != nil {
-		return nil, fmt.Errorf("failed parse userid string")
+	if strings.TrimSpace(content) == "" && imageURL == "" {
+		return nil, domain.NewValidationError("content", "post must have content or an image")
 	}
 
+	// created_at has no time zone and keeps microseconds, so the post is kept in
+	// the same form Postgres returns it in, which cursors and timelines rely on.
 	post := &models.Post{
 		ID:        uuid.New(),
-		UserID:    userID,
+		UserID:    principal.UserID,
 		Content:   content,
 		ImageURL:  imageURL,
-		CreatedAt: time.Now(),
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
 	}
 
 	if err := s.repo.CreatePost(ctx, post); err != nil {
 		return nil, fmt.Errorf("failed to create post: %w", err)
 	}
 
+	s.queueFanOut(ctx, *post)
+
 	return post, nil
 }
 
+// GetAllPosts pages through the feed by offset and counts every post on each
+// call.
+//
+// Deprecated: use GetPosts, which neither slows down as the table grows nor
+// repeats posts when new ones arrive between pages.
 func (s *FeedService) GetAllPosts(ctx context.Context, page, pageSize int) ([]models.Post, int, error) {
+	if err := validatePage(page, pageSize); err != nil {
+		return nil, 0, err
+	}
+
 	posts, totalPosts, err := s.repo.GetALLPosts(ctx, page, pageSize)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get posts: %w", err)
 	}
 
+	if err := s.attachReactions(ctx, posts); err != nil {
+		return nil, 0, err
+	}
+
 	return posts, totalPosts, nil
 }
+
+func (s *FeedService) GetPost(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	post, err := s.repo.GetPost(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	posts := []models.Post{*post}
+	if err := s.attachReactions(ctx, posts); err != nil {
+		return nil, err
+	}
+
+	return &posts[0], nil
+}
+
+// UpdatePost changes the content and image of a post; nil leaves a field as it
+// is. Only the author and users allowed to edit any post may do it.
+func (s *FeedService) UpdatePost(ctx context.Context, postID uuid.UUID, content, imageURL *string) (*models.Post, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	if content == nil && imageURL == nil {
+		return nil, domain.NewValidationError("content", "nothing to update")
+	}
+
+	post, err := s.repo.GetPost(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	if err := authz.RequireOwnerOr(principal.Authz(), post.UserID, authz.PermissionEditAnyPost); err != nil {
+		return nil, err
+	}
+
+	if content != nil {
+		post.Content = *content
+	}
+	if imageURL != nil {
+		post.ImageURL = *imageURL
+	}
+
+	if strings.TrimSpace(post.Content) == "" && post.ImageURL == "" {
+		return nil, domain.NewValidationError("content", "post must have content or an image")
+	}
+
+	// Kept in the form Postgres returns it in, like created_at in CreatePost.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	post.UpdatedAt = &now
+
+	if err := s.repo.UpdatePost(ctx, post, principal.UserID); err != nil {
+		return nil, fmt.Errorf("failed to update post: %w", err)
+	}
+
+	posts := []models.Post{*post}
+	if err := s.attachReactions(ctx, posts); err != nil {
+		return nil, err
+	}
+
+	return &posts[0], nil
+}
+
+// DeletePost soft-deletes a post of the caller, or of anybody when the caller
+// may delete any post.
+func (s *FeedService) DeletePost(ctx context.Context, postID uuid.UUID) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	post, err := s.repo.GetPost(ctx, postID)
+	if err != nil {
+		return fmt.Errorf("failed to get post: %w", err)
+	}
+
+	if err := authz.RequireOwnerOr(principal.Authz(), post.UserID, authz.PermissionDeleteAnyPost); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeletePost(ctx, post.ID, principal.UserID); err != nil {
+		return fmt.Errorf("failed to delete post: %w", err)
+	}
+
+	return nil
+}
+
+// GetPosts returns the page of the feed that follows cursor, newest first, and
+// the cursor of the next page, which is empty on the last page. An empty cursor
+// starts at the newest post. With includeTotal it also estimates how many posts
+// there are.
+func (s *FeedService) GetPosts(ctx context.Context, cursor string, pageSize int, includeTotal bool) ([]models.Post, string, int, error) {
+	if pageSize < 1 || pageSize > maxPageSize {
+		return nil, "", 0, domain.NewValidationError("page_size", fmt.Sprintf("must be between 1 and %d", maxPageSize))
+	}
+
+	var after *models.PostCursor
+	if cursor != "" {
+		decoded, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", 0, domain.NewValidationError("cursor", "is invalid")
+		}
+		after = decoded
+	}
+
+	// One post more than asked tells whether there is a next page.
+	posts, err := s.repo.GetPostsPage(ctx, after, pageSize+1)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	var nextCursor string
+	if len(posts) > pageSize {
+		posts = posts[:pageSize]
+		last := posts[len(posts)-1]
+		nextCursor = encodeCursor(models.PostCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	var total int
+	if includeTotal {
+		total, err = s.repo.EstimatePostsCount(ctx)
+		if err != nil {
+			return nil, "", 0, fmt.Errorf("failed to estimate posts count: %w", err)
+		}
+	}
+
+	if err := s.attachReactions(ctx, posts); err != nil {
+		return nil, "", 0, err
+	}
+
+	return posts, nextCursor, total, nil
+}
+
+// encodeCursor turns the position into an opaque token for clients.
+func encodeCursor(cursor models.PostCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixMicro(), 10) + ":" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(token string) (*models.PostCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	micros, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	postID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// created_at has no time zone and pgx reads it as UTC, so the cursor has to
+	// be compared in UTC as well.
+	return &models.PostCursor{CreatedAt: time.UnixMicro(createdAt).UTC(), ID: postID}, nil
+}
+
+// ExportMyPosts returns every post of the caller for the data export. Posts
+// the caller deleted are still stored, so they follow the visible ones with
+// DeletedAt set.
+func (s *FeedService) ExportMyPosts(ctx context.Context) ([]models.Post, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	posts, err := s.repo.GetPostsByUser(ctx, principal.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	deleted, err := s.repo.GetDeletedPostsByUser(ctx, principal.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted posts: %w", err)
+	}
+
+	return append(posts, deleted...), nil
+}
+
+// DeleteUserPosts removes the posts of a deleted account.
+func (s *FeedService) DeleteUserPosts(ctx context.Context, userID uuid.UUID) (int64, error) {
+	deleted, err := s.repo.DeletePostsByUser(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete posts: %w", err)
+	}
+
+	return deleted, nil
+}
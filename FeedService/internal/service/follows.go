@@ -0,0 +1,119 @@
+package service
+
+import (
+	"Project/FeedService/internal/auth"
+	"Project/FeedService/internal/domain"
+	"Project/FeedService/internal/domain/models"
+
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Follow makes the caller follow the user. Following somebody twice is not an
+// error.
+func (s *FeedService) Follow(ctx context.Context, userID uuid.UUID) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if userID == principal.UserID {
+		return domain.NewValidationError("user_id", "users cannot follow themselves")
+	}
+
+	followed, err := s.repo.Follow(ctx, principal.UserID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to follow user: %w", err)
+	}
+
+	if followed {
+		s.invalidateTimeline(ctx, principal.UserID)
+	}
+
+	return nil
+}
+
+// Unfollow stops the caller following the user. Unfollowing somebody the caller
+// does not follow is not an error.
+func (s *FeedService) Unfollow(ctx context.Context, userID uuid.UUID) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	unfollowed, err := s.repo.Unfollow(ctx, principal.UserID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+
+	if unfollowed {
+		s.invalidateTimeline(ctx, principal.UserID)
+	}
+
+	return nil
+}
+
+// invalidateTimeline drops the cached timeline of a user whose follows changed
+// so the next read rebuilds it from the new follows.
+func (s *FeedService) invalidateTimeline(ctx context.Context, userID uuid.UUID) {
+	if err := s.timelines.Invalidate(ctx, userID); err != nil {
+		s.logger.Error("Failed to invalidate timeline", zap.String("userID", userID.String()), zap.Error(err))
+	}
+}
+
+func (s *FeedService) ListFollowers(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]models.Follow, int, error) {
+	if err := validatePage(page, pageSize); err != nil {
+		return nil, 0, err
+	}
+
+	followers, total, err := s.repo.ListFollowers(ctx, userID, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list followers: %w", err)
+	}
+
+	return followers, total, nil
+}
+
+func (s *FeedService) ListFollowing(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]models.Follow, int, error) {
+	if err := validatePage(page, pageSize); err != nil {
+		return nil, 0, err
+	}
+
+	following, total, err := s.repo.ListFollowing(ctx, userID, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list following: %w", err)
+	}
+
+	return following, total, nil
+}
+
+// DeleteUserFollows removes a deleted account from the follow graph.
+func (s *FeedService) DeleteUserFollows(ctx context.Context, userID uuid.UUID) error {
+	if err := s.repo.DeleteFollowsByUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete follows: %w", err)
+	}
+
+	if err := s.timelines.Invalidate(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete timeline: %w", err)
+	}
+
+	return nil
+}
+
+func validatePage(page, pageSize int) error {
+	validationErr := &domain.ValidationError{}
+	if page < 1 {
+		validationErr.Add("page", "must be at least 1")
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		validationErr.Add("page_size", fmt.Sprintf("must be between 1 and %d", maxPageSize))
+	}
+	if len(validationErr.Violations) > 0 {
+		return validationErr
+	}
+
+	return nil
+}
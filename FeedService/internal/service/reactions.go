@@ -0,0 +1,102 @@
+package service
+
+import (
+	"Project/FeedService/internal/auth"
+	"Project/FeedService/internal/domain"
+	"Project/FeedService/internal/domain/models"
+
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// AddReaction adds the reaction of the caller to a post and returns the post
+// with its new counts. Reacting the same way twice is not an error.
+func (s *FeedService) AddReaction(ctx context.Context, postID uuid.UUID, reaction string) (*models.Post, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	if err := validateReaction(reaction); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.AddReaction(ctx, postID, principal.UserID, reaction); err != nil {
+		return nil, fmt.Errorf("failed to add reaction: %w", err)
+	}
+
+	return s.GetPost(ctx, postID)
+}
+
+// RemoveReaction takes back the reaction of the caller to a post and returns the
+// post with its new counts.
+func (s *FeedService) RemoveReaction(ctx context.Context, postID uuid.UUID, reaction string) (*models.Post, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	if err := validateReaction(reaction); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.RemoveReaction(ctx, postID, principal.UserID, reaction); err != nil {
+		return nil, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	return s.GetPost(ctx, postID)
+}
+
+func validateReaction(reaction string) error {
+	if !slices.Contains(models.Reactions, reaction) {
+		return domain.NewValidationError("reaction", "must be one of "+strings.Join(models.Reactions, ", "))
+	}
+
+	return nil
+}
+
+// attachReactions fills in the reaction counts of the posts and, when the
+// caller is authenticated, their own reactions.
+func (s *FeedService) attachReactions(ctx context.Context, posts []models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+
+	counts, err := s.repo.GetReactionCounts(ctx, postIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get reaction counts: %w", err)
+	}
+
+	var mine map[uuid.UUID][]string
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		mine, err = s.repo.GetUserReactions(ctx, principal.UserID, postIDs)
+		if err != nil {
+			return fmt.Errorf("failed to get reactions: %w", err)
+		}
+	}
+
+	for i := range posts {
+		posts[i].ReactionCounts = counts[posts[i].ID]
+		posts[i].MyReactions = mine[posts[i].ID]
+	}
+
+	return nil
+}
+
+// DeleteUserReactions removes the reactions of a deleted account.
+func (s *FeedService) DeleteUserReactions(ctx context.Context, userID uuid.UUID) error {
+	if err := s.repo.DeleteReactionsByUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete reactions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"Project/FeedService/internal/auth"
+	"Project/FeedService/internal/domain"
+	"Project/FeedService/internal/domain/models"
+
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const fanOutTimeout = 30 * time.Second
+
+// GetHomeTimeline returns the page of the posts of the users the caller follows
+// that follows cursor, newest first, and the cursor of the next page.
+//
+// Posts of regular users are pushed into the cached timelines of their active
+// followers when they are created, see fanOut. Posts of celebrities would have
+// to be pushed into too many timelines, so they are read from Postgres and
+// merged in here instead.
+func (s *FeedService) GetHomeTimeline(ctx context.Context, cursor string, pageSize int) ([]models.Post, string, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, "", domain.ErrUnauthenticated
+	}
+
+	if pageSize < 1 || pageSize > maxPageSize {
+		return nil, "", domain.NewValidationError("page_size", fmt.Sprintf("must be between 1 and %d", maxPageSize))
+	}
+
+	var after *models.PostCursor
+	if cursor != "" {
+		decoded, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", domain.NewValidationError("cursor", "is invalid")
+		}
+		after = decoded
+	}
+
+	// One post more than asked tells whether there is a next page.
+	limit := pageSize + 1
+	loaded := make(map[uuid.UUID]models.Post)
+
+	entries, err := s.regularTimelineEntries(ctx, principal.UserID, after, limit, loaded)
+	if err != nil {
+		return nil, "", err
+	}
+
+	celebrityPosts, err := s.repo.GetTimelinePosts(ctx, principal.UserID, s.celebrityFollowers, true, after, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get posts of celebrities: %w", err)
+	}
+	entries = append(entries, timelineEntries(celebrityPosts, loaded)...)
+
+	slices.SortFunc(entries, compareTimelineEntries)
+	entries = slices.CompactFunc(entries, func(a, b models.TimelineEntry) bool { return a.PostID == b.PostID })
+
+	var nextCursor string
+	if len(entries) > pageSize {
+		entries = entries[:pageSize]
+		last := entries[len(entries)-1]
+		nextCursor = encodeCursor(models.PostCursor{CreatedAt: last.CreatedAt, ID: last.PostID})
+	}
+
+	posts, err := s.loadTimelinePosts(ctx, entries, loaded)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.attachReactions(ctx, posts); err != nil {
+		return nil, "", err
+	}
+
+	return posts, nextCursor, nil
+}
+
+// regularTimelineEntries returns the page of the timeline without the posts of
+// celebrities. It comes from the cache when the cache holds it, otherwise from
+// Postgres; a first page that is not cached rebuilds the cache. Posts read from
+// Postgres are put into loaded.
+func (s *FeedService) regularTimelineEntries(ctx context.Context, userID uuid.UUID, after *models.PostCursor, limit int, loaded map[uuid.UUID]models.Post) ([]models.TimelineEntry, error) {
+	entries, covered, err := s.timelines.Page(ctx, userID, after, limit)
+	if err != nil {
+		// The timeline still works without the cache, only slower.
+		s.logger.Error("Failed to read cached timeline", zap.String("userID", userID.String()), zap.Error(err))
+	} else if covered {
+		return entries, nil
+	}
+
+	if err == nil && after == nil {
+		posts, err := s.repo.GetTimelinePosts(ctx, userID, s.celebrityFollowers, false, nil, s.timelines.Size())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get timeline posts: %w", err)
+		}
+
+		entries := timelineEntries(posts, loaded)
+		if err := s.storeTimeline(ctx, userID, entries); err != nil {
+			s.logger.Error("Failed to cache timeline", zap.String("userID", userID.String()), zap.Error(err))
+		}
+
+		return entries[:min(limit, len(entries))], nil
+	}
+
+	posts, err := s.repo.GetTimelinePosts(ctx, userID, s.celebrityFollowers, false, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timeline posts: %w", err)
+	}
+
+	return timelineEntries(posts, loaded), nil
+}
+
+// storeTimeline caches a timeline read from Postgres. Posts created while it
+// was read were fanned out before it was cached and skipped, so it is read
+// again once cached and the posts missing from it are merged in. Fan-outs from
+// then on find it.
+func (s *FeedService) storeTimeline(ctx context.Context, userID uuid.UUID, entries []models.TimelineEntry) error {
+	if err := s.timelines.Store(ctx, userID, entries); err != nil {
+		return err
+	}
+
+	// An empty timeline is not cached, so nothing can be missing from it.
+	if len(entries) == 0 {
+		return nil
+	}
+
+	posts, err := s.repo.GetTimelinePosts(ctx, userID, s.celebrityFollowers, false, nil, s.timelines.Size())
+	if err != nil {
+		return fmt.Errorf("failed to get timeline posts: %w", err)
+	}
+
+	stored := make(map[uuid.UUID]bool, len(entries))
+	for _, entry := range entries {
+		stored[entry.PostID] = true
+	}
+
+	var missing []models.TimelineEntry
+	for _, post := range posts {
+		if !stored[post.ID] {
+			missing = append(missing, models.TimelineEntry{PostID: post.ID, CreatedAt: post.CreatedAt})
+		}
+	}
+
+	return s.timelines.Merge(ctx, userID, missing)
+}
+
+// loadTimelinePosts returns the posts of the entries in their order. Posts that
+// were deleted since they were cached are left out.
+func (s *FeedService) loadTimelinePosts(ctx context.Context, entries []models.TimelineEntry, loaded map[uuid.UUID]models.Post) ([]models.Post, error) {
+	var missing []uuid.UUID
+	for _, entry := range entries {
+		if _, ok := loaded[entry.PostID]; !ok {
+			missing = append(missing, entry.PostID)
+		}
+	}
+
+	if len(missing) > 0 {
+		posts, err := s.repo.GetPostsByIDs(ctx, missing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get timeline posts: %w", err)
+		}
+		for _, post := range posts {
+			loaded[post.ID] = post
+		}
+	}
+
+	posts := make([]models.Post, 0, len(entries))
+	for _, entry := range entries {
+		if post, ok := loaded[entry.PostID]; ok {
+			posts = append(posts, post)
+		}
+	}
+
+	return posts, nil
+}
+
+func timelineEntries(posts []models.Post, loaded map[uuid.UUID]models.Post) []models.TimelineEntry {
+	entries := make([]models.TimelineEntry, 0, len(posts))
+	for _, post := range posts {
+		loaded[post.ID] = post
+		entries = append(entries, models.TimelineEntry{PostID: post.ID, CreatedAt: post.CreatedAt})
+	}
+
+	return entries
+}
+
+// compareTimelineEntries orders entries newest first, and by descending ID when
+// they were created at the same time, like the feed queries do.
+func compareTimelineEntries(a, b models.TimelineEntry) int {
+	if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+		return c
+	}
+
+	return slices.Compare(b.PostID[:], a.PostID[:])
+}
+
+// StartFanOut starts the workers that push new posts into timelines.
+func (s *FeedService) StartFanOut() {
+	for range s.fanOutWorkers {
+		s.fanOutDone.Add(1)
+		go func() {
+			defer s.fanOutDone.Done()
+			for post := range s.fanOuts {
+				s.fanOut(s.fanOutCtx, post)
+			}
+		}()
+	}
+}
+
+// StopFanOut waits until the queued posts are pushed into timelines and stops
+// the workers. Once ctx is done, the remaining posts are given up. It must be
+// called after the gRPC server stopped, so no more posts are created.
+func (s *FeedService) StopFanOut(ctx context.Context) {
+	defer s.cancelFanOut()
+
+	close(s.fanOuts)
+
+	done := make(chan struct{})
+	go func() {
+		s.fanOutDone.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logger.Error("Gave up fanning out posts", zap.Int("queued", len(s.fanOuts)))
+		s.cancelFanOut()
+		<-done
+	}
+}
+
+// queueFanOut waits for room in the fan-out queue, so a burst of posts slows
+// down CreatePost instead of piling up work.
+func (s *FeedService) queueFanOut(ctx context.Context, post models.Post) {
+	select {
+	case s.fanOuts <- post:
+	case <-ctx.Done():
+		s.logger.Error("Failed to queue post for fan-out", zap.String("postID", post.ID.String()), zap.Error(ctx.Err()))
+	}
+}
+
+// fanOut pushes a new post into the cached timelines of the followers of its
+// author unless the author is a celebrity. It runs after the post was created,
+// so failures are only logged; the timelines catch up when they are rebuilt.
+func (s *FeedService) fanOut(ctx context.Context, post models.Post) {
+	ctx, cancel := context.WithTimeout(ctx, fanOutTimeout)
+	defer cancel()
+
+	followers, err := s.repo.GetFollowersCount(ctx, post.UserID)
+	if err != nil {
+		s.logger.Error("Failed to count followers for fan-out", zap.String("postID", post.ID.String()), zap.Error(err))
+		return
+	}
+
+	if followers >= s.celebrityFollowers {
+		return
+	}
+
+	followerIDs, err := s.repo.GetFollowerIDs(ctx, post.UserID)
+	if err != nil {
+		s.logger.Error("Failed to get followers for fan-out", zap.String("postID", post.ID.String()), zap.Error(err))
+		return
+	}
+
+	entry := models.TimelineEntry{PostID: post.ID, CreatedAt: post.CreatedAt}
+	if err := s.timelines.Add(ctx, followerIDs, entry); err != nil {
+		s.logger.Error("Failed to fan out post", zap.String("postID", post.ID.String()), zap.Error(err))
+	}
+}
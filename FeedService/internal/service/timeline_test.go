@@ -0,0 +1,168 @@
+package service
+
+import (
+	"Project/FeedService/internal/domain/models"
+
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestCompareTimelineEntries(t *testing.T) {
+	now := time.Date(2025, 5, 16, 10, 0, 0, 0, time.UTC)
+	low := uuid.MustParse("00000000-0000-4000-8000-000000000001")
+	high := uuid.MustParse("ffffffff-0000-4000-8000-000000000001")
+
+	tests := []struct {
+		name string
+		a, b models.TimelineEntry
+		want int
+	}{
+		{
+			name: "newer post comes first",
+			a:    models.TimelineEntry{PostID: low, CreatedAt: now.Add(time.Microsecond)},
+			b:    models.TimelineEntry{PostID: high, CreatedAt: now},
+			want: -1,
+		},
+		{
+			name: "older post comes last",
+			a:    models.TimelineEntry{PostID: high, CreatedAt: now},
+			b:    models.TimelineEntry{PostID: low, CreatedAt: now.Add(time.Microsecond)},
+			want: 1,
+		},
+		{
+			name: "same time orders by descending id",
+			a:    models.TimelineEntry{PostID: high, CreatedAt: now},
+			b:    models.TimelineEntry{PostID: low, CreatedAt: now},
+			want: -1,
+		},
+		{
+			name: "same time in another zone is still a tie on time",
+			a:    models.TimelineEntry{PostID: low, CreatedAt: now.In(time.FixedZone("MSK", 3*60*60))},
+			b:    models.TimelineEntry{PostID: high, CreatedAt: now},
+			want: 1,
+		},
+		{
+			name: "same entry",
+			a:    models.TimelineEntry{PostID: low, CreatedAt: now},
+			b:    models.TimelineEntry{PostID: low, CreatedAt: now},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareTimelineEntries(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareTimelineEntries() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func timelineEntryOf(post models.Post) models.TimelineEntry {
+	return models.TimelineEntry{PostID: post.ID, CreatedAt: post.CreatedAt}
+}
+
+// fakeTimelineCache records what the service writes to the cache.
+type fakeTimelineCache struct {
+	TimelineCache
+
+	stored map[uuid.UUID][]models.TimelineEntry
+	merged map[uuid.UUID][]models.TimelineEntry
+}
+
+func newFakeTimelineCache() *fakeTimelineCache {
+	return &fakeTimelineCache{
+		stored: make(map[uuid.UUID][]models.TimelineEntry),
+		merged: make(map[uuid.UUID][]models.TimelineEntry),
+	}
+}
+
+func (f *fakeTimelineCache) Size() int {
+	return 100
+}
+
+func (f *fakeTimelineCache) Store(_ context.Context, userID uuid.UUID, entries []models.TimelineEntry) error {
+	f.stored[userID] = slices.Clone(entries)
+	return nil
+}
+
+func (f *fakeTimelineCache) Merge(_ context.Context, userID uuid.UUID, entries []models.TimelineEntry) error {
+	f.merged[userID] = append(f.merged[userID], entries...)
+	return nil
+}
+
+// fakeTimelineRepo returns the timeline posts one call after another, so a
+// post can be published between the rebuild and the re-check.
+type fakeTimelineRepo struct {
+	FeedRepositories
+
+	timelines [][]models.Post
+	calls     int
+}
+
+func (f *fakeTimelineRepo) GetTimelinePosts(_ context.Context, _ uuid.UUID, _ int, _ bool, _ *models.PostCursor, _ int) ([]models.Post, error) {
+	posts := f.timelines[min(f.calls, len(f.timelines)-1)]
+	f.calls++
+	return posts, nil
+}
+
+func TestStoreTimelineMergesPostsPublishedDuringRebuild(t *testing.T) {
+	now := time.Date(2025, 5, 16, 10, 0, 0, 0, time.UTC)
+	older := models.Post{ID: uuid.New(), CreatedAt: now.Add(-time.Minute)}
+	newer := models.Post{ID: uuid.New(), CreatedAt: now}
+	racing := models.Post{ID: uuid.New(), CreatedAt: now.Add(time.Second)}
+
+	tests := []struct {
+		name       string
+		entries    []models.TimelineEntry
+		recheck    []models.Post
+		wantMerged []models.TimelineEntry
+		wantChecks int
+	}{
+		{
+			name:       "nothing published meanwhile",
+			entries:    []models.TimelineEntry{timelineEntryOf(newer), timelineEntryOf(older)},
+			recheck:    []models.Post{newer, older},
+			wantChecks: 1,
+		},
+		{
+			name:       "post published meanwhile is merged",
+			entries:    []models.TimelineEntry{timelineEntryOf(newer), timelineEntryOf(older)},
+			recheck:    []models.Post{racing, newer, older},
+			wantMerged: []models.TimelineEntry{timelineEntryOf(racing)},
+			wantChecks: 1,
+		},
+		{
+			name:    "empty timeline is not checked again",
+			recheck: []models.Post{racing},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			repo := &fakeTimelineRepo{timelines: [][]models.Post{tt.recheck}}
+			timelines := newFakeTimelineCache()
+			s := &FeedService{repo: repo, timelines: timelines, logger: zap.NewNop()}
+
+			if err := s.storeTimeline(context.Background(), userID, tt.entries); err != nil {
+				t.Fatalf("storeTimeline() error = %v", err)
+			}
+
+			if !slices.Equal(timelines.stored[userID], tt.entries) {
+				t.Errorf("stored = %v, want %v", timelines.stored[userID], tt.entries)
+			}
+			if !slices.Equal(timelines.merged[userID], tt.wantMerged) {
+				t.Errorf("merged = %v, want %v", timelines.merged[userID], tt.wantMerged)
+			}
+			if repo.calls != tt.wantChecks {
+				t.Errorf("timeline checked %d times, want %d", repo.calls, tt.wantChecks)
+			}
+		})
+	}
+}
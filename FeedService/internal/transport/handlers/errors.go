@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+
+	"Project/FeedService/internal/domain"
+	"Project/pkg/authz"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus converts a service error into a gRPC status. Unexpected errors become
+// codes.Internal without details so internals do not leak to clients.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationStatus(validationErr)
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, authz.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrPostNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+func validationStatus(err *domain.ValidationError) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range err.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+
+	st, detailsErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return st.Err()
+}
@@ -1,27 +1,28 @@
 package handlers
 
 import (
+	"Project/FeedService/internal/domain"
+	"Project/FeedService/internal/domain/models"
 	"Project/FeedService/internal/service"
 	"Project/proto/gen"
 	"context"
-	"fmt"
+	"slices"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type FeedHandlers struct {
-	service   *service.FeedService
-	logger    *zap.Logger
-	secretKey string
+	service *service.FeedService
+	logger  *zap.Logger
 	gen.UnimplementedFeedServiceServer
 }
 
-func NewFeedHandlers(service *service.FeedService, logger *zap.Logger, secretKey string) *FeedHandlers {
+func NewFeedHandlers(service *service.FeedService, logger *zap.Logger) *FeedHandlers {
 	return &FeedHandlers{
-		service:   service,
-		logger:    logger,
-		secretKey: secretKey,
+		service: service,
+		logger:  logger,
 	}
 }
 
@@ -31,39 +32,142 @@ func (h *FeedHandlers) CreatePost(ctx context.Context, req *gen.CreatePostReques
 	post, err := h.service.CreatePost(ctx, req.Content, req.ImageUrl)
 	if err != nil {
 		h.logger.Error("Failed to create post", zap.Error(err))
-		return nil, fmt.Errorf("failed to create post: %w", err)
+		return nil, toStatus(err)
 	}
 
 	h.logger.Info("Create Post Successful", zap.String("Content", req.Content))
 	return &gen.CreatePostResponse{
-		Post: &gen.Post{
-			Content:   post.Content,
-			ImageUrl:  post.ImageURL,
-			CreatedAt: post.CreatedAt.Format(time.RFC3339),
-		},
+		Post: mapPost(post),
 	}, nil
 
 }
 
 func (h *FeedHandlers) GetAllPosts(ctx context.Context, req *gen.GetAllPostsRequest) (*gen.GetAllPostsResponse, error) {
-	posts, totalPosts, err := h.service.GetAllPosts(ctx, int(req.Page), int(req.PageSize))
+	if req.Cursor == "" && req.Page > 0 {
+		return h.getAllPostsByPage(ctx, req)
+	}
+
+	posts, nextCursor, totalPosts, err := h.service.GetPosts(ctx, req.Cursor, int(req.PageSize), req.IncludeTotal)
 	if err != nil {
 		h.logger.Error("Failed to get posts", zap.Error(err))
-		return nil, fmt.Errorf("failed to get posts: %w", err)
+		return nil, toStatus(err)
 	}
 
-	var responsePosts []*gen.Post
-	for _, post := range posts {
-		responsePosts = append(responsePosts, &gen.Post{
+	return &gen.GetAllPostsResponse{
+		Posts:      mapPosts(posts),
+		TotalPosts: int32(totalPosts),
+		NextCursor: nextCursor,
+	}, nil
+}
 
-			Content:   post.Content,
-			ImageUrl:  post.ImageURL,
-			CreatedAt: post.CreatedAt.Format(time.RFC3339),
-		})
+// getAllPostsByPage serves clients that still page by offset.
+func (h *FeedHandlers) getAllPostsByPage(ctx context.Context, req *gen.GetAllPostsRequest) (*gen.GetAllPostsResponse, error) {
+	posts, totalPosts, err := h.service.GetAllPosts(ctx, int(req.Page), int(req.PageSize))
+	if err != nil {
+		h.logger.Error("Failed to get posts", zap.Error(err))
+		return nil, toStatus(err)
 	}
 
 	return &gen.GetAllPostsResponse{
-		Posts:      responsePosts,
+		Posts:      mapPosts(posts),
 		TotalPosts: int32(totalPosts),
 	}, nil
 }
+
+func (h *FeedHandlers) ExportMyPosts(ctx context.Context, req *gen.ExportMyPostsRequest) (*gen.ExportMyPostsResponse, error) {
+	posts, err := h.service.ExportMyPosts(ctx)
+	if err != nil {
+		h.logger.Error("Failed to export posts", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	response := &gen.ExportMyPostsResponse{
+		Posts: make([]*gen.Post, 0, len(posts)),
+	}
+	for _, post := range posts {
+		response.Posts = append(response.Posts, mapPost(&post))
+	}
+
+	return response, nil
+}
+
+func (h *FeedHandlers) GetPost(ctx context.Context, req *gen.GetPostRequest) (*gen.GetPostResponse, error) {
+	postID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, toStatus(domain.NewValidationError("id", "must be a valid UUID"))
+	}
+
+	post, err := h.service.GetPost(ctx, postID)
+	if err != nil {
+		h.logger.Error("Failed to get post", zap.String("postID", req.Id), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.GetPostResponse{Post: mapPost(post)}, nil
+}
+
+func (h *FeedHandlers) UpdatePost(ctx context.Context, req *gen.UpdatePostRequest) (*gen.UpdatePostResponse, error) {
+	postID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, toStatus(domain.NewValidationError("id", "must be a valid UUID"))
+	}
+
+	post, err := h.service.UpdatePost(ctx, postID, req.Content, req.ImageUrl)
+	if err != nil {
+		h.logger.Error("Failed to update post", zap.String("postID", req.Id), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	h.logger.Info("Post updated", zap.String("postID", req.Id))
+	return &gen.UpdatePostResponse{Post: mapPost(post)}, nil
+}
+
+func (h *FeedHandlers) DeletePost(ctx context.Context, req *gen.DeletePostRequest) (*gen.DeletePostResponse, error) {
+	postID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, toStatus(domain.NewValidationError("id", "must be a valid UUID"))
+	}
+
+	if err := h.service.DeletePost(ctx, postID); err != nil {
+		h.logger.Error("Failed to delete post", zap.String("postID", req.Id), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	h.logger.Info("Post deleted", zap.String("postID", req.Id))
+	return &gen.DeletePostResponse{Success: true}, nil
+}
+
+func mapPosts(posts []models.Post) []*gen.Post {
+	response := make([]*gen.Post, 0, len(posts))
+	for _, post := range posts {
+		response = append(response, mapPost(&post))
+	}
+
+	return response
+}
+
+func mapPost(post *models.Post) *gen.Post {
+	response := &gen.Post{
+		Id:        post.ID.String(),
+		UserId:    post.UserID.String(),
+		Content:   post.Content,
+		ImageUrl:  post.ImageURL,
+		CreatedAt: post.CreatedAt.Format(time.RFC3339),
+	}
+	if post.UpdatedAt != nil {
+		response.UpdatedAt = post.UpdatedAt.Format(time.RFC3339)
+	}
+	if post.DeletedAt != nil {
+		response.DeletedAt = post.DeletedAt.Format(time.RFC3339)
+	}
+	if len(post.ReactionCounts) > 0 {
+		response.ReactionCounts = make(map[string]int32, len(post.ReactionCounts))
+		for reaction, count := range post.ReactionCounts {
+			response.ReactionCounts[reaction] = int32(count)
+		}
+	}
+	response.MyReactions = post.MyReactions
+	response.LikedByMe = slices.Contains(post.MyReactions, models.ReactionLike)
+
+	return response
+}
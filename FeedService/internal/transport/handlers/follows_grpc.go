@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"Project/FeedService/internal/domain"
+	"Project/FeedService/internal/domain/models"
+	"Project/proto/gen"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *FeedHandlers) Follow(ctx context.Context, req *gen.FollowRequest) (*gen.FollowResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, toStatus(domain.NewValidationError("user_id", "must be a valid UUID"))
+	}
+
+	if err := h.service.Follow(ctx, userID); err != nil {
+		h.logger.Error("Failed to follow user", zap.String("userID", req.UserId), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.FollowResponse{Success: true}, nil
+}
+
+func (h *FeedHandlers) Unfollow(ctx context.Context, req *gen.UnfollowRequest) (*gen.UnfollowResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, toStatus(domain.NewValidationError("user_id", "must be a valid UUID"))
+	}
+
+	if err := h.service.Unfollow(ctx, userID); err != nil {
+		h.logger.Error("Failed to unfollow user", zap.String("userID", req.UserId), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.UnfollowResponse{Success: true}, nil
+}
+
+func (h *FeedHandlers) ListFollowers(ctx context.Context, req *gen.ListFollowsRequest) (*gen.ListFollowsResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, toStatus(domain.NewValidationError("user_id", "must be a valid UUID"))
+	}
+
+	followers, total, err := h.service.ListFollowers(ctx, userID, int(req.Page), int(req.PageSize))
+	if err != nil {
+		h.logger.Error("Failed to list followers", zap.String("userID", req.UserId), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return mapFollowsToResponse(followers, total), nil
+}
+
+func (h *FeedHandlers) ListFollowing(ctx context.Context, req *gen.ListFollowsRequest) (*gen.ListFollowsResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, toStatus(domain.NewValidationError("user_id", "must be a valid UUID"))
+	}
+
+	following, total, err := h.service.ListFollowing(ctx, userID, int(req.Page), int(req.PageSize))
+	if err != nil {
+		h.logger.Error("Failed to list following", zap.String("userID", req.UserId), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return mapFollowsToResponse(following, total), nil
+}
+
+func (h *FeedHandlers) GetHomeTimeline(ctx context.Context, req *gen.GetHomeTimelineRequest) (*gen.GetHomeTimelineResponse, error) {
+	posts, nextCursor, err := h.service.GetHomeTimeline(ctx, req.Cursor, int(req.PageSize))
+	if err != nil {
+		h.logger.Error("Failed to get home timeline", zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.GetHomeTimelineResponse{
+		Posts:      mapPosts(posts),
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func mapFollowsToResponse(follows []models.Follow, total int) *gen.ListFollowsResponse {
+	response := &gen.ListFollowsResponse{
+		Follows: make([]*gen.Follow, 0, len(follows)),
+		Total:   int32(total),
+	}
+	for _, follow := range follows {
+		response.Follows = append(response.Follows, &gen.Follow{
+			UserId:     follow.UserID.String(),
+			FollowedAt: follow.FollowedAt.Format(time.RFC3339),
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"Project/FeedService/internal/domain"
+	"Project/FeedService/internal/domain/models"
+	"Project/proto/gen"
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *FeedHandlers) LikePost(ctx context.Context, req *gen.LikePostRequest) (*gen.ReactionResponse, error) {
+	return h.addReaction(ctx, req.PostId, models.ReactionLike)
+}
+
+func (h *FeedHandlers) UnlikePost(ctx context.Context, req *gen.LikePostRequest) (*gen.ReactionResponse, error) {
+	return h.removeReaction(ctx, req.PostId, models.ReactionLike)
+}
+
+func (h *FeedHandlers) AddReaction(ctx context.Context, req *gen.ReactionRequest) (*gen.ReactionResponse, error) {
+	return h.addReaction(ctx, req.PostId, req.Reaction)
+}
+
+func (h *FeedHandlers) RemoveReaction(ctx context.Context, req *gen.ReactionRequest) (*gen.ReactionResponse, error) {
+	return h.removeReaction(ctx, req.PostId, req.Reaction)
+}
+
+func (h *FeedHandlers) addReaction(ctx context.Context, id, reaction string) (*gen.ReactionResponse, error) {
+	postID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, toStatus(domain.NewValidationError("post_id", "must be a valid UUID"))
+	}
+
+	post, err := h.service.AddReaction(ctx, postID, reaction)
+	if err != nil {
+		h.logger.Error("Failed to add reaction", zap.String("postID", id), zap.String("reaction", reaction), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.ReactionResponse{Post: mapPost(post)}, nil
+}
+
+func (h *FeedHandlers) removeReaction(ctx context.Context, id, reaction string) (*gen.ReactionResponse, error) {
+	postID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, toStatus(domain.NewValidationError("post_id", "must be a valid UUID"))
+	}
+
+	post, err := h.service.RemoveReaction(ctx, postID, reaction)
+	if err != nil {
+		h.logger.Error("Failed to remove reaction", zap.String("postID", id), zap.String("reaction", reaction), zap.Error(err))
+		return nil, toStatus(err)
+	}
+
+	return &gen.ReactionResponse{Post: mapPost(post)}, nil
+}
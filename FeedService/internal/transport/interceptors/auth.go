@@ -0,0 +1,173 @@
+package interceptors
+
+import (
+	"context"
+	"slices"
+
+	"Project/FeedService/internal/auth"
+	"Project/FeedService/pkg/jwt"
+	"Project/pkg/revocation"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthInterceptor verifies the bearer token of every call locally against the
+// AuthService public keys and puts the caller into the context as an
+// auth.Principal. Revoked tokens are rejected using the local copy of the
+// revocation list. Public methods are let through without a token, but a token
+// that is sent is verified so they can tailor the response to the caller.
+// Permissions are checked by the service methods, as most depend on who owns
+// the post.
+//
+// Access tokens of OAuth clients are accepted only for the methods in
+// oauthScopes and only when they were granted the scope named there. Tokens of
+// the client credentials grant act for no user and are limited to public
+// methods.
+type AuthInterceptor struct {
+	keySet        *jwt.KeySet
+	revoked       *revocation.Cache
+	issuer        string
+	audience      string
+	oauthAudience string
+	publicMethods map[string]bool
+	oauthScopes   map[string]string
+	logger        *zap.Logger
+}
+
+func NewAuthInterceptor(
+	keySet *jwt.KeySet,
+	revoked *revocation.Cache,
+	issuer string,
+	audience string,
+	oauthAudience string,
+	publicMethods []string,
+	oauthScopes map[string]string,
+	logger *zap.Logger,
+) *AuthInterceptor {
+	public := make(map[string]bool, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = true
+	}
+
+	return &AuthInterceptor{
+		keySet:        keySet,
+		revoked:       revoked,
+		issuer:        issuer,
+		audience:      audience,
+		oauthAudience: oauthAudience,
+		publicMethods: public,
+		oauthScopes:   oauthScopes,
+		logger:        logger,
+	}
+}
+
+func (i *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (i *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func (i *AuthInterceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if i.publicMethods[method] && !hasAuthorization(ctx) {
+		return ctx, nil
+	}
+
+	token, err := jwt.ExtractTokenFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if jwt.IsOAuthToken(token) {
+		return i.authenticateOAuthClient(ctx, method, token)
+	}
+
+	claims, err := jwt.ParseToken(ctx, token, i.keySet, i.issuer, i.audience)
+	if err != nil {
+		i.logger.Warn("Rejected access token", zap.String("method", method), zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "invalid access token")
+	}
+
+	if i.revoked.IsRevoked(claims.ID) {
+		i.logger.Warn("Rejected revoked access token", zap.String("method", method), zap.String("jti", claims.ID))
+		return nil, status.Error(codes.Unauthenticated, "access token has been revoked")
+	}
+
+	principal := &auth.Principal{
+		UserID:      claims.UserID,
+		SessionID:   claims.SessionID,
+		Email:       claims.Email,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+	}
+
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+func (i *AuthInterceptor) authenticateOAuthClient(ctx context.Context, method, token string) (context.Context, error) {
+	claims, err := jwt.ParseOAuthToken(ctx, token, i.keySet, i.issuer, i.oauthAudience)
+	if err != nil {
+		i.logger.Warn("Rejected OAuth access token", zap.String("method", method), zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "invalid access token")
+	}
+
+	if i.revoked.IsRevoked(claims.ID) {
+		i.logger.Warn("Rejected revoked OAuth access token", zap.String("method", method), zap.String("jti", claims.ID))
+		return nil, status.Error(codes.Unauthenticated, "access token has been revoked")
+	}
+
+	scope, ok := i.oauthScopes[method]
+	if !ok {
+		return nil, status.Error(codes.PermissionDenied, "method is not available to OAuth clients")
+	}
+
+	if !slices.Contains(claims.Scopes, scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "access token lacks the %q scope", scope)
+	}
+
+	if claims.UserID == uuid.Nil && !i.publicMethods[method] {
+		return nil, status.Error(codes.PermissionDenied, "method requires a token issued on behalf of a user")
+	}
+
+	principal := &auth.Principal{
+		UserID:   claims.UserID,
+		ClientID: claims.ClientID,
+		Scopes:   claims.Scopes,
+	}
+
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+func hasAuthorization(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get("authorization")) > 0
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
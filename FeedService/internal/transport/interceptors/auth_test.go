@@ -0,0 +1,248 @@
+package interceptors
+
+import (
+	"Project/FeedService/internal/auth"
+	"Project/FeedService/pkg/jwt"
+	"Project/pkg/revocation"
+	"Project/proto/gen"
+
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testKeyID         = "test-key"
+	testIssuer        = "test-issuer"
+	testAudience      = "test-audience"
+	testOAuthAudience = "test-oauth-audience"
+)
+
+// jwksClient serves a single Ed25519 key as the AuthService JWKS.
+type jwksClient struct {
+	gen.AuthenticationClient
+
+	publicKey ed25519.PublicKey
+}
+
+func (c *jwksClient) GetJWKS(_ context.Context, _ *gen.GetJWKSRequest, _ ...grpc.CallOption) (*gen.GetJWKSResponse, error) {
+	return &gen.GetJWKSResponse{Keys: []*gen.JSONWebKey{{
+		Kid: testKeyID,
+		Kty: "OKP",
+		Crv: "Ed25519",
+		Alg: gojwt.SigningMethodEdDSA.Alg(),
+		X:   base64.RawURLEncoding.EncodeToString(c.publicKey),
+	}}}, nil
+}
+
+func signToken(t *testing.T, privateKey ed25519.PrivateKey, typ string, claims gojwt.MapClaims) string {
+	t.Helper()
+
+	if _, ok := claims["iss"]; !ok {
+		claims["iss"] = testIssuer
+	}
+	claims["jti"] = uuid.NewString()
+	claims["exp"] = time.Now().Add(time.Minute).Unix()
+
+	token := gojwt.NewWithClaims(gojwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = testKeyID
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+
+	signed, err := token.SignedString(privateKey)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestAuthInterceptorOAuthScopes(t *testing.T) {
+	const (
+		publicRead  = gen.FeedService_GetAllPosts_FullMethodName
+		privateRead = gen.FeedService_GetHomeTimeline_FullMethodName
+		write       = gen.FeedService_CreatePost_FullMethodName
+		unscoped    = gen.FeedService_ExportMyPosts_FullMethodName
+	)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	userID := uuid.New()
+	const clientID = "8c7d3c02-49a4-4bd5-9d1e-5d1f1b0f4a11"
+
+	firstParty := func(t *testing.T) string {
+		return signToken(t, privateKey, "", gojwt.MapClaims{
+			"aud":        testAudience,
+			"user_id":    userID.String(),
+			"session_id": uuid.NewString(),
+		})
+	}
+	oauth := func(audience, subject, scope string) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			return signToken(t, privateKey, "at+jwt", gojwt.MapClaims{
+				"aud":       audience,
+				"sub":       subject,
+				"client_id": clientID,
+				"grant_id":  uuid.NewString(),
+				"scope":     scope,
+			})
+		}
+	}
+
+	tests := []struct {
+		name     string
+		token    func(t *testing.T) string
+		method   string
+		wantCode codes.Code
+		// wantUser and wantClient describe the principal of allowed calls.
+		wantUser   uuid.UUID
+		wantClient string
+	}{
+		{
+			name:     "first-party token",
+			token:    firstParty,
+			method:   write,
+			wantUser: userID,
+		},
+		{
+			name: "first-party token from another issuer",
+			token: func(t *testing.T) string {
+				return signToken(t, privateKey, "", gojwt.MapClaims{
+					"iss":        "someone-else",
+					"aud":        testAudience,
+					"user_id":    userID.String(),
+					"session_id": uuid.NewString(),
+				})
+			},
+			method:   write,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "first-party token without an audience",
+			token: func(t *testing.T) string {
+				return signToken(t, privateKey, "", gojwt.MapClaims{
+					"user_id":    userID.String(),
+					"session_id": uuid.NewString(),
+				})
+			},
+			method:   write,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:       "read scope reads",
+			token:      oauth(testOAuthAudience, userID.String(), "profile posts:read"),
+			method:     privateRead,
+			wantUser:   userID,
+			wantClient: clientID,
+		},
+		{
+			name:     "read scope cannot write",
+			token:    oauth(testOAuthAudience, userID.String(), "posts:read"),
+			method:   write,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:       "write scope writes",
+			token:      oauth(testOAuthAudience, userID.String(), "posts:write"),
+			method:     write,
+			wantUser:   userID,
+			wantClient: clientID,
+		},
+		{
+			name:     "method without a scope is closed to OAuth clients",
+			token:    oauth(testOAuthAudience, userID.String(), "posts:read posts:write"),
+			method:   unscoped,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "OAuth token with the first-party audience",
+			token:    oauth(testAudience, userID.String(), "posts:write"),
+			method:   write,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:       "client credentials read public posts",
+			token:      oauth(testOAuthAudience, clientID, "posts:read"),
+			method:     publicRead,
+			wantUser:   uuid.Nil,
+			wantClient: clientID,
+		},
+		{
+			name:     "client credentials have no timeline",
+			token:    oauth(testOAuthAudience, clientID, "posts:read"),
+			method:   privateRead,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "client credentials cannot write",
+			token:    oauth(testOAuthAudience, clientID, "posts:write"),
+			method:   write,
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := NewAuthInterceptor(
+				jwt.NewKeySet(&jwksClient{publicKey: publicKey}, time.Hour),
+				revocation.NewCache(nil, time.Minute, zap.NewNop()),
+				testIssuer,
+				testAudience,
+				testOAuthAudience,
+				[]string{publicRead},
+				map[string]string{
+					publicRead:  auth.ScopePostsRead,
+					privateRead: auth.ScopePostsRead,
+					write:       auth.ScopePostsWrite,
+				},
+				zap.NewNop(),
+			)
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tt.token(t)))
+
+			var principal *auth.Principal
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				principal, _ = auth.PrincipalFromContext(ctx)
+				return req, nil
+			}
+
+			_, err := interceptor.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("error = %v, want code %s", err, tt.wantCode)
+			}
+			if err != nil {
+				if principal != nil {
+					t.Errorf("handler ran for a rejected call")
+				}
+				return
+			}
+
+			if principal == nil {
+				t.Fatalf("no principal in the context")
+			}
+			if principal.UserID != tt.wantUser || principal.ClientID != tt.wantClient {
+				t.Errorf("principal = %+v, want user %s and client %q", principal, tt.wantUser, tt.wantClient)
+			}
+			if tt.wantClient != "" && len(principal.Roles)+len(principal.Permissions) != 0 {
+				t.Errorf("OAuth principal has roles %v and permissions %v", principal.Roles, principal.Permissions)
+			}
+			if tt.wantClient != "" && len(principal.Scopes) == 0 {
+				t.Errorf("OAuth principal has no scopes")
+			}
+		})
+	}
+}
@@ -5,7 +5,8 @@ import (
 	"net"
 
 	"Project/FeedService/internal/transport/handlers"
-	
+	"Project/FeedService/internal/transport/interceptors"
+
 	"Project/proto/gen"
 
 	"go.uber.org/zap"
@@ -22,8 +23,12 @@ func NewGRPCServer(
 	log *zap.Logger,
 	port int,
 	feedHandlers *handlers.FeedHandlers,
+	authInterceptor *interceptors.AuthInterceptor,
 ) *GRPCServer {
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(authInterceptor.Unary()),
+		grpc.ChainStreamInterceptor(authInterceptor.Stream()),
+	)
 	gen.RegisterFeedServiceServer(grpcServer, feedHandlers)
 	
 	return &GRPCServer{
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE posts
+    ADD COLUMN updated_at TIMESTAMP,
+    ADD COLUMN deleted_at TIMESTAMP,
+    ADD COLUMN deleted_by UUID;
+
+-- post_edits keeps the previous version of a post every time it is edited.
+CREATE TABLE post_edits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    editor_id UUID NOT NULL,
+    previous_content TEXT NOT NULL,
+    previous_image_url TEXT,
+    edited_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_post_edits_post_id ON post_edits(post_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_post_edits_post_id;
+DROP TABLE IF EXISTS post_edits;
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- The feed is paged by (created_at, id), newest first, over the posts that are not deleted.
+CREATE INDEX idx_posts_feed_cursor ON posts(created_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_posts_created_at;
+
+-- +goose Down
+CREATE INDEX idx_posts_created_at ON posts(created_at);
+
+DROP INDEX IF EXISTS idx_posts_feed_cursor;
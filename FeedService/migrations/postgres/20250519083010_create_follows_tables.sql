@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE follows (
+    follower_id UUID NOT NULL,
+    followee_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX idx_follows_followee_id ON follows(followee_id, created_at DESC);
+CREATE INDEX idx_follows_follower_id_created_at ON follows(follower_id, created_at DESC);
+
+-- follow_stats keeps the counts so celebrities can be told apart without
+-- counting their followers on every post.
+CREATE TABLE follow_stats (
+    user_id UUID PRIMARY KEY,
+    followers_count INTEGER NOT NULL DEFAULT 0,
+    following_count INTEGER NOT NULL DEFAULT 0
+);
+
+-- Timelines read the newest posts of each followed user.
+CREATE INDEX idx_posts_user_id_feed_cursor ON posts(user_id, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_user_id_feed_cursor;
+DROP TABLE IF EXISTS follow_stats;
+DROP INDEX IF EXISTS idx_follows_follower_id_created_at;
+DROP INDEX IF EXISTS idx_follows_followee_id;
+DROP TABLE IF EXISTS follows;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- A user can react to a post once with every reaction type.
+CREATE TABLE post_reactions (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    reaction VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id, reaction)
+);
+
+CREATE INDEX idx_post_reactions_user_id ON post_reactions(user_id);
+
+-- post_reaction_counts is kept up to date in the transactions that change
+-- post_reactions so feeds do not count reactions on every read.
+CREATE TABLE post_reaction_counts (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    reaction VARCHAR(32) NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, reaction)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS post_reaction_counts;
+DROP INDEX IF EXISTS idx_post_reactions_user_id;
+DROP TABLE IF EXISTS post_reactions;
@@ -30,35 +30,93 @@ func ExtractTokenFromContext(ctx context.Context) (string, error) {
     return token, nil
 }
 
-func ExtractUserIDFromToken(tokenString string, secretKey string) (uuid.UUID, error) {
-    token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-        }
-        return []byte(secretKey), nil
-    })
+type Claims struct {
+    ID          string
+    UserID      uuid.UUID
+    SessionID   uuid.UUID
+    Email       string
+    Roles       []string
+    Permissions []string
+}
+
+func ExtractUserIDFromToken(ctx context.Context, tokenString string, keySet *KeySet, issuer, audience string) (uuid.UUID, error) {
+    claims, err := ParseToken(ctx, tokenString, keySet, issuer, audience)
     if err != nil {
-        return uuid.Nil, fmt.Errorf("failed to parse token: %w", err)
+        return uuid.Nil, err
+    }
+
+    return claims.UserID, nil
+}
+
+// ParseToken verifies the token with the AuthService public key named by its
+// "kid" header, and that it was issued by issuer for audience. Both are
+// required, a token without them is rejected.
+func ParseToken(ctx context.Context, tokenString string, keySet *KeySet, issuer, audience string) (*Claims, error) {
+    token, err := jwt.Parse(tokenString, keyFunc(ctx, keySet))
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse token: %w", err)
     }
 
     if !token.Valid {
-        return uuid.Nil, fmt.Errorf("invalid token")
+        return nil, fmt.Errorf("invalid token")
+    }
+
+    if typ, _ := token.Header["typ"].(string); typ == oauthTokenType {
+        return nil, fmt.Errorf("oauth access tokens are not first-party tokens")
     }
 
     claims, ok := token.Claims.(jwt.MapClaims)
     if !ok {
-        return uuid.Nil, fmt.Errorf("failed to extract claims from token")
+        return nil, fmt.Errorf("failed to extract claims from token")
+    }
+
+    if err := verifyIssuerAndAudience(claims, issuer, audience); err != nil {
+        return nil, err
     }
 
     userIDStr, ok := claims["user_id"].(string)
     if !ok {
-        return uuid.Nil, fmt.Errorf("user_id not found in token claims")
+        return nil, fmt.Errorf("user_id not found in token claims")
     }
 
     userID, err := uuid.Parse(userIDStr)
     if err != nil {
-        return uuid.Nil, fmt.Errorf("failed to parse user_id as UUID: %w", err)
+        return nil, fmt.Errorf("failed to parse user_id as UUID: %w", err)
     }
 
-    return userID, nil
-}
\ No newline at end of file
+    sessionIDStr, _ := claims["session_id"].(string)
+    sessionID, err := uuid.Parse(sessionIDStr)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse session_id as UUID: %w", err)
+    }
+
+    jti, _ := claims["jti"].(string)
+    if jti == "" {
+        return nil, fmt.Errorf("jti not found in token claims")
+    }
+
+    email, _ := claims["email"].(string)
+
+    return &Claims{
+        ID:          jti,
+        UserID:      userID,
+        SessionID:   sessionID,
+        Email:       email,
+        Roles:       stringsClaim(claims, "roles"),
+        Permissions: stringsClaim(claims, "permissions"),
+    }, nil
+}
+
+// stringsClaim reads a JSON array of strings, ignoring anything else.
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+    values, _ := claims[name].([]interface{})
+
+    result := make([]string, 0, len(values))
+    for _, value := range values {
+        if s, ok := value.(string); ok {
+            result = append(result, s)
+        }
+    }
+
+    return result
+}
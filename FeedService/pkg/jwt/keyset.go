@@ -0,0 +1,159 @@
+package jwt
+
+import (
+	"Project/proto/gen"
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// minRefreshInterval limits how often Key can fetch the JWKS, whether the
+// previous fetch succeeded or not.
+const minRefreshInterval = 30 * time.Second
+
+type PublicKey struct {
+	ID        string
+	Algorithm string
+	Key       interface{}
+}
+
+// KeySet caches the public keys of AuthService so tokens can be verified
+// locally. Keys are refetched periodically and whenever a token is signed with
+// a key that is not known yet, which is how a rotated key gets picked up.
+// Concurrent lookups share a single fetch.
+type KeySet struct {
+	client          gen.AuthenticationClient
+	refreshInterval time.Duration
+	refreshes       singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]*PublicKey
+	lastRefresh time.Time
+	lastAttempt time.Time
+}
+
+func NewKeySet(client gen.AuthenticationClient, refreshInterval time.Duration) *KeySet {
+	return &KeySet{
+		client:          client,
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]*PublicKey),
+	}
+}
+
+func (k *KeySet) Refresh(ctx context.Context) error {
+	k.mu.Lock()
+	k.lastAttempt = time.Now()
+	k.mu.Unlock()
+
+	res, err := k.client.GetJWKS(ctx, &gen.GetJWKSRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys := make(map[string]*PublicKey, len(res.Keys))
+	for _, jwk := range res.Keys {
+		key, err := parseJSONWebKey(jwk)
+		if err != nil {
+			return err
+		}
+		keys[key.ID] = key
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.lastRefresh = time.Now()
+	k.mu.Unlock()
+
+	return nil
+}
+
+func (k *KeySet) Key(ctx context.Context, keyID string) (*PublicKey, error) {
+	k.mu.RLock()
+	key, ok := k.keys[keyID]
+	stale := time.Since(k.lastRefresh) > k.refreshInterval
+	k.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if err := k.refreshThrottled(ctx); err != nil && !ok {
+		return nil, err
+	}
+
+	k.mu.RLock()
+	key, ok = k.keys[keyID]
+	k.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %q", keyID)
+	}
+
+	return key, nil
+}
+
+// refreshThrottled refreshes the keys unless a fetch was attempted within
+// minRefreshInterval, so tokens with made-up key IDs or an unreachable
+// AuthService cannot turn every request into a JWKS fetch.
+func (k *KeySet) refreshThrottled(ctx context.Context) error {
+	_, err, _ := k.refreshes.Do("jwks", func() (interface{}, error) {
+		k.mu.RLock()
+		recent := time.Since(k.lastAttempt) < minRefreshInterval
+		k.mu.RUnlock()
+
+		if recent {
+			return nil, nil
+		}
+
+		return nil, k.Refresh(ctx)
+	})
+
+	return err
+}
+
+func parseJSONWebKey(jwk *gen.JSONWebKey) (*PublicKey, error) {
+	key := &PublicKey{
+		ID:        jwk.Kid,
+		Algorithm: jwk.Alg,
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode modulus of key %q: %w", jwk.Kid, err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode exponent of key %q: %w", jwk.Kid, err)
+		}
+
+		key.Key = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key %q: %w", jwk.Kid, err)
+		}
+
+		if jwk.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported OKP key %q", jwk.Kid)
+		}
+
+		key.Key = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q of key %q", jwk.Kty, jwk.Kid)
+	}
+
+	return key, nil
+}
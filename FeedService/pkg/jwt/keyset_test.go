@@ -0,0 +1,100 @@
+package jwt
+
+import (
+	"Project/proto/gen"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// countingJWKSClient serves one Ed25519 key and counts the fetches.
+type countingJWKSClient struct {
+	gen.AuthenticationClient
+
+	x       string
+	err     error
+	fetches atomic.Int32
+}
+
+func (c *countingJWKSClient) GetJWKS(_ context.Context, _ *gen.GetJWKSRequest, _ ...grpc.CallOption) (*gen.GetJWKSResponse, error) {
+	c.fetches.Add(1)
+	time.Sleep(10 * time.Millisecond)
+
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &gen.GetJWKSResponse{Keys: []*gen.JSONWebKey{{
+		Kid: "known",
+		Kty: "OKP",
+		Crv: "Ed25519",
+		Alg: "EdDSA",
+		X:   c.x,
+	}}}, nil
+}
+
+func newCountingJWKSClient(t *testing.T) *countingJWKSClient {
+	t.Helper()
+
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return &countingJWKSClient{x: base64.RawURLEncoding.EncodeToString(publicKey)}
+}
+
+func lookupConcurrently(keySet *KeySet, keyID string, n int) {
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = keySet.Key(context.Background(), keyID)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestKeySetThrottlesRefreshes(t *testing.T) {
+	client := newCountingJWKSClient(t)
+	keySet := NewKeySet(client, time.Hour)
+
+	lookupConcurrently(keySet, "known", 20)
+	if got := client.fetches.Load(); got != 1 {
+		t.Fatalf("concurrent lookups fetched the JWKS %d times, want 1", got)
+	}
+
+	if _, err := keySet.Key(context.Background(), "known"); err != nil {
+		t.Fatalf("Key(known) returned error: %v", err)
+	}
+
+	lookupConcurrently(keySet, "unknown", 20)
+	if _, err := keySet.Key(context.Background(), "unknown"); err == nil {
+		t.Fatal("Key(unknown) returned no error")
+	}
+	if got := client.fetches.Load(); got != 1 {
+		t.Fatalf("unknown key IDs fetched the JWKS %d more times within the refresh interval", got-1)
+	}
+}
+
+func TestKeySetThrottlesFailedRefreshes(t *testing.T) {
+	client := newCountingJWKSClient(t)
+	client.err = errors.New("unavailable")
+	keySet := NewKeySet(client, time.Hour)
+
+	if _, err := keySet.Key(context.Background(), "known"); err == nil {
+		t.Fatal("Key returned no error while AuthService is unavailable")
+	}
+
+	lookupConcurrently(keySet, "known", 20)
+	if got := client.fetches.Load(); got != 1 {
+		t.Fatalf("a failed refresh was retried %d times within the refresh interval", got-1)
+	}
+}
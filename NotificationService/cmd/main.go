@@ -19,7 +19,7 @@ func main() {
 
 	log, err := logger.New()
 	if err != nil {
-		fmt.Errorf("Failed to load logger %w",err)
+		fmt.Printf("Failed to load logger: %v\n", err)
 		return
 	}
 
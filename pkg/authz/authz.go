@@ -0,0 +1,64 @@
+// Package authz holds the roles and permissions AuthService puts into access
+// tokens and the checks the other services run against them.
+package authz
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Roles and permissions seeded by the AuthService migrations.
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+
+	PermissionManageRoles   = "roles:manage"
+	PermissionDeleteAnyPost = "posts:delete_any"
+	PermissionEditAnyPost   = "posts:edit_any"
+	PermissionRevokeTokens  = "tokens:revoke"
+	PermissionManageUsers   = "users:manage"
+	PermissionReadAuditLog  = "audit:read"
+
+	PermissionImpersonateUsers = "users:impersonate"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Principal is an authenticated user with the roles and permissions of its
+// access token.
+type Principal struct {
+	UserID      uuid.UUID
+	Roles       []string
+	Permissions []string
+}
+
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+func (p Principal) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+// Require returns ErrPermissionDenied unless the principal has every permission.
+func Require(p Principal, permissions ...string) error {
+	for _, permission := range permissions {
+		if !p.HasPermission(permission) {
+			return ErrPermissionDenied
+		}
+	}
+
+	return nil
+}
+
+// RequireOwnerOr lets the owner of a resource through and anybody else only with
+// the permission, e.g. moderators deleting the posts of other users.
+func RequireOwnerOr(p Principal, ownerID uuid.UUID, permission string) error {
+	if p.UserID == ownerID {
+		return nil
+	}
+
+	return Require(p, permission)
+}
@@ -0,0 +1,110 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Cache is a local copy of the List fed by the pub/sub channel. Messages missed
+// while the subscription reconnects are picked up by a periodic resync.
+type Cache struct {
+	list           *List
+	resyncInterval time.Duration
+	logger         *zap.Logger
+
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+func NewCache(list *List, resyncInterval time.Duration, logger *zap.Logger) *Cache {
+	return &Cache{
+		list:           list,
+		resyncInterval: resyncInterval,
+		logger:         logger,
+		revoked:        make(map[string]time.Time),
+	}
+}
+
+// Start loads the current list and keeps the cache up to date until ctx is
+// done. Only the initial load is fatal.
+func (c *Cache) Start(ctx context.Context) error {
+	pubsub := c.list.client.Subscribe(ctx, Channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to revoked tokens: %w", err)
+	}
+
+	if err := c.resync(ctx); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	go c.run(ctx, pubsub.Channel())
+	go func() {
+		<-ctx.Done()
+		pubsub.Close()
+	}()
+
+	return nil
+}
+
+func (c *Cache) run(ctx context.Context, messages <-chan *redis.Message) {
+	ticker := time.NewTicker(c.resyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			jti, expiresAt, ok := parseMessage(message.Payload)
+			if !ok {
+				c.logger.Warn("Invalid revoked token message", zap.String("payload", message.Payload))
+				continue
+			}
+			c.add(jti, expiresAt)
+		case <-ticker.C:
+			if err := c.resync(ctx); err != nil {
+				c.logger.Error("Failed to resync revoked tokens", zap.Error(err))
+			}
+		}
+	}
+}
+
+// resync replaces the cache with the list in Redis, which also drops expired
+// entries.
+func (c *Cache) resync(ctx context.Context) error {
+	revoked, err := c.list.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.revoked = revoked
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *Cache) add(jti string, expiresAt time.Time) {
+	c.mu.Lock()
+	c.revoked[jti] = expiresAt
+	c.mu.Unlock()
+}
+
+func (c *Cache) IsRevoked(jti string) bool {
+	c.mu.RLock()
+	expiresAt, ok := c.revoked[jti]
+	c.mu.RUnlock()
+
+	return ok && time.Now().Before(expiresAt)
+}